@@ -0,0 +1,153 @@
+// Package admin HTTP administration and health endpoints for a Quark Broker.
+//
+// Exposes liveness and readiness probes (e.g. for Kubernetes), a JSON snapshot of the Broker supervisors and workers
+// and operational actions (pause, resume, drain and restart) over consumers subscribed to a topic.
+//
+//	GET  /healthz/live
+//	GET  /healthz/ready
+//	GET  /status
+//	POST /consumers/{pause|resume|drain|restart}?topic=<topic>
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/neutrinocorp/quark"
+)
+
+// Handler is an http.Handler administrating a quark.Broker
+type Handler struct {
+	Broker *quark.Broker
+
+	mux *http.ServeMux
+}
+
+// NewHandler allocates and returns a Handler for the given Broker
+func NewHandler(b *quark.Broker) *Handler {
+	h := &Handler{
+		Broker: b,
+		mux:    http.NewServeMux(),
+	}
+	h.mux.HandleFunc("/healthz/live", h.live)
+	h.mux.HandleFunc("/healthz/ready", h.ready)
+	h.mux.HandleFunc("/status", h.status)
+	h.mux.HandleFunc("/consumers/pause", h.action(b.Pause))
+	h.mux.HandleFunc("/consumers/resume", h.action(b.Resume))
+	h.mux.HandleFunc("/consumers/drain", h.action(b.Drain))
+	h.mux.HandleFunc("/consumers/restart", h.action(b.Restart))
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type probeResponse struct {
+	Status  string   `json:"status"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// live reports the process is up, the Broker state is not considered to avoid restarts while draining
+func (h *Handler) live(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	writeJSON(w, http.StatusOK, probeResponse{Status: "ok"})
+}
+
+// ready reports the Broker is serving, every registered consumer has a running supervisor and all of their workers
+// are connected
+func (h *Handler) ready(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	if reasons := Readiness(h.Broker.Status()); len(reasons) > 0 {
+		writeJSON(w, http.StatusServiceUnavailable, probeResponse{Status: "unavailable", Reasons: reasons})
+		return
+	}
+	writeJSON(w, http.StatusOK, probeResponse{Status: "ok"})
+}
+
+func (h *Handler) status(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	writeJSON(w, http.StatusOK, h.Broker.Status())
+}
+
+func (h *Handler) action(fn func(topic string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+			return
+		}
+		topic := r.URL.Query().Get("topic")
+		if topic == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "topic is required"})
+			return
+		}
+		if err := fn(topic); err != nil {
+			writeJSON(w, errorStatusCode(err), errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, h.Broker.Status())
+	}
+}
+
+// Readiness returns the reasons why the given Broker status is not ready to process messages, empty if ready
+func Readiness(st quark.BrokerStatus) []string {
+	if st.ShuttingDown {
+		return []string{"broker is shutting down"}
+	} else if !st.Serving {
+		return []string{"broker is not serving"}
+	}
+	reasons := make([]string, 0)
+	for _, c := range st.Unscheduled {
+		reasons = append(reasons, fmt.Sprintf("topic(s) %v: supervisor is not running", c.Topics))
+	}
+	for _, s := range st.Supervisors {
+		if s.Degraded {
+			reasons = append(reasons, fmt.Sprintf("topic(s) %v: degraded: %s", s.Topics, s.Error))
+		}
+		if len(s.Workers) < s.PoolSize {
+			reasons = append(reasons, fmt.Sprintf("topic(s) %v: %d of %d workers scheduled", s.Topics,
+				len(s.Workers), s.PoolSize))
+		}
+		for _, wk := range s.Workers {
+			if wk.State != quark.WorkerRunning {
+				reasons = append(reasons, fmt.Sprintf("topic(s) %v: worker %d is %s", s.Topics, wk.Id, wk.State))
+			}
+		}
+	}
+	return reasons
+}
+
+func errorStatusCode(err error) int {
+	switch {
+	case errors.Is(err, quark.ErrTopicNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, quark.ErrPauseNotSupported):
+		return http.StatusNotImplemented
+	case errors.Is(err, quark.ErrBrokerClosed), errors.Is(err, quark.ErrBrokerNotServing):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
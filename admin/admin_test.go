@@ -0,0 +1,120 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/neutrinocorp/quark/bus/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBroker() *quark.Broker {
+	b := memory.NewBroker(memory.NewBus(), quark.WithPoolSize(1), quark.WithRetryBackoff(time.Millisecond))
+	b.Topic("chat.0").HandleFunc(func(w quark.EventWriter, e *quark.Event) bool {
+		return true
+	})
+	return b
+}
+
+func serve(t *testing.T, b *quark.Broker) {
+	go func() {
+		_ = b.ListenAndServe()
+	}()
+	assert.Eventually(t, func() bool {
+		return b.Status().Serving
+	}, time.Second, time.Millisecond*10)
+}
+
+func do(h http.Handler, method, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func TestHandler_Probes(t *testing.T) {
+	t.Run("Admin liveness and readiness", func(t *testing.T) {
+		b := newTestBroker()
+		h := NewHandler(b)
+		assert.Equal(t, http.StatusOK, do(h, http.MethodGet, "/healthz/live").Code)
+		assert.Equal(t, http.StatusServiceUnavailable, do(h, http.MethodGet, "/healthz/ready").Code)
+
+		serve(t, b)
+		assert.Equal(t, http.StatusOK, do(h, http.MethodGet, "/healthz/ready").Code)
+		assert.Equal(t, http.StatusMethodNotAllowed, do(h, http.MethodPost, "/healthz/ready").Code)
+
+		assert.Nil(t, b.Shutdown(context.Background()))
+		assert.Equal(t, http.StatusServiceUnavailable, do(h, http.MethodGet, "/healthz/ready").Code)
+		assert.Equal(t, http.StatusOK, do(h, http.MethodGet, "/healthz/live").Code)
+	})
+
+	t.Run("Admin readiness of unscheduled consumers", func(t *testing.T) {
+		b := newTestBroker()
+		serve(t, b)
+		defer b.Shutdown(context.Background())
+		h := NewHandler(b)
+		assert.Nil(t, b.Drain("chat.0"))
+		rec := do(h, http.MethodGet, "/healthz/ready")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Contains(t, rec.Body.String(), "topic(s) [chat.0]: supervisor is not running")
+
+		assert.Nil(t, b.Restart("chat.0"))
+		assert.Equal(t, http.StatusOK, do(h, http.MethodGet, "/healthz/ready").Code)
+	})
+}
+
+func TestHandler_Status(t *testing.T) {
+	t.Run("Admin status", func(t *testing.T) {
+		b := newTestBroker()
+		serve(t, b)
+		defer b.Shutdown(context.Background())
+
+		rec := do(NewHandler(b), http.MethodGet, "/status")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		st := struct {
+			Serving     bool `json:"serving"`
+			Supervisors []struct {
+				Topics  []string `json:"topics"`
+				Workers []struct {
+					State string `json:"state"`
+				} `json:"workers"`
+			} `json:"supervisors"`
+		}{}
+		assert.Nil(t, json.NewDecoder(rec.Body).Decode(&st))
+		assert.True(t, st.Serving)
+		assert.Equal(t, []string{"chat.0"}, st.Supervisors[0].Topics)
+		assert.Equal(t, "running", st.Supervisors[0].Workers[0].State)
+	})
+}
+
+var handlerActionsTestingSuite = []struct {
+	method   string
+	target   string
+	expected int
+}{
+	{http.MethodPost, "/consumers/pause?topic=chat.0", http.StatusOK},
+	{http.MethodPost, "/consumers/resume?topic=chat.0", http.StatusOK},
+	{http.MethodPost, "/consumers/drain?topic=chat.0", http.StatusOK},
+	{http.MethodPost, "/consumers/drain?topic=chat.0", http.StatusNotFound},
+	{http.MethodPost, "/consumers/restart?topic=chat.0", http.StatusOK},
+	{http.MethodPost, "/consumers/pause?topic=chat.1", http.StatusNotFound},
+	{http.MethodPost, "/consumers/pause", http.StatusBadRequest},
+	{http.MethodGet, "/consumers/pause?topic=chat.0", http.StatusMethodNotAllowed},
+}
+
+func TestHandler_Actions(t *testing.T) {
+	b := newTestBroker()
+	serve(t, b)
+	defer b.Shutdown(context.Background())
+	h := NewHandler(b)
+	for _, tt := range handlerActionsTestingSuite {
+		t.Run("Admin consumer action", func(t *testing.T) {
+			assert.Equal(t, tt.expected, do(h, tt.method, tt.target).Code)
+		})
+	}
+	assert.Equal(t, 1, b.ActiveSupervisors())
+}
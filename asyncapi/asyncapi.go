@@ -0,0 +1,343 @@
+// Package asyncapi AsyncAPI document generation for Quark applications.
+//
+// Walks a Broker EventMux and the topics declared by its consumers (quark.Consumer.Publishes) to describe the
+// application channels, operations and messages. Topics following quark.FormatTopicName are described using their
+// segments, message payloads use schemas registered explicitly (e.g. by a codec) or derived from Go types.
+package asyncapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/neutrinocorp/quark"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Version2 AsyncAPI 2.x specification version
+	Version2 = "2.6.0"
+	// Version3 AsyncAPI 3.x specification version
+	Version3 = "3.0.0"
+
+	defaultProtocol    = "kafka"
+	defaultContentType = "application/json"
+)
+
+// ErrUnsupportedVersion the requested AsyncAPI specification version is not supported
+var ErrUnsupportedVersion = errors.New("asyncapi: unsupported specification version")
+
+// Server is a message broker the application connects to
+type Server struct {
+	Host     string
+	Protocol string
+}
+
+// ChannelBinding is the Apache Kafka topic layout of a channel
+type ChannelBinding struct {
+	Partitions int
+	Replicas   int
+}
+
+// Generator builds AsyncAPI documents from a quark.Broker
+type Generator struct {
+	Title       string
+	Version     string
+	Description string
+	// ContentType is the default message content type, defaults to the Broker BaseMessageContentType
+	ContentType string
+	Servers     map[string]Server
+
+	broker   *quark.Broker
+	schemas  map[string]map[string]interface{}
+	bindings map[string]ChannelBinding
+}
+
+// NewGenerator allocates and returns a Generator for the given Broker. The Broker cluster is used as servers
+func NewGenerator(b *quark.Broker, title, version string) *Generator {
+	g := &Generator{
+		Title:       title,
+		Version:     version,
+		ContentType: b.BaseMessageContentType,
+		Servers:     make(map[string]Server),
+		broker:      b,
+		schemas:     make(map[string]map[string]interface{}),
+		bindings:    make(map[string]ChannelBinding),
+	}
+	for i, addr := range b.Cluster {
+		g.Servers[fmt.Sprintf("broker-%d", i)] = Server{Host: addr, Protocol: defaultProtocol}
+	}
+	return g
+}
+
+// Schema registers the JSON Schema of the message payload published into the given topic
+func (g *Generator) Schema(topic string, schema map[string]interface{}) *Generator {
+	g.schemas[topic] = schema
+	return g
+}
+
+// Message registers the Go type of the message payload published into the given topic, its JSON Schema is derived
+// using SchemaOf
+func (g *Generator) Message(topic string, v interface{}) *Generator {
+	return g.Schema(topic, SchemaOf(v))
+}
+
+// Binding registers the Apache Kafka layout of the given topic
+func (g *Generator) Binding(topic string, b ChannelBinding) *Generator {
+	g.bindings[topic] = b
+	return g
+}
+
+// JSON generates an AsyncAPI document of the given specification version encoded as JSON
+func (g *Generator) JSON(version string) ([]byte, error) {
+	doc, err := g.Generate(version)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// YAML generates an AsyncAPI document of the given specification version encoded as YAML
+func (g *Generator) YAML(version string) ([]byte, error) {
+	doc, err := g.Generate(version)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc)
+}
+
+// operation is a consumer receiving from or publishing into a channel
+type operation struct {
+	topic string
+	group string
+	send  bool
+}
+
+// id returns the operation identifier, restricted to the characters allowed by AsyncAPI component keys
+func (o operation) id() string {
+	id := "receive." + o.topic + "." + o.group
+	if o.send {
+		id = "send." + o.topic
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, id)
+}
+
+// Generate builds an AsyncAPI document of the given specification version (Version2 or Version3)
+func (g *Generator) Generate(version string) (map[string]interface{}, error) {
+	topics, ops := g.walk()
+	doc := map[string]interface{}{
+		"asyncapi":           version,
+		"info":               g.info(),
+		"defaultContentType": g.contentType(),
+	}
+	switch version {
+	case Version2:
+		g.generateV2(doc, topics, ops)
+	case Version3:
+		g.generateV3(doc, topics, ops)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedVersion, version)
+	}
+	return doc, nil
+}
+
+// walk collects every topic and operation from the Broker EventMux
+func (g *Generator) walk() ([]string, []operation) {
+	seen := make(map[string]bool)
+	ops := make([]operation, 0)
+	sent := make(map[string]bool)
+	for topic, consumers := range g.broker.EventMux.List() {
+		seen[topic] = true
+		for _, c := range consumers {
+			ops = append(ops, operation{topic: topic, group: consumerGroup(c)})
+			for _, p := range c.GetPublishes() {
+				seen[p] = true
+				if !sent[p] {
+					sent[p] = true
+					ops = append(ops, operation{topic: p, send: true})
+				}
+			}
+		}
+	}
+	topics := make([]string, 0, len(seen))
+	for t := range seen {
+		topics = append(topics, t)
+	}
+	sort.Strings(topics)
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].id() < ops[j].id()
+	})
+	return topics, ops
+}
+
+// consumerGroup returns the effective consumer group (topics are used by default, see quark.Supervisor)
+func consumerGroup(c *quark.Consumer) string {
+	if g := c.GetGroup(); g != "" {
+		return g
+	}
+	return c.TopicString()
+}
+
+func (g *Generator) info() map[string]interface{} {
+	info := map[string]interface{}{
+		"title":   g.Title,
+		"version": g.Version,
+	}
+	if g.Description != "" {
+		info["description"] = g.Description
+	}
+	return info
+}
+
+func (g *Generator) contentType() string {
+	if g.ContentType != "" {
+		return g.ContentType
+	}
+	return defaultContentType
+}
+
+func (g *Generator) message(topic string) map[string]interface{} {
+	msg := map[string]interface{}{
+		"name":        topic,
+		"contentType": g.contentType(),
+	}
+	if schema, ok := g.schemas[topic]; ok {
+		msg["payload"] = schema
+	}
+	if name, ok := quark.ParseTopicName(topic); ok {
+		msg["name"] = name.Entity + "." + name.Action
+		msg["title"] = fmt.Sprintf("%s %s %s", name.Entity, name.Action, name.Kind)
+	}
+	return msg
+}
+
+func (g *Generator) channelDescription(topic string) string {
+	name, ok := quark.ParseTopicName(topic)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s %s on %s of %s service v%d (%s)", name.Entity, name.Action, name.Kind, name.Service,
+		name.Version, name.Organization)
+}
+
+func (g *Generator) channelBindings(topic string) map[string]interface{} {
+	b, ok := g.bindings[topic]
+	if !ok {
+		return nil
+	}
+	kafka := map[string]interface{}{"topic": topic}
+	if b.Partitions > 0 {
+		kafka["partitions"] = b.Partitions
+	}
+	if b.Replicas > 0 {
+		kafka["replicas"] = b.Replicas
+	}
+	return map[string]interface{}{"kafka": kafka}
+}
+
+func operationBindings(o operation) map[string]interface{} {
+	if o.send {
+		return nil
+	}
+	return map[string]interface{}{
+		"kafka": map[string]interface{}{
+			"groupId": map[string]interface{}{"type": "string", "enum": []string{o.group}},
+		},
+	}
+}
+
+// generateV2 AsyncAPI 2.x, operations are described from the client perspective: clients publish into the channels
+// the application receives from and subscribe to the channels the application sends to
+func (g *Generator) generateV2(doc map[string]interface{}, topics []string, ops []operation) {
+	servers := make(map[string]interface{}, len(g.Servers))
+	for name, s := range g.Servers {
+		servers[name] = map[string]interface{}{"url": s.Host, "protocol": s.Protocol}
+	}
+	doc["servers"] = servers
+
+	channels := make(map[string]interface{}, len(topics))
+	for _, topic := range topics {
+		ch := map[string]interface{}{}
+		if d := g.channelDescription(topic); d != "" {
+			ch["description"] = d
+		}
+		if b := g.channelBindings(topic); b != nil {
+			ch["bindings"] = b
+		}
+		channels[topic] = ch
+	}
+	for _, o := range ops {
+		ch := channels[o.topic].(map[string]interface{})
+		key := "publish"
+		if o.send {
+			key = "subscribe"
+		}
+		if _, ok := ch[key]; ok {
+			continue // AsyncAPI 2.x allows a single operation per kind, first consumer group is kept
+		}
+		op := map[string]interface{}{
+			"operationId": o.id(),
+			"message":     g.message(o.topic),
+		}
+		if b := operationBindings(o); b != nil {
+			op["bindings"] = b
+		}
+		ch[key] = op
+	}
+	doc["channels"] = channels
+}
+
+// generateV3 AsyncAPI 3.x, operations are described from the application perspective
+func (g *Generator) generateV3(doc map[string]interface{}, topics []string, ops []operation) {
+	servers := make(map[string]interface{}, len(g.Servers))
+	for name, s := range g.Servers {
+		servers[name] = map[string]interface{}{"host": s.Host, "protocol": s.Protocol}
+	}
+	doc["servers"] = servers
+
+	channels := make(map[string]interface{}, len(topics))
+	for _, topic := range topics {
+		ch := map[string]interface{}{
+			"address": topic,
+			"messages": map[string]interface{}{
+				"message": g.message(topic),
+			},
+		}
+		if d := g.channelDescription(topic); d != "" {
+			ch["description"] = d
+		}
+		if b := g.channelBindings(topic); b != nil {
+			ch["bindings"] = b
+		}
+		channels[topic] = ch
+	}
+	doc["channels"] = channels
+
+	operations := make(map[string]interface{}, len(ops))
+	for _, o := range ops {
+		action := "receive"
+		if o.send {
+			action = "send"
+		}
+		op := map[string]interface{}{
+			"action":  action,
+			"channel": map[string]interface{}{"$ref": "#/channels/" + o.topic},
+			"messages": []interface{}{
+				map[string]interface{}{"$ref": "#/channels/" + o.topic + "/messages/message"},
+			},
+		}
+		if b := operationBindings(o); b != nil {
+			op["bindings"] = b
+		}
+		operations[o.id()] = op
+	}
+	doc["operations"] = operations
+}
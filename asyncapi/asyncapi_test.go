@@ -0,0 +1,103 @@
+package asyncapi
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+type userPaid struct {
+	UserId string    `json:"user_id"`
+	Amount float64   `json:"amount"`
+	PaidAt time.Time `json:"paid_at"`
+	Tags   []string  `json:"tags,omitempty"`
+	Parent *userPaid `json:"parent"`
+	secret string
+}
+
+const paidTopic = "neutrino.payment.1.event.user.paid"
+
+func newTestGenerator() *Generator {
+	b := quark.NewBroker(quark.WithCluster("localhost:9092"))
+	b.Topic(paidTopic).Group("notifications").Publishes("neutrino.mail.1.command.mail.send").
+		HandleFunc(func(w quark.EventWriter, e *quark.Event) bool {
+			return true
+		})
+	b.Topic("chat.0").HandleFunc(func(w quark.EventWriter, e *quark.Event) bool {
+		return true
+	})
+	return NewGenerator(b, "Payments", "1.0.0").
+		Message(paidTopic, userPaid{}).
+		Binding(paidTopic, ChannelBinding{Partitions: 12, Replicas: 3})
+}
+
+func TestSchemaOf(t *testing.T) {
+	t.Run("JSON schema from Go type", func(t *testing.T) {
+		schema := SchemaOf(&userPaid{})
+		assert.Equal(t, "object", schema["type"])
+		props := schema["properties"].(map[string]interface{})
+		assert.Equal(t, 5, len(props))
+		assert.Equal(t, map[string]interface{}{"type": "number"}, props["amount"])
+		assert.Equal(t, "date-time", props["paid_at"].(map[string]interface{})["format"])
+		assert.Equal(t, map[string]interface{}{"type": "object"}, props["parent"])
+		assert.Equal(t, []string{"user_id", "amount", "paid_at"}, schema["required"])
+	})
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	t.Run("Generate AsyncAPI 2.x document", func(t *testing.T) {
+		doc, err := newTestGenerator().Generate(Version2)
+		assert.Nil(t, err)
+		channels := doc["channels"].(map[string]interface{})
+		assert.Equal(t, 3, len(channels))
+
+		paid := channels[paidTopic].(map[string]interface{})
+		assert.Equal(t, "user paid on event of payment service v1 (neutrino)", paid["description"])
+		assert.Equal(t, 12, paid["bindings"].(map[string]interface{})["kafka"].(map[string]interface{})["partitions"])
+		publish := paid["publish"].(map[string]interface{})
+		assert.Equal(t, "receive.neutrino.payment.1.event.user.paid.notifications", publish["operationId"])
+		assert.NotNil(t, publish["message"].(map[string]interface{})["payload"])
+
+		mail := channels["neutrino.mail.1.command.mail.send"].(map[string]interface{})
+		assert.NotNil(t, mail["subscribe"])
+	})
+
+	t.Run("Generate AsyncAPI 3.x document", func(t *testing.T) {
+		doc, err := newTestGenerator().Generate(Version3)
+		assert.Nil(t, err)
+		assert.Equal(t, "localhost:9092", doc["servers"].(map[string]interface{})["broker-0"].(map[string]interface{})["host"])
+		ops := doc["operations"].(map[string]interface{})
+		assert.Equal(t, 3, len(ops))
+		send := ops["send.neutrino.mail.1.command.mail.send"].(map[string]interface{})
+		assert.Equal(t, "send", send["action"])
+		receive := ops["receive.chat.0.chat.0"].(map[string]interface{})
+		assert.Equal(t, map[string]interface{}{"$ref": "#/channels/chat.0"}, receive["channel"])
+	})
+
+	t.Run("Generate unsupported version", func(t *testing.T) {
+		_, err := newTestGenerator().Generate("1.2.0")
+		assert.True(t, errors.Is(err, ErrUnsupportedVersion))
+	})
+}
+
+func TestGenerator_Encoding(t *testing.T) {
+	t.Run("Encode AsyncAPI documents", func(t *testing.T) {
+		g := newTestGenerator()
+		data, err := g.JSON(Version3)
+		assert.Nil(t, err)
+		doc := map[string]interface{}{}
+		assert.Nil(t, json.Unmarshal(data, &doc))
+		assert.Equal(t, Version3, doc["asyncapi"])
+
+		data, err = g.YAML(Version2)
+		assert.Nil(t, err)
+		doc = map[string]interface{}{}
+		assert.Nil(t, yaml.Unmarshal(data, &doc))
+		assert.Equal(t, Version2, doc["asyncapi"])
+	})
+}
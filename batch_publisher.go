@@ -0,0 +1,247 @@
+package quark
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var (
+	defaultBatchMaxMessages = 100
+	defaultBatchMaxBytes    = 1 << 20
+	defaultBatchLinger      = time.Millisecond * 10
+	defaultBatchBufferSize  = 10000
+)
+
+// BatchConfig defines when an asynchronous publisher flushes its buffered messages, zero values fall back to
+// defaults
+type BatchConfig struct {
+	// MaxMessages messages buffered before flushing (default 100)
+	MaxMessages int
+	// MaxBytes size of the buffered messages data before flushing (default 1 MiB)
+	MaxBytes int
+	// Linger maximum time a message is buffered before flushing (default 10 milliseconds)
+	Linger time.Duration
+	// BufferSize messages enqueued before PublishAsync blocks (default 10000)
+	BufferSize int
+	// OnDelivery is called every time a Delivery is resolved
+	OnDelivery func(*Delivery)
+}
+
+func (c BatchConfig) withDefaults() BatchConfig {
+	if c.MaxMessages <= 0 {
+		c.MaxMessages = defaultBatchMaxMessages
+	}
+	if c.MaxBytes <= 0 {
+		c.MaxBytes = defaultBatchMaxBytes
+	}
+	if c.Linger <= 0 {
+		c.Linger = defaultBatchLinger
+	}
+	if c.BufferSize <= 0 {
+		c.BufferSize = defaultBatchBufferSize
+	}
+	return c
+}
+
+// BatchPublisher is an AsyncPublisher buffering messages and publishing them in batches using the underlying
+// Publisher, once a batch reaches its maximum messages, maximum bytes or linger time.
+//
+// Every message of a failed batch is resolved with the batch error. Close must be called to release the
+// BatchPublisher background goroutine (a Broker closes it on Shutdown if used as one of its publishers)
+type BatchPublisher struct {
+	publisher Publisher
+	cfg       BatchConfig
+
+	queue   chan *Delivery
+	flushes chan chan struct{}
+	closing chan struct{}
+	done    chan struct{}
+	mu      sync.RWMutex
+	closed  bool
+}
+
+var _ AsyncPublisher = &BatchPublisher{}
+
+// NewBatchPublisher allocates and starts a BatchPublisher decorating the given Publisher
+func NewBatchPublisher(p Publisher, cfg BatchConfig) *BatchPublisher {
+	cfg = cfg.withDefaults()
+	b := &BatchPublisher{
+		publisher: p,
+		cfg:       cfg,
+		queue:     make(chan *Delivery, cfg.BufferSize),
+		flushes:   make(chan chan struct{}),
+		closing:   make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Publish enqueues the given messages, returning before they are published; delivery errors are reported through the
+// OnDelivery hook. EventWriter(s) wait for the Delivery futures instead (see AsyncPublisher).
+//
+// Returns ErrEmptyMessage (nothing is enqueued), ErrPublisherClosed or the ctx error if it is done before every
+// message was enqueued
+func (b *BatchPublisher) Publish(ctx context.Context, msgs ...*Message) error {
+	if b.publisher == nil {
+		return ErrPublisherNotImplemented
+	}
+	for _, msg := range msgs {
+		if msg == nil {
+			return ErrEmptyMessage
+		}
+	}
+	for _, d := range b.PublishAsync(ctx, msgs...) {
+		if err := d.Err(); err == ErrPublisherClosed || (err != nil && err == ctx.Err()) {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishAsync enqueues the given messages returning a Delivery future per message, blocks while the buffer is full.
+//
+// Messages are resolved with the ctx error if it is done before they are enqueued. Once enqueued, messages are
+// published even if ctx is done (e.g. the Event context of a handler which already returned)
+func (b *BatchPublisher) PublishAsync(ctx context.Context, msgs ...*Message) []*Delivery {
+	deliveries := make([]*Delivery, 0, len(msgs))
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, msg := range msgs {
+		d := NewDelivery(msg)
+		deliveries = append(deliveries, d)
+		if b.closed {
+			b.complete(d, ErrPublisherClosed)
+			continue
+		} else if b.publisher == nil {
+			b.complete(d, ErrPublisherNotImplemented)
+			continue
+		} else if msg == nil {
+			b.complete(d, ErrEmptyMessage)
+			continue
+		} else if err := ctx.Err(); err != nil {
+			b.complete(d, err)
+			continue
+		}
+		select {
+		case b.queue <- d:
+		case <-ctx.Done():
+			b.complete(d, ctx.Err())
+		}
+	}
+	return deliveries
+}
+
+// Flush publishes every enqueued message, blocks until they are resolved or ctx is done
+func (b *BatchPublisher) Flush(ctx context.Context) error {
+	req := make(chan struct{})
+	select {
+	case b.flushes <- req:
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-req:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close flushes every enqueued message and stops the BatchPublisher, following messages are resolved with
+// ErrPublisherClosed
+func (b *BatchPublisher) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrPublisherClosed
+	}
+	b.closed = true
+	b.mu.Unlock()
+	close(b.closing)
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *BatchPublisher) run() {
+	defer close(b.done)
+	batch := make([]*Delivery, 0, b.cfg.MaxMessages)
+	size := 0
+	var timer *time.Timer
+	var linger <-chan time.Time
+	send := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, linger = nil, nil
+		}
+		if len(batch) == 0 {
+			return
+		}
+		b.send(batch)
+		batch = make([]*Delivery, 0, b.cfg.MaxMessages)
+		size = 0
+	}
+	add := func(d *Delivery) {
+		batch = append(batch, d)
+		size += len(d.Message.Data)
+		if len(batch) == 1 {
+			timer = time.NewTimer(b.cfg.Linger)
+			linger = timer.C
+		}
+		if len(batch) >= b.cfg.MaxMessages || size >= b.cfg.MaxBytes {
+			send()
+		}
+	}
+	drain := func() {
+		for {
+			select {
+			case d := <-b.queue:
+				add(d)
+			default:
+				send()
+				return
+			}
+		}
+	}
+	for {
+		select {
+		case d := <-b.queue:
+			add(d)
+		case <-linger:
+			send()
+		case req := <-b.flushes:
+			drain()
+			close(req)
+		case <-b.closing:
+			drain()
+			return
+		}
+	}
+}
+
+// send publishes the given batch resolving its deliveries
+func (b *BatchPublisher) send(batch []*Delivery) {
+	msgs := make([]*Message, 0, len(batch))
+	for _, d := range batch {
+		msgs = append(msgs, d.Message)
+	}
+	// batches outlive the callers context, so its cancellation does not abort messages of other callers
+	err := b.publisher.Publish(context.Background(), msgs...)
+	for _, d := range batch {
+		b.complete(d, err)
+	}
+}
+
+func (b *BatchPublisher) complete(d *Delivery, err error) {
+	d.Complete(err)
+	if b.cfg.OnDelivery != nil {
+		b.cfg.OnDelivery(d)
+	}
+}
@@ -0,0 +1,139 @@
+package quark
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type batchRecordingPublisher struct {
+	mu      sync.Mutex
+	err     error
+	batches [][]string
+}
+
+func (p *batchRecordingPublisher) Publish(_ context.Context, msgs ...*Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ids := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		ids = append(ids, m.Id)
+	}
+	p.batches = append(p.batches, ids)
+	return p.err
+}
+
+func (p *batchRecordingPublisher) getBatches() [][]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([][]string(nil), p.batches...)
+}
+
+var batchPublisherTestingSuite = []struct {
+	description string
+	cfg         BatchConfig
+	data        []byte
+	expBatches  [][]string
+}{
+	{"Flush batch by message count", BatchConfig{MaxMessages: 2, Linger: time.Hour}, nil,
+		[][]string{{"1", "2"}, {"3", "4"}}},
+	{"Flush batch by bytes", BatchConfig{MaxBytes: 6, Linger: time.Hour}, []byte("foo"),
+		[][]string{{"1", "2"}, {"3", "4"}}},
+	{"Flush batch by linger time", BatchConfig{Linger: time.Millisecond}, nil,
+		[][]string{{"1", "2", "3", "4"}}},
+}
+
+func TestBatchPublisher_PublishAsync(t *testing.T) {
+	for _, tt := range batchPublisherTestingSuite {
+		t.Run(tt.description, func(t *testing.T) {
+			p := &batchRecordingPublisher{}
+			b := NewBatchPublisher(p, tt.cfg)
+			defer b.Close(context.Background())
+
+			ctx := context.Background()
+			deliveries := b.PublishAsync(ctx, NewMessage("1", "foo", tt.data), NewMessage("2", "foo", tt.data),
+				NewMessage("3", "foo", tt.data), NewMessage("4", "foo", tt.data))
+			require.Len(t, deliveries, 4)
+			for _, d := range deliveries {
+				waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+				assert.Nil(t, d.Wait(waitCtx))
+				cancel()
+			}
+			assert.Equal(t, tt.expBatches, p.getBatches())
+		})
+	}
+
+	t.Run("Resolve deliveries with batch error", func(t *testing.T) {
+		var resolved []*Delivery
+		p := &batchRecordingPublisher{err: errPublisherStub}
+		b := NewBatchPublisher(p, BatchConfig{OnDelivery: func(d *Delivery) {
+			resolved = append(resolved, d)
+		}})
+		defer b.Close(context.Background())
+
+		deliveries := b.PublishAsync(context.Background(), NewMessage("1", "foo", nil), nil)
+		assert.Nil(t, b.Flush(context.Background()))
+		assert.True(t, errors.Is(deliveries[0].Err(), errPublisherStub))
+		assert.True(t, errors.Is(deliveries[1].Err(), ErrEmptyMessage))
+		assert.Len(t, resolved, 2)
+	})
+
+	t.Run("Publish messages after caller context is done", func(t *testing.T) {
+		p := &batchRecordingPublisher{}
+		b := NewBatchPublisher(p, BatchConfig{Linger: time.Hour})
+		ctx, cancel := context.WithCancel(context.Background())
+		assert.Nil(t, b.Publish(ctx, NewMessage("1", "foo", nil)))
+		cancel()
+		assert.Nil(t, b.Close(context.Background()))
+		assert.Equal(t, [][]string{{"1"}}, p.getBatches())
+
+		assert.True(t, errors.Is(b.Publish(ctx, NewMessage("2", "foo", nil)), ErrPublisherClosed))
+		assert.True(t, errors.Is(b.Close(context.Background()), ErrPublisherClosed))
+		assert.Nil(t, b.Flush(context.Background()))
+	})
+
+	t.Run("Return enqueue errors", func(t *testing.T) {
+		p := &batchRecordingPublisher{}
+		b := NewBatchPublisher(p, BatchConfig{Linger: time.Hour, BufferSize: 1})
+		ctx, cancel := context.WithCancel(context.Background())
+		assert.True(t, errors.Is(b.Publish(ctx, NewMessage("1", "foo", nil), nil), ErrEmptyMessage))
+		cancel()
+		assert.True(t, errors.Is(b.Publish(ctx, NewMessage("2", "foo", nil), NewMessage("3", "foo", nil),
+			NewMessage("4", "foo", nil)), context.Canceled))
+		assert.Nil(t, b.Close(context.Background()))
+		assert.Empty(t, p.getBatches())
+	})
+}
+
+func TestBroker_ShutdownReleasesPublishers(t *testing.T) {
+	p := &batchRecordingPublisher{}
+	b := NewBatchPublisher(p, BatchConfig{Linger: time.Hour})
+	broker := NewBroker(WithPublisher(b), WithRetryBackoff(time.Millisecond))
+
+	assert.Nil(t, b.Publish(context.Background(), NewMessage("1", "foo", nil)))
+	assert.Empty(t, p.getBatches())
+	assert.Nil(t, broker.Shutdown(context.Background()))
+	assert.Equal(t, [][]string{{"1"}}, p.getBatches())
+	assert.Equal(t, ErrPublisherClosed, b.Publish(context.Background(), NewMessage("2", "foo", nil)))
+}
+
+func TestEventWriter_AsyncPublisher(t *testing.T) {
+	errStub := errors.New("broker not available")
+	for _, expErr := range []error{nil, errStub} {
+		t.Run("Event writer wait for deliveries", func(t *testing.T) {
+			p := &batchRecordingPublisher{err: expErr}
+			b := NewBatchPublisher(p, BatchConfig{Linger: time.Millisecond})
+			defer b.Close(context.Background())
+			w := NewEventWriter(NewBroker(WithPublisher(b), WithRetryBackoff(time.Millisecond)))
+
+			_, err := w.WriteMessage(context.Background(), NewMessage("1", "foo", nil))
+			assert.True(t, errors.Is(err, expErr))
+			assert.Equal(t, [][]string{{"1"}}, p.getBatches()) // delivered before returning
+		})
+	}
+}
@@ -2,6 +2,8 @@ package quark
 
 import (
 	"context"
+	"reflect"
+	"sort"
 	"sync"
 	"time"
 
@@ -44,12 +46,30 @@ type Broker struct {
 
 	BaseContext context.Context
 
-	supervisors       map[int]*Supervisor
-	activeSupervisors int
-	activeWorkers     int
-	mu                sync.Mutex
-	inShutdown        atomicBool
-	doneChan          chan struct{}
+	// ReplyTopic is the topic this Broker instance receives replies from when using request-reply mechanisms
+	// (EventWriter.Request).
+	//
+	// Must be unique per-instance (e.g. "replies.<hostname>"), so replies are routed to the requesting instance. The
+	// topic is consumed using a consumer group named after it, receiving replies from every partition.
+	ReplyTopic string
+
+	// RestartPolicy defines how Supervisor(s) restart their crashed workers, DefaultRestartPolicy is used if nil
+	RestartPolicy *RestartPolicy
+	// StartupMode defines how failing supervisors are handled when starting, StartupAtomic by default
+	StartupMode StartupMode
+	// PanicPolicy defines how Events whose handler panicked are handled, DefaultPanicPolicy is used if nil
+	PanicPolicy *PanicPolicy
+	// HandlerTimeout maximum time a handler may take to serve an Event, handlers have no deadline if zero
+	HandlerTimeout time.Duration
+
+	replies          *replyRouter
+	replyOnce        sync.Once
+	replyConsumerSet atomicBool
+	supervisors      map[*Consumer]*Supervisor
+	mu               sync.Mutex
+	inShutdown       atomicBool
+	inService        atomicBool
+	doneChan         chan struct{}
 }
 
 var (
@@ -85,7 +105,12 @@ func NewBroker(opts ...Option) *Broker {
 		BaseMessageSource:      options.baseMessageSource,
 		BaseMessageContentType: options.baseMessageContentType,
 		BaseContext:            options.baseContext,
-		supervisors:            make(map[int]*Supervisor),
+		ReplyTopic:             options.replyTopic,
+		RestartPolicy:          options.restartPolicy,
+		StartupMode:            options.startupMode,
+		PanicPolicy:            options.panicPolicy,
+		HandlerTimeout:         options.handlerTimeout,
+		supervisors:            make(map[*Consumer]*Supervisor),
 		mu:                     sync.Mutex{},
 		inShutdown:             0,
 		doneChan:               nil,
@@ -114,38 +139,146 @@ func (b *Broker) ListenAndServe() error {
 	return b.Serve()
 }
 
-// Serve starts the broker components
+// Serve starts the broker components.
+//
+// Blocks until the Broker is shut down, then returns ErrBrokerClosed
 func (b *Broker) Serve() error {
-	for {
-		if b.BaseContext == nil {
-			b.BaseContext = context.Background()
-		}
-		b.setDefaultMux()
-		if err := b.startSupervisors(b.BaseContext); err != nil {
-			return err
-		}
-
-		<-b.getDoneChanLocked()
-		b.Shutdown(b.BaseContext)
+	if b.BaseContext == nil {
+		b.BaseContext = context.Background()
 	}
+	b.setDefaultMux()
+	b.setReplyConsumer()
+	if err := b.startSupervisors(b.BaseContext); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	done := b.getDoneChanLocked()
+	b.mu.Unlock()
+	<-done
+	return ErrBrokerClosed
 }
 
 func (b *Broker) startSupervisors(ctx context.Context) error {
+	degraded := make([]error, 0)
+	defer func() { b.reportErrors(ctx, degraded) }() // runs once the lock is released
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	started := make([]*Consumer, 0)
 	for _, consumers := range b.EventMux.List() {
 		for _, c := range consumers {
-			nodeCtx := ctx
-			n := newSupervisor(b, c)
-			if err := n.ScheduleJobs(nodeCtx); err != nil {
-				return err
+			if _, ok := b.supervisors[c]; ok {
+				continue
+			}
+			if err := b.startSupervisorLocked(ctx, c, &degraded); err != nil {
+				return b.rollbackLocked(started, err)
+			}
+			started = append(started, c)
+		}
+	}
+	b.inService.setTrue()
+	return nil
+}
+
+// rollbackLocked closes the supervisors of the given consumers after a failed startup
+func (b *Broker) rollbackLocked(consumers []*Consumer, cause error) error {
+	errs := multierror.Append(new(multierror.Error), cause)
+	for _, c := range consumers {
+		if n, ok := b.supervisors[c]; ok {
+			if err := b.closeNodeLocked(c, n); err != nil {
+				errs = multierror.Append(errs, err)
 			}
-			b.supervisors[b.activeSupervisors] = n
-			b.activeWorkers += n.runningWorkers.Length()
-			b.activeSupervisors++
 		}
 	}
+	return errs.ErrorOrNil()
+}
+
+// startSupervisorLocked schedules the Supervisor of the given Consumer. Using StartupBestEffort mode, scheduling
+// errors are appended to degraded instead, to be reported once the Broker lock is released (see reportErrors)
+func (b *Broker) startSupervisorLocked(ctx context.Context, c *Consumer, degraded *[]error) error {
+	if b.supervisors == nil {
+		b.supervisors = make(map[*Consumer]*Supervisor)
+	}
+	if _, ok := b.supervisors[c]; ok {
+		return nil // consumers listening to N topics are registered N times in the EventMux
+	}
+	n := newSupervisor(b, c)
+	if err := n.ScheduleJobs(ctx); err != nil {
+		if b.setDefaultStartupMode() != StartupBestEffort {
+			return err
+		}
+		b.supervisors[c] = n // kept as degraded, workers which did start keep running
+		*degraded = append(*degraded, err)
+		return nil
+	}
+	b.supervisors[c] = n
+	return nil
+}
+
+// reportErrors reports the given errors through the ErrorHandler, must be called without holding the Broker lock as
+// handlers might query the Broker (e.g. Status)
+func (b *Broker) reportErrors(ctx context.Context, errs []error) {
+	if b.ErrorHandler == nil {
+		return
+	}
+	for _, err := range errs {
+		b.ErrorHandler(ctx, err)
+	}
+}
+
+// AddConsumer registers the given Consumer into the EventMux.
+//
+// If the Broker is already serving, the Consumer Supervisor and its workers are scheduled right away; otherwise,
+// they will be scheduled when the Broker starts serving
+func (b *Broker) AddConsumer(c *Consumer) error {
+	if c == nil {
+		return ErrEmptyConsumer
+	} else if b.shuttingDown() {
+		return ErrBrokerClosed
+	}
+	b.setDefaultMux()
+	degraded := make([]error, 0)
+	defer func() { b.reportErrors(b.BaseContext, degraded) }() // runs once the lock is released
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !muxContains(b.EventMux, c) {
+		b.EventMux.Add(c)
+	}
+	if !b.inService.isSet() {
+		return nil
+	}
+	if err := b.startSupervisorLocked(b.BaseContext, c, &degraded); err != nil {
+		if remover, ok := b.EventMux.(ConsumerRemover); ok {
+			remover.Remove(c)
+		}
+		return err
+	}
 	return nil
 }
 
+// RemoveConsumer removes the given Consumer from the EventMux and gracefully stops its Supervisor and workers
+// if the Broker is serving.
+//
+// Returns ErrRemoveNotSupported if the EventMux is not a ConsumerRemover
+func (b *Broker) RemoveConsumer(c *Consumer) error {
+	if c == nil {
+		return ErrEmptyConsumer
+	}
+	b.setDefaultMux()
+	remover, ok := b.EventMux.(ConsumerRemover)
+	if !ok {
+		return ErrRemoveNotSupported
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	remover.Remove(c)
+	n, ok := b.supervisors[c]
+	if !ok {
+		return nil
+	}
+	return b.closeNodeLocked(c, n)
+}
+
 // Shutdown starts Broker graceful shutdown of its components
 func (b *Broker) Shutdown(ctx context.Context) error {
 	b.inShutdown.setTrue()
@@ -153,11 +286,14 @@ func (b *Broker) Shutdown(ctx context.Context) error {
 	defer b.mu.Unlock()
 	b.closeDoneChanLocked()
 
+	b.inService.setFalse()
+
+	publishers := b.publishersLocked() // closed supervisors are removed from the registry
 	ticker := time.NewTicker(shutdownPollInterval)
 	defer ticker.Stop()
 	for {
 		if err := b.closeNodes(); err == nil {
-			return err
+			return releasePublishers(ctx, publishers)
 		}
 		select {
 		case <-ctx.Done():
@@ -190,21 +326,108 @@ func (b *Broker) closeDoneChanLocked() {
 	}
 }
 
-func (b *Broker) closeNodes() error {
+// Pause stops every Supervisor subscribed to the given topic from fetching new data without shutting them down
+func (b *Broker) Pause(topic string) error {
+	return b.toggleTopic(topic, (*Supervisor).Pause)
+}
+
+// Resume starts fetching data again on every Supervisor subscribed to the given topic
+func (b *Broker) Resume(topic string) error {
+	return b.toggleTopic(topic, (*Supervisor).Resume)
+}
+
+// Drain gracefully stops every Supervisor subscribed to the given topic, waiting for in-flight messages to be
+// handled. Consumers are kept in the EventMux, so they can be started again using Restart
+func (b *Broker) Drain(topic string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	errs := new(multierror.Error)
-	for k, n := range b.supervisors {
-		if err := n.Close(); err != nil {
+	found := false
+	for c, n := range b.supervisors {
+		if !consumerHasTopic(c, topic) {
+			continue
+		}
+		found = true
+		if err := b.closeNodeLocked(c, n); err != nil {
 			errs = multierror.Append(errs, err)
+		}
+	}
+	if !found {
+		return ErrTopicNotFound
+	}
+	return errs.ErrorOrNil()
+}
+
+// Restart stops (if running) and schedules again every Consumer subscribed to the given topic
+func (b *Broker) Restart(topic string) error {
+	if b.shuttingDown() {
+		return ErrBrokerClosed
+	} else if !b.inService.isSet() {
+		return ErrBrokerNotServing
+	}
+	degraded := make([]error, 0)
+	defer func() { b.reportErrors(b.BaseContext, degraded) }() // runs once the lock is released
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	consumers := b.EventMux.Get(topic)
+	if len(consumers) == 0 {
+		return ErrTopicNotFound
+	}
+	errs := new(multierror.Error)
+	for _, c := range consumers {
+		if n, ok := b.supervisors[c]; ok {
+			if err := b.closeNodeLocked(c, n); err != nil {
+				errs = multierror.Append(errs, err)
+				continue
+			}
+		}
+		if err := b.startSupervisorLocked(b.BaseContext, c, &degraded); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+func (b *Broker) toggleTopic(topic string, fn func(*Supervisor) error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	errs := new(multierror.Error)
+	found := false
+	for c, n := range b.supervisors {
+		if !consumerHasTopic(c, topic) {
 			continue
 		}
-		b.activeSupervisors--
-		b.activeWorkers = n.runningWorkers.Length()
-		delete(b.supervisors, k)
+		found = true
+		if err := fn(n); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	if !found {
+		return ErrTopicNotFound
+	}
+	return errs.ErrorOrNil()
+}
+
+func (b *Broker) closeNodes() error {
+	errs := new(multierror.Error)
+	for c, n := range b.supervisors {
+		if err := b.closeNodeLocked(c, n); err != nil {
+			errs = multierror.Append(errs, err)
+		}
 	}
 
 	return errs.ErrorOrNil()
 }
 
+// closeNodeLocked stops the given Supervisor, it is kept in the registry if any of its workers failed to close
+func (b *Broker) closeNodeLocked(c *Consumer, n *Supervisor) error {
+	if err := n.Close(); err != nil {
+		return err
+	}
+	delete(b.supervisors, c)
+	return nil
+}
+
 // Topic adds new Consumer Supervisor to the given EventMux
 func (b *Broker) Topic(topic string) *Consumer {
 	b.setDefaultMux()
@@ -217,14 +440,121 @@ func (b *Broker) Topics(topics ...string) *Consumer {
 	return b.EventMux.Topics(topics...)
 }
 
-// activeSupervisors returns the current number of running supervisors
+// ActiveSupervisors returns the current number of running supervisors
 func (b *Broker) ActiveSupervisors() int {
-	return b.activeSupervisors
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.supervisors)
 }
 
-// activeWorkers returns the current number of running workers (inside every Supervisor)
+// ActiveWorkers returns the current number of running workers (inside every Supervisor)
 func (b *Broker) ActiveWorkers() int {
-	return b.activeWorkers
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	total := 0
+	for _, n := range b.supervisors {
+		total += n.runningWorkers.Length()
+	}
+	return total
+}
+
+// Status returns a snapshot of the current Broker state, including every running Supervisor and its workers
+func (b *Broker) Status() BrokerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := BrokerStatus{
+		Serving:      b.inService.isSet(),
+		ShuttingDown: b.shuttingDown(),
+		Supervisors:  make([]SupervisorStatus, 0, len(b.supervisors)),
+	}
+	for _, n := range b.supervisors {
+		st.Supervisors = append(st.Supervisors, n.Status())
+	}
+	sort.Slice(st.Supervisors, func(i, j int) bool {
+		return st.Supervisors[i].key() < st.Supervisors[j].key()
+	})
+	st.Unscheduled = b.unscheduledLocked()
+	st.CircuitBreakers = b.circuitBreakersLocked()
+	return st
+}
+
+// unscheduledLocked returns the consumers registered into the EventMux without a running Supervisor
+func (b *Broker) unscheduledLocked() []ConsumerStatus {
+	if b.EventMux == nil {
+		return nil
+	}
+	consumers := make([]ConsumerStatus, 0)
+	seen := make(map[*Consumer]struct{})
+	for _, cs := range b.EventMux.List() {
+		for _, c := range cs {
+			if _, ok := seen[c]; ok {
+				continue // consumers listening to N topics are registered N times in the EventMux
+			}
+			seen[c] = struct{}{}
+			if _, ok := b.supervisors[c]; !ok {
+				consumers = append(consumers, ConsumerStatus{Topics: c.GetTopics(), Group: c.GetGroup()})
+			}
+		}
+	}
+	sort.Slice(consumers, func(i, j int) bool {
+		return consumers[i].key() < consumers[j].key()
+	})
+	return consumers
+}
+
+// circuitBreakersLocked returns the status of every circuit breaker used as Broker or Consumer Publisher
+func (b *Broker) circuitBreakersLocked() []CircuitBreakerStatus {
+	breakers := make([]CircuitBreakerStatus, 0)
+	for _, p := range b.publishersLocked() {
+		if cb, ok := p.(*CircuitBreakerPublisher); ok {
+			breakers = append(breakers, cb.CircuitStatus())
+		}
+	}
+	sort.SliceStable(breakers, func(i, j int) bool {
+		return breakers[i].Name < breakers[j].Name
+	})
+	return breakers
+}
+
+// publishersLocked returns the Broker and running Consumer publishers without duplicates
+func (b *Broker) publishersLocked() []Publisher {
+	publishers := make([]Publisher, 0)
+	seen := make(map[Publisher]struct{})
+	add := func(p Publisher) {
+		if p == nil {
+			return
+		} else if reflect.TypeOf(p).Comparable() {
+			if _, ok := seen[p]; ok {
+				return
+			}
+			seen[p] = struct{}{}
+		}
+		publishers = append(publishers, p)
+	}
+	add(b.Publisher)
+	for _, n := range b.supervisors {
+		add(n.Consumer.publisher)
+	}
+	return publishers
+}
+
+// releasePublishers flushes the given publishers buffering messages (see Flusher), then closes the asynchronous ones
+// (see AsyncPublisher)
+func releasePublishers(ctx context.Context, publishers []Publisher) error {
+	errs := new(multierror.Error)
+	for _, p := range publishers {
+		if f, ok := p.(Flusher); ok {
+			errs = multierror.Append(errs, f.Flush(ctx))
+		}
+	}
+	for _, p := range publishers {
+		if a, ok := p.(AsyncPublisher); ok {
+			if err := a.Close(ctx); err != ErrPublisherClosed {
+				errs = multierror.Append(errs, err)
+			}
+		}
+	}
+	return errs.ErrorOrNil()
 }
 
 func (b *Broker) setDefaultMux() {
@@ -233,6 +563,23 @@ func (b *Broker) setDefaultMux() {
 	}
 }
 
+// setReplyConsumer registers the consumer routing replies to their awaiting requests
+func (b *Broker) setReplyConsumer() {
+	if b.ReplyTopic == "" || b.replyConsumerSet.isSet() {
+		return
+	}
+	// grouped, so partitioned providers (e.g. Apache Kafka) consume every partition of the reply topic
+	b.EventMux.Topic(b.ReplyTopic).Group(b.ReplyTopic).Handle(b.getReplyRouter())
+	b.replyConsumerSet.setTrue()
+}
+
+func (b *Broker) getReplyRouter() *replyRouter {
+	b.replyOnce.Do(func() {
+		b.replies = newReplyRouter()
+	})
+	return b.replies
+}
+
 func (b *Broker) setDefaultPoolSize() int {
 	if b.PoolSize > 0 {
 		return b.PoolSize
@@ -254,6 +601,24 @@ func (b *Broker) setDefaultRetryBackoff() time.Duration {
 	return defaultRetryBackoff
 }
 
+func (b *Broker) setDefaultStartupMode() StartupMode {
+	return b.StartupMode
+}
+
+func (b *Broker) setDefaultRestartPolicy() RestartPolicy {
+	if b.RestartPolicy != nil {
+		return b.RestartPolicy.withDefaults()
+	}
+	return DefaultRestartPolicy
+}
+
+func (b *Broker) setDefaultPanicPolicy() PanicPolicy {
+	if b.PanicPolicy != nil {
+		return *b.PanicPolicy
+	}
+	return DefaultPanicPolicy
+}
+
 func (b *Broker) setDefaultConnRetries() int {
 	if b.ConnRetries > 0 {
 		return b.ConnRetries
@@ -0,0 +1,290 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/quark"
+)
+
+// ConfigSectionName is the name of the Apache Kafka provider section inside a quark.Config
+const ConfigSectionName = "kafka"
+
+// SCRAMClientGenerator creates the SCRAM clients used by SCRAM-SHA-256 and SCRAM-SHA-512 SASL mechanisms.
+//
+// Must be set before building a Broker from a configuration using those mechanisms
+var SCRAMClientGenerator func() sarama.SCRAMClient
+
+// ConfigSection is the Apache Kafka provider section of a quark.Config
+//
+//	provider:
+//	  kafka:
+//	    version: 2.8.0
+//	    sasl: {enabled: true, mechanism: PLAIN, user: quark}
+type ConfigSection struct {
+	Version  string                `yaml:"version"`
+	ClientID string                `yaml:"client_id"`
+	Consumer ConsumerConfigSection `yaml:"consumer"`
+	Producer ProducerConfigSection `yaml:"producer"`
+	TLS      TLSConfigSection      `yaml:"tls"`
+	SASL     SASLConfigSection     `yaml:"sasl"`
+}
+
+// ConsumerConfigSection Apache Kafka consumer settings
+type ConsumerConfigSection struct {
+	// InitialOffset is either "newest" (default) or "oldest", used when no offset was committed
+	InitialOffset string `yaml:"initial_offset"`
+	// Partition consumed by partition consumers (consumers without a group)
+	Partition int32 `yaml:"partition"`
+	// Rebalance strategy is either "range" (default), "roundrobin" or "sticky"
+	Rebalance      string         `yaml:"rebalance"`
+	SessionTimeout quark.Duration `yaml:"session_timeout"`
+	ReturnErrors   bool           `yaml:"return_errors"`
+}
+
+// ProducerConfigSection Apache Kafka producer settings
+type ProducerConfigSection struct {
+	// RequiredAcks is either "all" (default), "local" or "none"
+	RequiredAcks string `yaml:"required_acks"`
+	Idempotent   bool   `yaml:"idempotent"`
+	// Compression is either "none" (default), "gzip", "snappy", "lz4" or "zstd"
+	Compression string `yaml:"compression"`
+	// KeyStrategy is either "id" (default), "subject", "correlation_id", "extension:<name>" or "header:<name>"
+	KeyStrategy          string `yaml:"key_strategy"`
+	PartitionPassthrough bool   `yaml:"partition_passthrough"`
+}
+
+// TLSConfigSection Apache Kafka TLS settings, certificates are read from PEM files
+type TLSConfigSection struct {
+	Enabled            bool   `yaml:"enabled"`
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// SASLConfigSection Apache Kafka SASL settings
+type SASLConfigSection struct {
+	Enabled bool `yaml:"enabled"`
+	// Mechanism is either "PLAIN" (default), "SCRAM-SHA-256" or "SCRAM-SHA-512"
+	Mechanism string `yaml:"mechanism"`
+	User      string `yaml:"user"`
+	Password  string `yaml:"password"`
+}
+
+// NewKafkaBrokerFromConfig allocates and returns a Kafka Broker and its consumers using the given configuration.
+//
+// The kafka provider section (if any) is overridden by QUARK_KAFKA_* environment variables (see
+// ConfigSection.ApplyEnv)
+func NewKafkaBrokerFromConfig(cfg *quark.Config, r *quark.HandlerRegistry, opts ...quark.Option) (*quark.Broker,
+	error) {
+	section := ConfigSection{}
+	if err := cfg.DecodeProvider(ConfigSectionName, &section); err != nil &&
+		!errors.Is(err, quark.ErrProviderConfigNotFound) {
+		return nil, err
+	}
+	if err := section.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	saramaCfg, err := section.SaramaConfig()
+	if err != nil {
+		return nil, err
+	}
+	keyStrategy, err := ParseKafkaKeyStrategy(section.Producer.KeyStrategy)
+	if err != nil {
+		return nil, err
+	}
+	b, err := quark.NewBrokerFromConfig(cfg, r, opts...)
+	if err != nil {
+		return nil, err
+	}
+	setDefaultKafkaConfig(saramaCfg, b)
+	if kafkaCfg, ok := b.ProviderConfig.(KafkaConfiguration); ok {
+		kafkaCfg.Consumer.Topic.Partition = section.Consumer.Partition
+		kafkaCfg.Producer.KeyStrategy = keyStrategy
+		kafkaCfg.Producer.PartitionPassthrough = section.Producer.PartitionPassthrough
+		b.ProviderConfig = kafkaCfg
+	}
+	setDefaultKafkaPublisher(b)
+	setDefaultKafkaWorkerFactory(b)
+	return b, nil
+}
+
+// ApplyEnv overrides section values with QUARK_KAFKA_* environment variables found by lookup (e.g. os.LookupEnv):
+// VERSION, CLIENT_ID, TLS_ENABLED, SASL_ENABLED, SASL_MECHANISM, SASL_USER and SASL_PASSWORD
+func (s *ConfigSection) ApplyEnv(lookup func(string) (string, bool)) error {
+	prefix := quark.EnvPrefix + "KAFKA_"
+	if v, ok := lookup(prefix + "VERSION"); ok {
+		s.Version = v
+	}
+	if v, ok := lookup(prefix + "CLIENT_ID"); ok {
+		s.ClientID = v
+	}
+	if v, ok := lookup(prefix + "SASL_MECHANISM"); ok {
+		s.SASL.Mechanism = v
+	}
+	if v, ok := lookup(prefix + "SASL_USER"); ok {
+		s.SASL.User = v
+	}
+	if v, ok := lookup(prefix + "SASL_PASSWORD"); ok {
+		s.SASL.Password = v
+	}
+	for key, dst := range map[string]*bool{"TLS_ENABLED": &s.TLS.Enabled, "SASL_ENABLED": &s.SASL.Enabled} {
+		if v, ok := lookup(prefix + key); ok {
+			enabled, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("%s%s: %w", prefix, key, err)
+			}
+			*dst = enabled
+		}
+	}
+	return nil
+}
+
+// SaramaConfig builds a sarama configuration from the section, zero values keep sarama defaults
+func (s ConfigSection) SaramaConfig() (*sarama.Config, error) {
+	cfg := sarama.NewConfig()
+	if s.Version != "" {
+		v, err := sarama.ParseKafkaVersion(s.Version)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Version = v
+	}
+	if s.ClientID != "" {
+		cfg.ClientID = s.ClientID
+	}
+	if err := s.Consumer.apply(cfg); err != nil {
+		return nil, err
+	}
+	if err := s.Producer.apply(cfg); err != nil {
+		return nil, err
+	}
+	if err := s.TLS.apply(cfg); err != nil {
+		return nil, err
+	}
+	if err := s.SASL.apply(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, cfg.Validate()
+}
+
+func (s ConsumerConfigSection) apply(cfg *sarama.Config) error {
+	switch s.InitialOffset {
+	case "", "newest":
+		cfg.Consumer.Offsets.Initial = sarama.OffsetNewest
+	case "oldest":
+		cfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+	default:
+		return invalidValue("consumer.initial_offset", s.InitialOffset)
+	}
+	switch s.Rebalance {
+	case "", "range":
+		cfg.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategyRange
+	case "roundrobin":
+		cfg.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategyRoundRobin
+	case "sticky":
+		cfg.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategySticky
+	default:
+		return invalidValue("consumer.rebalance", s.Rebalance)
+	}
+	if s.SessionTimeout > 0 {
+		cfg.Consumer.Group.Session.Timeout = time.Duration(s.SessionTimeout)
+	}
+	cfg.Consumer.Return.Errors = s.ReturnErrors
+	return nil
+}
+
+func (s ProducerConfigSection) apply(cfg *sarama.Config) error {
+	switch s.RequiredAcks {
+	case "", "all":
+		cfg.Producer.RequiredAcks = sarama.WaitForAll
+	case "local":
+		cfg.Producer.RequiredAcks = sarama.WaitForLocal
+	case "none":
+		cfg.Producer.RequiredAcks = sarama.NoResponse
+	default:
+		return invalidValue("producer.required_acks", s.RequiredAcks)
+	}
+	switch s.Compression {
+	case "", "none":
+		cfg.Producer.Compression = sarama.CompressionNone
+	case "gzip":
+		cfg.Producer.Compression = sarama.CompressionGZIP
+	case "snappy":
+		cfg.Producer.Compression = sarama.CompressionSnappy
+	case "lz4":
+		cfg.Producer.Compression = sarama.CompressionLZ4
+	case "zstd":
+		cfg.Producer.Compression = sarama.CompressionZSTD
+	default:
+		return invalidValue("producer.compression", s.Compression)
+	}
+	if s.Idempotent {
+		cfg.Producer.Idempotent = true
+		cfg.Producer.RequiredAcks = sarama.WaitForAll
+		cfg.Net.MaxOpenRequests = 1
+	}
+	return nil
+}
+
+func (s TLSConfigSection) apply(cfg *sarama.Config) error {
+	if !s.Enabled {
+		return nil
+	}
+	tlsCfg := &tls.Config{InsecureSkipVerify: s.InsecureSkipVerify}
+	if s.CAFile != "" {
+		ca, err := ioutil.ReadFile(s.CAFile)
+		if err != nil {
+			return err
+		}
+		tlsCfg.RootCAs = x509.NewCertPool()
+		if !tlsCfg.RootCAs.AppendCertsFromPEM(ca) {
+			return invalidValue("tls.ca_file", s.CAFile)
+		}
+	}
+	if s.CertFile != "" || s.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+		if err != nil {
+			return err
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	cfg.Net.TLS.Enable = true
+	cfg.Net.TLS.Config = tlsCfg
+	return nil
+}
+
+func (s SASLConfigSection) apply(cfg *sarama.Config) error {
+	if !s.Enabled {
+		return nil
+	}
+	cfg.Net.SASL.Enable = true
+	cfg.Net.SASL.User = s.User
+	cfg.Net.SASL.Password = s.Password
+	switch s.Mechanism {
+	case "", sarama.SASLTypePlaintext:
+		cfg.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+	case sarama.SASLTypeSCRAMSHA256, sarama.SASLTypeSCRAMSHA512:
+		if SCRAMClientGenerator == nil {
+			return fmt.Errorf("sasl.mechanism %s requires kafka.SCRAMClientGenerator: %w", s.Mechanism,
+				quark.ErrInvalidConfig)
+		}
+		cfg.Net.SASL.Mechanism = sarama.SASLMechanism(s.Mechanism)
+		cfg.Net.SASL.SCRAMClientGeneratorFunc = SCRAMClientGenerator
+	default:
+		return invalidValue("sasl.mechanism", s.Mechanism)
+	}
+	return nil
+}
+
+func invalidValue(field, value string) error {
+	return fmt.Errorf("kafka %s %q: %w", field, value, quark.ErrInvalidConfig)
+}
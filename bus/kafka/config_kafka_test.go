@@ -0,0 +1,87 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+)
+
+const testingKafkaConfig = `
+cluster: [localhost:9092]
+provider:
+  kafka:
+    version: 2.8.0
+    client_id: quark-test
+    consumer:
+      initial_offset: oldest
+      rebalance: sticky
+      session_timeout: 20s
+    producer:
+      compression: zstd
+      key_strategy: subject
+    sasl:
+      enabled: true
+      user: quark
+consumers:
+  - topics: [chat.0]
+    group: audit
+    handler: audit
+`
+
+func TestConfigSection_SaramaConfig(t *testing.T) {
+	t.Run("Kafka section into sarama config", func(t *testing.T) {
+		cfg, err := quark.ParseConfig([]byte(testingKafkaConfig))
+		assert.Nil(t, err)
+		section := ConfigSection{}
+		assert.Nil(t, cfg.DecodeProvider(ConfigSectionName, &section))
+		assert.Nil(t, section.ApplyEnv(func(key string) (string, bool) {
+			if key == "QUARK_KAFKA_SASL_PASSWORD" {
+				return "secret", true
+			}
+			return "", false
+		}))
+
+		saramaCfg, err := section.SaramaConfig()
+		assert.Nil(t, err)
+		assert.Equal(t, sarama.V2_8_0_0, saramaCfg.Version)
+		assert.Equal(t, "quark-test", saramaCfg.ClientID)
+		assert.Equal(t, sarama.OffsetOldest, saramaCfg.Consumer.Offsets.Initial)
+		assert.Equal(t, time.Second*20, saramaCfg.Consumer.Group.Session.Timeout)
+		assert.Equal(t, sarama.CompressionZSTD, saramaCfg.Producer.Compression)
+		assert.True(t, saramaCfg.Net.SASL.Enable)
+		assert.Equal(t, "secret", saramaCfg.Net.SASL.Password)
+	})
+
+	t.Run("Kafka section with invalid values", func(t *testing.T) {
+		_, err := ConfigSection{Consumer: ConsumerConfigSection{Rebalance: "random"}}.SaramaConfig()
+		assert.True(t, errors.Is(err, quark.ErrInvalidConfig))
+		_, err = ConfigSection{SASL: SASLConfigSection{Enabled: true, Mechanism: sarama.SASLTypeSCRAMSHA256}}.
+			SaramaConfig()
+		assert.True(t, errors.Is(err, quark.ErrInvalidConfig))
+	})
+}
+
+func TestNewKafkaBrokerFromConfig(t *testing.T) {
+	t.Run("New Kafka broker from config", func(t *testing.T) {
+		t.Setenv("QUARK_KAFKA_SASL_ENABLED", "false")
+		cfg, err := quark.ParseConfig([]byte(testingKafkaConfig))
+		assert.Nil(t, err)
+		r := quark.NewHandlerRegistry().RegisterFunc("audit", func(w quark.EventWriter, e *quark.Event) bool {
+			return true
+		})
+		b, err := NewKafkaBrokerFromConfig(cfg, r)
+		assert.Nil(t, err)
+		assert.NotNil(t, b.Publisher)
+		assert.NotNil(t, b.WorkerFactory)
+		kafkaCfg, ok := b.ProviderConfig.(KafkaConfiguration)
+		assert.True(t, ok)
+		assert.Equal(t, sarama.V2_8_0_0, kafkaCfg.Config.Version)
+		assert.Equal(t, "chat-1", kafkaCfg.Producer.KeyStrategy(&quark.Message{Id: "1", Subject: "chat-1"}))
+		assert.False(t, kafkaCfg.Producer.PartitionPassthrough)
+		assert.True(t, b.EventMux.Contains("chat.0"))
+	})
+}
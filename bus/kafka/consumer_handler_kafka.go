@@ -45,11 +45,12 @@ func (k *defaultKafkaPartitionConsumer) Consume(ctx context.Context, p sarama.Pa
 		}
 
 		if handler := c.GetHandle(); handler != nil {
-			handler.ServeEvent(e, ev)
+			k.worker.parent.Dispatch(handler.ServeEvent, e, ev)
 		}
 		if handleFunc := c.GetHandleFunc(); handleFunc != nil {
-			handleFunc(e, ev)
+			k.worker.parent.Dispatch(handleFunc, e, ev)
 		}
+		k.worker.SetOffset(msgConsumer.Topic, msgConsumer.Partition, msgConsumer.Offset)
 	}
 }
 
@@ -58,42 +59,59 @@ type defaultKafkaConsumer struct {
 	worker *kafkaWorker
 }
 
-func (k *defaultKafkaConsumer) Setup(_ sarama.ConsumerGroupSession) error {
+// Setup records the claimed partitions and moves the offsets of partitions the group did not commit any offset for
+// yet to the Consumer start position (if any), the position is committed before consuming the claims. Partitions with
+// committed offsets (e.g. process restarts, deploys and rebalances) resume from them
+func (k *defaultKafkaConsumer) Setup(session sarama.ConsumerGroupSession) error {
+	k.worker.SetPartitions(session.Claims())
+	startFrom := k.worker.parent.Consumer.GetStartFrom()
+	if startFrom.IsDefault() || k.worker.client == nil {
+		return nil
+	}
+	seeked := false
+	for topic, partitions := range session.Claims() {
+		for _, partition := range partitions {
+			committed, err := committedOffset(k.worker.client, k.worker.parent.GetGroup(), topic, partition)
+			if err != nil {
+				return err
+			} else if committed >= 0 {
+				continue
+			}
+			offset, err := ResolveStartOffset(k.worker.client, topic, partition, startFrom)
+			if err != nil {
+				return err
+			}
+			// sarama only marks offsets forward (including groups without committed offsets) and resets them backward
+			session.MarkOffset(topic, partition, offset, "")
+			session.ResetOffset(topic, partition, offset, "")
+			seeked = true
+		}
+	}
+	if seeked {
+		session.Commit() // restarted workers must resume from the start position
+	}
 	return nil
 }
 
 func (k *defaultKafkaConsumer) Cleanup(_ sarama.ConsumerGroupSession) error {
+	k.worker.SetPartitions(nil)
 	return nil
 }
 
 func (k *defaultKafkaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	// Note: DO NOT SEND ANY ERRORS BACK IF YOU DONT WANT TO STOP THE CONSUMER GROUP'S SESSION (All workers)
+	if k.worker.isPaused() && k.worker.group != nil {
+		// partitions claimed after a rebalance must honor the worker pause
+		k.worker.group.Pause(map[string][]int32{claim.Topic(): {claim.Partition()}})
+	}
 	for msgConsumer := range claim.Messages() {
-		if k.worker.cfg.Consumer.OnReceived != nil {
-			k.worker.cfg.Consumer.OnReceived(session.Context(), msgConsumer)
-		}
-
-		eventCtx := session.Context()
-		h := NewKafkaHeader(msgConsumer)
-		h.Set(HeaderMemberId, session.MemberID())
-		h.Set(HeaderGenerationId, strconv.Itoa(int(session.GenerationID())))
-		h.Set(quark.HeaderConsumerGroup, k.worker.parent.Consumer.GetGroup())
-		body := new(quark.Message)
-		UnmarshalKafkaMessage(msgConsumer, body)
-		e := &quark.Event{
-			Context:    eventCtx,
-			Topic:      msgConsumer.Topic,
-			Header:     h,
-			Body:       body,
-			RawValue:   msgConsumer.Value,
-			RawSession: session,
-		}
-
+		e := k.newEvent(session, msgConsumer)
+		h := e.Header
 		if handler := k.worker.parent.Consumer.GetHandle(); handler != nil {
 			// set up required parent data (tracing, redelivery and correlation)
 			evWriter := k.worker.parent.GetEventWriter()
 			evWriter.ReplaceHeader(newQuarkHeaders(h))
-			if commit := handler.ServeEvent(evWriter, e); commit {
+			if commit := k.worker.parent.Dispatch(handler.ServeEvent, evWriter, e); commit {
 				session.MarkMessage(msgConsumer, "")
 				session.Commit()
 			}
@@ -102,16 +120,38 @@ func (k *defaultKafkaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession,
 			// set up required parent data (tracing, redelivery and correlation)
 			evWriter := k.worker.parent.GetEventWriter()
 			evWriter.ReplaceHeader(newQuarkHeaders(h))
-			if commit := handlerFunc(evWriter, e); commit {
+			if commit := k.worker.parent.Dispatch(handlerFunc, evWriter, e); commit {
 				session.MarkMessage(msgConsumer, "")
 				session.Commit()
 			}
 		}
-
+		k.worker.SetOffset(msgConsumer.Topic, msgConsumer.Partition, msgConsumer.Offset)
 	}
 	return nil
 }
 
+// newEvent builds the quark.Event of a message consumed by the group session, calling the OnReceived hook
+func (k *defaultKafkaConsumer) newEvent(session sarama.ConsumerGroupSession,
+	msgConsumer *sarama.ConsumerMessage) *quark.Event {
+	if k.worker.cfg.Consumer.OnReceived != nil {
+		k.worker.cfg.Consumer.OnReceived(session.Context(), msgConsumer)
+	}
+	h := NewKafkaHeader(msgConsumer)
+	h.Set(HeaderMemberId, session.MemberID())
+	h.Set(HeaderGenerationId, strconv.Itoa(int(session.GenerationID())))
+	h.Set(quark.HeaderConsumerGroup, k.worker.parent.Consumer.GetGroup())
+	body := new(quark.Message)
+	UnmarshalKafkaMessage(msgConsumer, body)
+	return &quark.Event{
+		Context:    session.Context(),
+		Topic:      msgConsumer.Topic,
+		Header:     h,
+		Body:       body,
+		RawValue:   msgConsumer.Value,
+		RawSession: session,
+	}
+}
+
 func newQuarkHeaders(h quark.Header) quark.Header {
 	hEv := quark.Header{}
 	hEv.Set(quark.HeaderSpanContext, h.Get(quark.HeaderSpanContext))
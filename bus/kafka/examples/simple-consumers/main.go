@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
 	"os/signal"
@@ -10,6 +11,7 @@ import (
 	"github.com/Shopify/sarama"
 	"github.com/neutrinocorp/quark"
 	"github.com/neutrinocorp/quark/bus/kafka"
+	"github.com/neutrinocorp/quark/dlq"
 )
 
 var customErrHandler = func(ctx context.Context, err error) {
@@ -41,7 +43,7 @@ func main() {
 
 			/*
 				// Retry strategy
-				err := w.WriteRetry(e.Context, e.Body)
+				err := quark.WriteRetryError(e.Context, w, e.Body, errors.New("notification provider unavailable"))
 				if errors.Is(err, quark.ErrMessageRedeliveredTooMuch) {
 					_, _ = w.Write(e.Context, e.Body.Data, "dlq.chat.1")
 				}*/
@@ -53,7 +55,8 @@ func main() {
 			redelivery := e.Header.Get(quark.HeaderMessageRedeliveryCount)
 			log.Printf("topic: %s | message: %s", e.Topic, e.RawValue)
 			log.Printf("topic: %s | redelivery: %s", e.Topic, redelivery)
-			_, _ = w.Write(e.Context, e.Body.Data, "org.neutrinocorp.event.user.created.dlq")
+			_ = dlq.Write(e.Context, w, "org.neutrinocorp.event.user.created.dlq", e,
+				errors.New("analytics store unavailable"))
 			return true
 		})
 
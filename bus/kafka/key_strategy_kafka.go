@@ -0,0 +1,72 @@
+package kafka
+
+import (
+	"strings"
+
+	"github.com/neutrinocorp/quark"
+)
+
+// KafkaKeyStrategy resolves the Apache Kafka key of a Message. Messages sharing a key are stored in the same
+// partition (using a hash partitioner), keeping their order.
+//
+// Empty keys fall back to the Message id
+type KafkaKeyStrategy func(*quark.Message) string
+
+// KeyById keys messages by their id (default), spreading them across partitions without ordering guarantees
+func KeyById() KafkaKeyStrategy {
+	return func(msg *quark.Message) string {
+		return msg.Id
+	}
+}
+
+// KeyBySubject keys messages by their subject (e.g. an aggregate id), ordering messages of the same subject
+func KeyBySubject() KafkaKeyStrategy {
+	return func(msg *quark.Message) string {
+		return msg.Subject
+	}
+}
+
+// KeyByCorrelationId keys messages by their correlation id, ordering messages caused by the same root message
+func KeyByCorrelationId() KafkaKeyStrategy {
+	return func(msg *quark.Message) string {
+		return msg.Metadata.CorrelationId
+	}
+}
+
+// KeyByExtension keys messages by the given CloudEvents extension attribute (stored in Message ExternalData)
+func KeyByExtension(name string) KafkaKeyStrategy {
+	return func(msg *quark.Message) string {
+		return msg.Metadata.ExternalData[name]
+	}
+}
+
+// KeyByHeader keys messages by the value of the given Apache Kafka header (e.g. quark.HeaderMessageSource)
+func KeyByHeader(name string) KafkaKeyStrategy {
+	return func(msg *quark.Message) string {
+		for _, h := range MarshalKafkaHeaders(msg) {
+			if string(h.Key) == name {
+				return string(h.Value)
+			}
+		}
+		return ""
+	}
+}
+
+// ParseKafkaKeyStrategy parses a key strategy name, either "id" (default), "subject", "correlation_id",
+// "extension:<name>" or "header:<name>"
+func ParseKafkaKeyStrategy(s string) (KafkaKeyStrategy, error) {
+	switch s {
+	case "", "id":
+		return KeyById(), nil
+	case "subject":
+		return KeyBySubject(), nil
+	case "correlation_id":
+		return KeyByCorrelationId(), nil
+	}
+	if name := strings.TrimPrefix(s, "extension:"); name != s && name != "" {
+		return KeyByExtension(name), nil
+	} else if name = strings.TrimPrefix(s, "header:"); name != s && name != "" {
+		return KeyByHeader(name), nil
+	}
+	return nil, invalidValue("producer.key_strategy", s)
+}
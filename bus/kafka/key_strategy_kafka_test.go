@@ -0,0 +1,42 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+)
+
+var parseKafkaKeyStrategyTestingSuite = []struct {
+	description string
+	in          string
+	expKey      string
+	expErr      error
+}{
+	{"Parse default strategy", "", "1", nil},
+	{"Parse id strategy", "id", "1", nil},
+	{"Parse subject strategy", "subject", "account-9", nil},
+	{"Parse correlation id strategy", "correlation_id", "0", nil},
+	{"Parse extension strategy", "extension:tenant", "acme", nil},
+	{"Parse header strategy", "header:" + quark.HeaderMessageType, "ledger.posted", nil},
+	{"Parse extension strategy without name", "extension:", "", quark.ErrInvalidConfig},
+	{"Parse unknown strategy", "random", "", quark.ErrInvalidConfig},
+}
+
+func TestParseKafkaKeyStrategy(t *testing.T) {
+	msg := quark.NewMessageFromParent("0", "1", "ledger.posted", nil)
+	msg.Subject = "account-9"
+	msg.Metadata.ExternalData["tenant"] = "acme"
+	for _, tt := range parseKafkaKeyStrategyTestingSuite {
+		t.Run(tt.description, func(t *testing.T) {
+			strategy, err := ParseKafkaKeyStrategy(tt.in)
+			if tt.expErr != nil {
+				assert.True(t, errors.Is(err, tt.expErr))
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expKey, strategy(msg))
+		})
+	}
+}
@@ -8,25 +8,39 @@ import (
 	"github.com/neutrinocorp/quark"
 )
 
-// MarshalKafkaMessage parses the given Message into a Apache Kafka producer message
+// MarshalKafkaMessage parses the given Message into a Apache Kafka producer message keyed by the Message id
 func MarshalKafkaMessage(msg *quark.Message) *sarama.ProducerMessage {
-	partition, err := strconv.ParseInt(msg.Metadata.ExternalData[HeaderPartition], 10, 32)
-	if err != nil {
-		partition = 0
+	return MarshalKafkaProducerMessage(msg, KafkaProducerConfig{})
+}
+
+// MarshalKafkaProducerMessage parses the given Message into a Apache Kafka producer message keyed using the
+// KafkaProducerConfig KeyStrategy.
+//
+// The partition and offset of the Message (e.g. an inbound message forwarded by a handler) are only kept if
+// PartitionPassthrough is enabled
+func MarshalKafkaProducerMessage(msg *quark.Message, cfg KafkaProducerConfig) *sarama.ProducerMessage {
+	key := msg.Id
+	if cfg.KeyStrategy != nil {
+		if k := cfg.KeyStrategy(msg); k != "" {
+			key = k
+		}
 	}
-	offset, err := strconv.ParseInt(msg.Metadata.ExternalData[HeaderOffset], 10, 64)
-	if err != nil {
-		offset = 0
+	kafkaMsg := &sarama.ProducerMessage{
+		Topic:   msg.Type,
+		Key:     sarama.StringEncoder(key),
+		Value:   msg,
+		Headers: MarshalKafkaHeaders(msg),
 	}
-
-	return &sarama.ProducerMessage{
-		Topic:     msg.Type,
-		Key:       sarama.StringEncoder(msg.Id),
-		Value:     msg,
-		Headers:   MarshalKafkaHeaders(msg),
-		Offset:    offset,
-		Partition: int32(partition),
+	if !cfg.PartitionPassthrough {
+		return kafkaMsg
+	}
+	if partition, err := strconv.ParseInt(msg.Metadata.ExternalData[HeaderPartition], 10, 32); err == nil {
+		kafkaMsg.Partition = int32(partition)
+	}
+	if offset, err := strconv.ParseInt(msg.Metadata.ExternalData[HeaderOffset], 10, 64); err == nil {
+		kafkaMsg.Offset = offset
 	}
+	return kafkaMsg
 }
 
 // MarshalKafkaHeaders parses the given Message and its metadata into Apache Kafka's header types
@@ -86,8 +100,8 @@ func UnmarshalKafkaHeaders(headers []*sarama.RecordHeader, msg *quark.Message) {
 	for _, f := range headers {
 		switch string(f.Key) {
 		case quark.HeaderMessageId:
-			if msg.Id == "" {
-				msg.Id = string(f.Value)
+			if len(f.Value) > 0 {
+				msg.Id = string(f.Value) // keys might not hold the id (see KafkaKeyStrategy)
 			}
 		case quark.HeaderMessageType:
 			msg.Type = string(f.Value)
@@ -122,7 +136,8 @@ func UnmarshalKafkaHeaders(headers []*sarama.RecordHeader, msg *quark.Message) {
 	}
 }
 
-// UnmarshalKafkaMessage parses the given Apache Kafka message into a Message
+// UnmarshalKafkaMessage parses the given Apache Kafka message into a Message, its key is used as id if the message
+// has no id header
 func UnmarshalKafkaMessage(msgKafka *sarama.ConsumerMessage, msg *quark.Message) {
 	msg.Id = string(msgKafka.Key)
 	msg.Data = msgKafka.Value
@@ -1,6 +1,7 @@
 package kafka
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -72,3 +73,74 @@ func TestUnmarshalKafkaMessage(t *testing.T) {
 		assert.Equal(t, msg.SpecVersion, msgMock.SpecVersion)
 	})
 }
+
+func TestMarshalKafkaMessage_Failure(t *testing.T) {
+	t.Run("Keep failure headers on Kafka round trip", func(t *testing.T) {
+		msg := quark.NewMessage("1", "chat.0", []byte("hello there"))
+		f := quark.NewFailure(errors.New("cassandra: timeout"), "chat-service")
+		f.Host = "10.0.0.1"
+		f.Apply(msg)
+
+		msgProducer := MarshalKafkaMessage(msg)
+		headers := make([]*sarama.RecordHeader, 0, len(msgProducer.Headers))
+		for i := range msgProducer.Headers {
+			headers = append(headers, &msgProducer.Headers[i])
+		}
+		msgMock := new(quark.Message)
+		UnmarshalKafkaHeaders(headers, msgMock)
+
+		got, ok := quark.GetFailure(msgMock)
+		assert.True(t, ok)
+		assert.Equal(t, "cassandra: timeout", got.Error)
+		assert.Equal(t, "*errors.errorString", got.ErrorType)
+		assert.Equal(t, "chat-service", got.Group)
+		assert.Equal(t, "10.0.0.1", got.Host)
+		assert.True(t, f.Time.Equal(got.Time))
+		assert.True(t, f.FirstTime.Equal(got.FirstTime))
+	})
+}
+
+var marshalKafkaProducerMessageTestingSuite = []struct {
+	description  string
+	cfg          KafkaProducerConfig
+	expKey       string
+	expPartition int32
+}{
+	{"Key by id", KafkaProducerConfig{}, "1", 0},
+	{"Key by subject", KafkaProducerConfig{KeyStrategy: KeyBySubject()}, "account-9", 0},
+	{"Key by correlation id", KafkaProducerConfig{KeyStrategy: KeyByCorrelationId()}, "0", 0},
+	{"Key by extension", KafkaProducerConfig{KeyStrategy: KeyByExtension("tenant")}, "acme", 0},
+	{"Key by header", KafkaProducerConfig{KeyStrategy: KeyByHeader(quark.HeaderMessageSource)}, "ledger", 0},
+	{"Key by custom func", KafkaProducerConfig{KeyStrategy: func(msg *quark.Message) string {
+		return msg.Type + "/" + msg.Subject
+	}}, "ledger.posted/account-9", 0},
+	{"Fall back to id on empty key", KafkaProducerConfig{KeyStrategy: KeyByExtension("region")}, "1", 0},
+	{"Partition passthrough", KafkaProducerConfig{PartitionPassthrough: true}, "1", 4},
+}
+
+func TestMarshalKafkaProducerMessage(t *testing.T) {
+	for _, tt := range marshalKafkaProducerMessageTestingSuite {
+		t.Run(tt.description, func(t *testing.T) {
+			msg := quark.NewMessageFromParent("0", "1", "ledger.posted", nil)
+			msg.Subject = "account-9"
+			msg.Source = "ledger"
+			msg.Metadata.ExternalData["tenant"] = "acme"
+			msg.Metadata.ExternalData[HeaderPartition] = "4"
+
+			msgProducer := MarshalKafkaProducerMessage(msg, tt.cfg)
+			key, err := msgProducer.Key.Encode()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expKey, string(key))
+			assert.Equal(t, tt.expPartition, msgProducer.Partition)
+
+			// ids are kept whatever the key is
+			headers := make([]*sarama.RecordHeader, 0, len(msgProducer.Headers))
+			for i := range msgProducer.Headers {
+				headers = append(headers, &msgProducer.Headers[i])
+			}
+			msgMock := new(quark.Message)
+			UnmarshalKafkaMessage(&sarama.ConsumerMessage{Key: key, Headers: headers}, msgMock)
+			assert.Equal(t, "1", msgMock.Id)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package kafka
+
+import (
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/quark"
+)
+
+// ResolveStartOffset returns the Apache Kafka offset of the given start position for an specific topic partition.
+//
+// Timestamps are resolved using the partition offset index (first message at or after the given time), falling
+// back to the newest offset if no message was published after it
+func ResolveStartOffset(client sarama.Client, topic string, partition int32, p quark.StartPosition) (int64, error) {
+	switch p.Kind {
+	case quark.StartOffset:
+		return p.Offset, nil
+	case quark.StartOldest:
+		return client.GetOffset(topic, partition, sarama.OffsetOldest)
+	case quark.StartTime:
+		offset, err := client.GetOffset(topic, partition, p.Time.UnixNano()/int64(1e6))
+		if err != nil {
+			return 0, err
+		} else if offset == sarama.OffsetNewest {
+			return client.GetOffset(topic, partition, sarama.OffsetNewest)
+		}
+		return offset, nil
+	default:
+		return client.GetOffset(topic, partition, sarama.OffsetNewest)
+	}
+}
+
+// committedOffset returns the offset committed by the given consumer group for an specific topic partition, -1 if
+// the group did not commit any offset yet
+func committedOffset(client sarama.Client, group, topic string, partition int32) (int64, error) {
+	coordinator, err := client.Coordinator(group)
+	if err != nil {
+		return 0, err
+	}
+	req := new(sarama.OffsetFetchRequest)
+	req.Version = 1 // offsets stored by Apache Kafka instead of ZooKeeper
+	req.ConsumerGroup = group
+	req.AddPartition(topic, partition)
+	resp, err := coordinator.FetchOffset(req)
+	if err != nil {
+		return 0, err
+	}
+	block := resp.GetBlock(topic, partition)
+	if block == nil {
+		return 0, sarama.ErrIncompleteResponse
+	} else if block.Err != sarama.ErrNoError {
+		return 0, block.Err
+	}
+	return block.Offset, nil
+}
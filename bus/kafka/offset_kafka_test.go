@@ -0,0 +1,104 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveStartOffset(t *testing.T) {
+	t.Run("Resolve explicit start offset", func(t *testing.T) {
+		offset, err := ResolveStartOffset(nil, "chat.0", 0, quark.Offset(30))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(30), offset)
+	})
+}
+
+// offsetSessionStub moves offsets through a real sarama partition offset manager
+type offsetSessionStub struct {
+	sarama.ConsumerGroupSession
+	offsets sarama.OffsetManager
+	pom     sarama.PartitionOffsetManager
+}
+
+func (s *offsetSessionStub) Claims() map[string][]int32 { return map[string][]int32{"chat.0": {0}} }
+
+func (s *offsetSessionStub) MarkOffset(_ string, _ int32, offset int64, metadata string) {
+	s.pom.MarkOffset(offset, metadata)
+}
+
+func (s *offsetSessionStub) ResetOffset(_ string, _ int32, offset int64, metadata string) {
+	s.pom.ResetOffset(offset, metadata)
+}
+
+func (s *offsetSessionStub) Commit() { s.offsets.Commit() }
+
+var consumerSetupTestingSuite = []struct {
+	description string
+	committed   int64
+	start       quark.StartPosition
+	expOffset   int64
+}{
+	{"Seek new group to offset", -1, quark.Offset(40), 40},
+	{"Seek new group to newest", -1, quark.Newest, 100},
+	{"Seek new group to oldest", -1, quark.Oldest, 0},
+	{"Seek new group to timestamp", -1, quark.Timestamp(time.Unix(1600000000, 0)), 25},
+	{"Keep committed offset over start offset", 10, quark.Offset(40), 10},
+	{"Keep committed offset over oldest", 50, quark.Oldest, 50},
+	{"Keep committed offset by default", 50, quark.StartPosition{}, 50},
+}
+
+func TestDefaultKafkaConsumer_Setup(t *testing.T) {
+	for _, tt := range consumerSetupTestingSuite {
+		t.Run(tt.description, func(t *testing.T) {
+			broker := sarama.NewMockBroker(t, 1)
+			defer broker.Close()
+			broker.SetHandlerByMap(map[string]sarama.MockResponse{
+				"MetadataRequest": sarama.NewMockMetadataResponse(t).SetBroker(broker.Addr(), broker.BrokerID()).
+					SetLeader("chat.0", 0, broker.BrokerID()),
+				"OffsetRequest": sarama.NewMockOffsetResponse(t).
+					SetOffset("chat.0", 0, sarama.OffsetOldest, 0).
+					SetOffset("chat.0", 0, sarama.OffsetNewest, 100).
+					SetOffset("chat.0", 0, time.Unix(1600000000, 0).UnixNano()/int64(1e6), 25),
+				"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+					SetCoordinator(sarama.CoordinatorGroup, "audit", broker),
+				"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+					SetOffset("audit", "chat.0", 0, tt.committed, "", sarama.ErrNoError),
+				"OffsetCommitRequest": sarama.NewMockOffsetCommitResponse(t),
+			})
+			cfg := sarama.NewConfig()
+			cfg.Consumer.Offsets.AutoCommit.Enable = false
+			client, err := sarama.NewClient([]string{broker.Addr()}, cfg)
+			require.Nil(t, err)
+			defer client.Close()
+			offsets, err := sarama.NewOffsetManagerFromClient("audit", client)
+			require.Nil(t, err)
+			defer offsets.Close()
+			pom, err := offsets.ManagePartition("chat.0", 0)
+			require.Nil(t, err)
+			defer pom.AsyncClose()
+
+			h := &defaultKafkaConsumer{worker: &kafkaWorker{
+				parent: &quark.Supervisor{Broker: quark.NewBroker(),
+					Consumer: quark.NewConsumer("chat.0").Group("audit").StartFrom(tt.start)},
+				client: client,
+			}}
+			session := &offsetSessionStub{offsets: offsets, pom: pom}
+			assert.Nil(t, h.Setup(session))
+			offset, _ := pom.NextOffset()
+			assert.Equal(t, tt.expOffset, offset)
+			commits := 0
+			for _, rr := range broker.History() {
+				if _, ok := rr.Request.(*sarama.OffsetCommitRequest); ok {
+					commits++
+				}
+			}
+			// seeks are committed before consuming, restarts and rebalances resume from the committed offset
+			assert.Equal(t, !tt.start.IsDefault() && tt.committed < 0, commits > 0)
+		})
+	}
+}
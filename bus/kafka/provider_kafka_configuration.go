@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/quark"
 )
 
 // KafkaConfiguration Apache Kafka specific Broker and Consumer configuration, overrides default values,
@@ -19,18 +20,43 @@ type KafkaConsumerConfig struct {
 	GroupHandler     sarama.ConsumerGroupHandler
 	PartitionHandler KafkaPartitionConsumer
 	Topic            KafkaConsumerTopicConfig
+	// Transaction enables the consume-transform-produce mode of consumer groups
+	Transaction KafkaTransactionConfig
 	// Hooks
 	OnReceived func(context.Context, *sarama.ConsumerMessage)
 }
 
 // KafkaConsumerTopicConfig Apache Kafka configuration used to override default consuming values
+//
+// Offset is only used by partition consumers without a quark.Consumer StartFrom position
 type KafkaConsumerTopicConfig struct {
 	Partition int32
 	Offset    int64
 }
 
+// KafkaTransactionConfig Apache Kafka consume-transform-produce configuration.
+//
+// When enabled, every message consumed by a consumer group is handled inside a Kafka transaction: the messages
+// written by the handler EventWriter and the consumed offset are committed atomically. Messages not acknowledged abort
+// their transaction and restart the worker, consuming the partition again from its last committed offset.
+//
+// Workers read using sarama.ReadCommitted, downstream consumers of the produced topics must set the same
+// Consumer.IsolationLevel to skip the messages of aborted transactions
+type KafkaTransactionConfig struct {
+	Enabled bool
+	// IdPrefix of the transactional ids, one per claimed partition (default the consumer group)
+	IdPrefix string
+}
+
 // KafkaProducerConfig Apache Kafka producer configuration
 type KafkaProducerConfig struct {
+	// Batch defines how KafkaAsyncPublisher batches messages, mapped into the sarama producer flush configuration
+	Batch quark.BatchConfig
+	// KeyStrategy resolves the key of produced messages (default KeyById)
+	KeyStrategy KafkaKeyStrategy
+	// PartitionPassthrough keeps the partition of forwarded messages (HeaderPartition), requires a manual partitioner
+	// (e.g. sarama.NewManualPartitioner)
+	PartitionPassthrough bool
 	// Hooks
 	OnSent func(ctx context.Context, message *sarama.ProducerMessage, partition int32, offset int64)
 }
@@ -0,0 +1,221 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/quark"
+)
+
+// KafkaAsyncPublisher Quark asynchronous publisher for Kafka, messages are batched by a sarama.AsyncProducer
+// following the KafkaProducerConfig Batch configuration.
+//
+// Every message is resolved through its quark.Delivery future once Kafka acknowledged or rejected it. Close must be
+// called to release the underlying producer (a Broker closes it on Shutdown if used as one of its publishers)
+type KafkaAsyncPublisher struct {
+	cfg      KafkaConfiguration
+	producer sarama.AsyncProducer
+
+	mu     sync.RWMutex
+	closed bool
+	done   sync.WaitGroup
+
+	flightMu sync.Mutex
+	inFlight int
+	idle     chan struct{}
+}
+
+// asyncMessage is the metadata of a message sent through the sarama.AsyncProducer
+type asyncMessage struct {
+	ctx      context.Context
+	delivery *quark.Delivery
+}
+
+var _ quark.AsyncPublisher = &KafkaAsyncPublisher{}
+
+// NewKafkaAsyncPublisher allocates a new KafkaAsyncPublisher and its underlying sarama.AsyncProducer
+func NewKafkaAsyncPublisher(cfg KafkaConfiguration, addrs ...string) (*KafkaAsyncPublisher, error) {
+	producer, err := sarama.NewAsyncProducer(addrs, newAsyncProducerConfig(cfg))
+	if err != nil {
+		return nil, err
+	}
+	return newKafkaAsyncPublisher(cfg, producer), nil
+}
+
+// newAsyncProducerConfig copies the sarama configuration enabling delivery reports and applying the batching options
+func newAsyncProducerConfig(cfg KafkaConfiguration) *sarama.Config {
+	config := sarama.NewConfig()
+	if cfg.Config != nil {
+		*config = *cfg.Config
+	}
+	config.Producer.Return.Successes = true
+	config.Producer.Return.Errors = true
+	batch := cfg.Producer.Batch
+	if batch.MaxMessages > 0 {
+		config.Producer.Flush.Messages = batch.MaxMessages
+	}
+	if batch.MaxBytes > 0 {
+		config.Producer.Flush.Bytes = batch.MaxBytes
+	}
+	if batch.Linger > 0 {
+		config.Producer.Flush.Frequency = batch.Linger
+	}
+	if batch.BufferSize > 0 {
+		config.ChannelBufferSize = batch.BufferSize
+	}
+	return config
+}
+
+func newKafkaAsyncPublisher(cfg KafkaConfiguration, producer sarama.AsyncProducer) *KafkaAsyncPublisher {
+	p := &KafkaAsyncPublisher{
+		cfg:      cfg,
+		producer: producer,
+	}
+	p.done.Add(2)
+	go p.routeSuccesses()
+	go p.routeErrors()
+	return p
+}
+
+// Publish enqueues the given messages, returning before Kafka acknowledged them; delivery errors are reported through
+// the Producer hooks. EventWriter(s) wait for the Delivery futures instead (see quark.AsyncPublisher).
+//
+// Returns quark.ErrEmptyMessage (nothing is enqueued), quark.ErrPublisherClosed or the ctx error if it is done before
+// every message was enqueued
+func (d *KafkaAsyncPublisher) Publish(ctx context.Context, messages ...*quark.Message) error {
+	for _, msg := range messages {
+		if msg == nil {
+			return quark.ErrEmptyMessage
+		}
+	}
+	for _, delivery := range d.PublishAsync(ctx, messages...) {
+		if err := delivery.Err(); err == quark.ErrPublisherClosed || (err != nil && err == ctx.Err()) {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishAsync enqueues the given messages into the sarama.AsyncProducer returning a Delivery future per message.
+//
+// Messages are resolved with the ctx error if it is done before they are enqueued
+func (d *KafkaAsyncPublisher) PublishAsync(ctx context.Context, messages ...*quark.Message) []*quark.Delivery {
+	deliveries := make([]*quark.Delivery, 0, len(messages))
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, msg := range messages {
+		delivery := quark.NewDelivery(msg)
+		deliveries = append(deliveries, delivery)
+		if d.closed {
+			d.complete(delivery, quark.ErrPublisherClosed)
+			continue
+		} else if msg == nil {
+			d.complete(delivery, quark.ErrEmptyMessage)
+			continue
+		} else if err := ctx.Err(); err != nil {
+			d.complete(delivery, err)
+			continue
+		}
+		kafkaMsg := MarshalKafkaProducerMessage(msg, d.cfg.Producer)
+		kafkaMsg.Metadata = asyncMessage{ctx: ctx, delivery: delivery}
+		d.addInFlight(1)
+		select {
+		case d.producer.Input() <- kafkaMsg:
+		case <-ctx.Done():
+			d.addInFlight(-1)
+			d.complete(delivery, ctx.Err())
+		}
+	}
+	return deliveries
+}
+
+// Flush blocks until every enqueued message was acknowledged or rejected by Kafka, or ctx is done.
+//
+// Messages are sent following the sarama producer flush configuration (e.g. Producer.Flush.Frequency)
+func (d *KafkaAsyncPublisher) Flush(ctx context.Context) error {
+	d.flightMu.Lock()
+	idle := d.idle
+	d.flightMu.Unlock()
+	if idle == nil {
+		return nil
+	}
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close flushes every enqueued message and closes the underlying sarama.AsyncProducer, blocks until every message
+// was resolved or ctx is done
+func (d *KafkaAsyncPublisher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return quark.ErrPublisherClosed
+	}
+	d.closed = true
+	d.mu.Unlock()
+	d.producer.AsyncClose()
+	closed := make(chan struct{})
+	go func() {
+		d.done.Wait() // successes and errors channels are closed once every message was resolved
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *KafkaAsyncPublisher) routeSuccesses() {
+	defer d.done.Done()
+	for kafkaMsg := range d.producer.Successes() {
+		msg, ok := kafkaMsg.Metadata.(asyncMessage)
+		if !ok {
+			continue
+		}
+		if d.cfg.Producer.OnSent != nil {
+			d.cfg.Producer.OnSent(msg.ctx, kafkaMsg, kafkaMsg.Partition, kafkaMsg.Offset)
+		}
+		d.complete(msg.delivery, nil)
+		d.addInFlight(-1)
+	}
+}
+
+func (d *KafkaAsyncPublisher) routeErrors() {
+	defer d.done.Done()
+	for errProducer := range d.producer.Errors() {
+		msg, ok := errProducer.Msg.Metadata.(asyncMessage)
+		if !ok {
+			continue
+		}
+		d.complete(msg.delivery, errProducer.Err)
+		d.addInFlight(-1)
+	}
+}
+
+// addInFlight tracks the messages waiting for a delivery report, Flush waits on the idle channel until there are
+// none
+func (d *KafkaAsyncPublisher) addInFlight(delta int) {
+	d.flightMu.Lock()
+	defer d.flightMu.Unlock()
+	d.inFlight += delta
+	if d.inFlight > 0 && d.idle == nil {
+		d.idle = make(chan struct{})
+	} else if d.inFlight == 0 && d.idle != nil {
+		close(d.idle)
+		d.idle = nil
+	}
+}
+
+func (d *KafkaAsyncPublisher) complete(delivery *quark.Delivery, err error) {
+	delivery.Complete(err)
+	if d.cfg.Producer.Batch.OnDelivery != nil {
+		d.cfg.Producer.Batch.OnDelivery(delivery)
+	}
+}
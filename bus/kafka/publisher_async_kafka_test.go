@@ -0,0 +1,74 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaAsyncPublisher_PublishAsync(t *testing.T) {
+	var mu sync.Mutex
+	sent := make([]string, 0)
+	cfg := KafkaConfiguration{
+		Producer: KafkaProducerConfig{
+			OnSent: func(_ context.Context, msg *sarama.ProducerMessage, _ int32, _ int64) {
+				mu.Lock()
+				defer mu.Unlock()
+				sent = append(sent, msg.Topic)
+			},
+		},
+	}
+	producer := mocks.NewAsyncProducer(t, newAsyncProducerConfig(cfg))
+	producer.ExpectInputAndSucceed()
+	producer.ExpectInputAndFail(sarama.ErrNotLeaderForPartition)
+	p := newKafkaAsyncPublisher(cfg, producer)
+
+	ctx := context.Background()
+	deliveries := p.PublishAsync(ctx, quark.NewMessage("1", "chat.1", nil), quark.NewMessage("2", "chat.2", nil),
+		nil)
+	require.Len(t, deliveries, 3)
+
+	flushCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	assert.Nil(t, p.Flush(flushCtx))
+	assert.Nil(t, deliveries[0].Err())
+	assert.True(t, errors.Is(deliveries[1].Err(), sarama.ErrNotLeaderForPartition))
+	assert.True(t, errors.Is(deliveries[2].Err(), quark.ErrEmptyMessage))
+	mu.Lock()
+	assert.Equal(t, []string{"chat.1"}, sent)
+	mu.Unlock()
+
+	assert.True(t, errors.Is(p.Publish(ctx, quark.NewMessage("3", "chat.1", nil), nil), quark.ErrEmptyMessage))
+	cancelCtx, cancelPublish := context.WithCancel(ctx)
+	cancelPublish()
+	assert.True(t, errors.Is(p.Publish(cancelCtx, quark.NewMessage("3", "chat.1", nil)), context.Canceled))
+
+	assert.Nil(t, p.Close(ctx))
+	assert.True(t, errors.Is(p.Publish(ctx, quark.NewMessage("3", "chat.1", nil)), quark.ErrPublisherClosed))
+	assert.True(t, errors.Is(p.Close(ctx), quark.ErrPublisherClosed))
+}
+
+func TestNewAsyncProducerConfig(t *testing.T) {
+	base := sarama.NewConfig()
+	base.ClientID = "payments"
+	config := newAsyncProducerConfig(KafkaConfiguration{
+		Config: base,
+		Producer: KafkaProducerConfig{
+			Batch: quark.BatchConfig{MaxMessages: 50, MaxBytes: 1024, Linger: time.Millisecond * 5},
+		},
+	})
+	assert.Equal(t, "payments", config.ClientID)
+	assert.True(t, config.Producer.Return.Successes)
+	assert.Equal(t, 50, config.Producer.Flush.Messages)
+	assert.Equal(t, 1024, config.Producer.Flush.Bytes)
+	assert.Equal(t, time.Millisecond*5, config.Producer.Flush.Frequency)
+	assert.False(t, base.Producer.Return.Successes)
+}
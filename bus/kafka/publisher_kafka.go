@@ -39,7 +39,7 @@ func (d *KafkaPublisher) Publish(ctx context.Context, messages ...*quark.Message
 }
 
 func (d *KafkaPublisher) sendMessage(ctx context.Context, p sarama.SyncProducer, msg *quark.Message) error {
-	kafkaMsg := MarshalKafkaMessage(msg)
+	kafkaMsg := MarshalKafkaProducerMessage(msg, d.cfg.Producer)
 	partition, offset, err := p.SendMessage(kafkaMsg)
 	if err != nil {
 		return err
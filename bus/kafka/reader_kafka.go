@@ -0,0 +1,175 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/hashicorp/go-multierror"
+	"github.com/neutrinocorp/quark"
+	"github.com/neutrinocorp/quark/dlq"
+)
+
+// TopicReader reads every partition of an Apache Kafka topic without a consumer group, no offsets are committed.
+//
+// Useful to inspect topics such as Dead Letter Queues (implements dlq.Reader)
+type TopicReader struct {
+	cfg     *sarama.Config
+	cluster []string
+
+	// From position every partition is read from, oldest by default
+	From quark.StartPosition
+	// Follow waits for new messages, otherwise reading stops at the high-water mark of every partition.
+	//
+	// The last offsets of a partition might not hold readable messages (e.g. transaction markers), so reading a
+	// partition also stops once no message was received during IdleTimeout
+	Follow bool
+	// IdleTimeout time a partition might go without messages before it is considered fully read if not following,
+	// 1 second by default
+	IdleTimeout time.Duration
+}
+
+var defaultReaderIdleTimeout = time.Second
+
+var _ dlq.Reader = &TopicReader{}
+
+// NewTopicReader allocates a new TopicReader
+func NewTopicReader(cfg *sarama.Config, addrs ...string) *TopicReader {
+	return &TopicReader{
+		cfg:         cfg,
+		cluster:     addrs,
+		From:        quark.Oldest,
+		IdleTimeout: defaultReaderIdleTimeout,
+	}
+}
+
+// Read reads the given topic calling fn for each message until fn returns false or the context is done
+func (r *TopicReader) Read(ctx context.Context, topic string, fn func(*quark.Message) bool) error {
+	return r.ReadMessages(ctx, topic, func(msgKafka *sarama.ConsumerMessage) bool {
+		msg := new(quark.Message)
+		UnmarshalKafkaMessage(msgKafka, msg)
+		return fn(msg)
+	})
+}
+
+// ReadMessages reads the given topic calling fn for each Apache Kafka message until fn returns false or the context
+// is done
+func (r *TopicReader) ReadMessages(ctx context.Context, topic string, fn func(*sarama.ConsumerMessage) bool) error {
+	client, err := sarama.NewClient(r.cluster, r.cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+	partitions, err := client.Partitions(topic)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	msgs := make(chan *sarama.ConsumerMessage)
+	errs := new(multierror.Error)
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	abort := func(err error) error {
+		cancel()
+		wg.Wait()
+		return err
+	}
+	for _, partition := range partitions {
+		offset, end, err := r.partitionRange(client, topic, partition)
+		if err != nil {
+			return abort(err)
+		} else if !r.Follow && offset >= end {
+			continue // nothing to read
+		}
+		pc, err := consumer.ConsumePartition(topic, partition, offset)
+		if err != nil {
+			return abort(err)
+		}
+		var idle <-chan time.Time
+		if !r.Follow {
+			ticker := time.NewTicker(r.setDefaultIdleTimeout())
+			defer ticker.Stop()
+			idle = ticker.C
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer pc.AsyncClose()
+			received := true // grace interval for the first fetch
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-idle:
+					if !received && len(pc.Messages()) == 0 {
+						return // remaining offsets up to the high-water mark hold no readable messages
+					}
+					received = false
+				case err := <-pc.Errors():
+					mu.Lock()
+					errs = multierror.Append(errs, err)
+					mu.Unlock()
+					cancel()
+					return
+				case msg := <-pc.Messages():
+					received = true
+					select {
+					case msgs <- msg:
+					case <-ctx.Done():
+						return
+					}
+					if !r.Follow && msg.Offset >= end-1 {
+						return
+					}
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(msgs)
+	}()
+
+	for msg := range msgs {
+		if !fn(msg) {
+			cancel()
+			break
+		}
+	}
+	wg.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	return errs.ErrorOrNil()
+}
+
+// partitionRange returns the resolved start offset and the high-water mark of a topic partition
+func (r *TopicReader) partitionRange(client sarama.Client, topic string, partition int32) (int64, int64, error) {
+	end, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, 0, err
+	}
+	from := r.From
+	if from.IsDefault() {
+		from = quark.Newest
+	}
+	offset, err := ResolveStartOffset(client, topic, partition, from)
+	if err != nil {
+		return 0, 0, err
+	}
+	return offset, end, nil
+}
+
+func (r *TopicReader) setDefaultIdleTimeout() time.Duration {
+	if r.IdleTimeout > 0 {
+		return r.IdleTimeout
+	}
+	return defaultReaderIdleTimeout
+}
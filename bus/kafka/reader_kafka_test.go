@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTopicReader(t *testing.T) {
+	t.Run("Read topics from oldest until high-water mark", func(t *testing.T) {
+		r := NewTopicReader(sarama.NewConfig(), "localhost:9092")
+		assert.Equal(t, quark.Oldest, r.From)
+		assert.False(t, r.Follow)
+		assert.Equal(t, time.Second, r.IdleTimeout)
+		assert.Equal(t, []string{"localhost:9092"}, r.cluster)
+	})
+}
+
+var topicReaderTestingSuite = []struct {
+	description string
+	offsets     []int64
+}{
+	{"Read until the last message", []int64{0, 1, 2}},
+	{"Read until a trailing transaction marker", []int64{0, 1}}, // offset 2 is a control record
+}
+
+func TestTopicReader_ReadMessages(t *testing.T) {
+	for _, tt := range topicReaderTestingSuite {
+		t.Run(tt.description, func(t *testing.T) {
+			broker := sarama.NewMockBroker(t, 1)
+			defer broker.Close()
+			fetch := sarama.NewMockFetchResponse(t, 1).SetHighWaterMark("chat.dlq", 0, 3)
+			for _, offset := range tt.offsets {
+				fetch.SetMessage("chat.dlq", 0, offset, sarama.StringEncoder("foo"))
+			}
+			broker.SetHandlerByMap(map[string]sarama.MockResponse{
+				"MetadataRequest": sarama.NewMockMetadataResponse(t).SetBroker(broker.Addr(), broker.BrokerID()).
+					SetLeader("chat.dlq", 0, broker.BrokerID()),
+				"OffsetRequest": sarama.NewMockOffsetResponse(t).
+					SetOffset("chat.dlq", 0, sarama.OffsetOldest, 0).
+					SetOffset("chat.dlq", 0, sarama.OffsetNewest, 3),
+				"FetchRequest": fetch,
+			})
+			cfg := sarama.NewConfig()
+			cfg.Consumer.MaxWaitTime = time.Millisecond * 10
+			r := NewTopicReader(cfg, broker.Addr())
+			r.IdleTimeout = time.Millisecond * 100
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+			defer cancel()
+
+			read := make([]int64, 0)
+			err := r.ReadMessages(ctx, "chat.dlq", func(msg *sarama.ConsumerMessage) bool {
+				read = append(read, msg.Offset)
+				return true
+			})
+			assert.Nil(t, err)
+			assert.Nil(t, ctx.Err()) // reading stopped before the deadline
+			assert.Equal(t, tt.offsets, read)
+		})
+	}
+}
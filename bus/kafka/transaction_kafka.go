@@ -0,0 +1,211 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/Shopify/sarama"
+	"github.com/hashicorp/go-multierror"
+	"github.com/neutrinocorp/quark"
+)
+
+// errTxnNotAcknowledged the handler did not acknowledge the message, its transaction was aborted
+var errTxnNotAcknowledged = errors.New("message not acknowledged")
+
+// transactionalKafkaConsumer Implements sarama.ConsumerGroupHandler handling every message inside a Kafka
+// transaction (consume-transform-produce).
+//
+// Each claimed partition gets its own transactional producer with a stable transactional id
+// (<prefix>-<topic>-<partition>). Once a rebalance assigns the partition to another member of the group, the new
+// owner initializes the same transactional id, fencing the producer of the previous generation so its pending
+// transaction can never commit. Transactions are also aborted if the group session of their generation ended.
+//
+// Messages not acknowledged by the handler abort their transaction and stop the claim, the worker is restarted and
+// consumes the partition again from the last committed offset, so following messages never commit an offset past
+// them.
+type transactionalKafkaConsumer struct {
+	defaultKafkaConsumer
+	done        <-chan struct{} // worker job run
+	newProducer func(*sarama.Config) (sarama.AsyncProducer, error)
+}
+
+func newTransactionalKafkaConsumer(w *kafkaWorker, done <-chan struct{}) *transactionalKafkaConsumer {
+	return &transactionalKafkaConsumer{
+		defaultKafkaConsumer: defaultKafkaConsumer{worker: w},
+		done:                 done,
+		newProducer: func(config *sarama.Config) (sarama.AsyncProducer, error) {
+			return sarama.NewAsyncProducer(w.parent.GetCluster(), config)
+		},
+	}
+}
+
+func (k *transactionalKafkaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession,
+	claim sarama.ConsumerGroupClaim) error {
+	if k.worker.isPaused() && k.worker.group != nil {
+		k.worker.group.Pause(map[string][]int32{claim.Topic(): {claim.Partition()}})
+	}
+	producer, err := k.newProducer(newTransactionalProducerConfig(k.worker.cfg,
+		k.transactionalID(claim.Topic(), claim.Partition())))
+	if err != nil {
+		return k.fail(err)
+	}
+	defer k.routeReports(session.Context(), producer)()
+
+	for msgConsumer := range claim.Messages() {
+		if err = k.consumeMessage(session, producer, msgConsumer); err != nil {
+			return k.fail(err)
+		}
+		k.worker.SetOffset(msgConsumer.Topic, msgConsumer.Partition, msgConsumer.Offset)
+	}
+	return nil
+}
+
+// consumeMessage dispatches the message inside a transaction, committing the handler writes along with the message
+// offset if the handler acknowledged it. Not acknowledged messages abort the transaction, returning
+// errTxnNotAcknowledged
+func (k *transactionalKafkaConsumer) consumeMessage(session sarama.ConsumerGroupSession, producer sarama.AsyncProducer,
+	msgConsumer *sarama.ConsumerMessage) error {
+	if err := producer.BeginTxn(); err != nil {
+		return err
+	}
+	e := k.newEvent(session, msgConsumer)
+	txnPublisher := &transactionalPublisher{producer: producer, cfg: k.worker.cfg.Producer}
+	dispatched, ack := false, true
+	if handler := k.worker.parent.Consumer.GetHandle(); handler != nil {
+		evWriter := k.worker.parent.NewEventWriter(txnPublisher)
+		evWriter.ReplaceHeader(newQuarkHeaders(e.Header))
+		dispatched, ack = true, k.worker.parent.Dispatch(handler.ServeEvent, evWriter, e)
+	}
+	if handlerFunc := k.worker.parent.Consumer.GetHandleFunc(); handlerFunc != nil {
+		evWriter := k.worker.parent.NewEventWriter(txnPublisher)
+		evWriter.ReplaceHeader(newQuarkHeaders(e.Header))
+		dispatched, ack = true, k.worker.parent.Dispatch(handlerFunc, evWriter, e) && ack
+	}
+	if !dispatched || session.Context().Err() != nil {
+		// the generation ended (rebalance), its new owner consumes the message again
+		return producer.AbortTxn()
+	} else if !ack {
+		// handler writes (e.g. retries) belong to the aborted transaction, the message is consumed again instead
+		return k.abort(producer, errTxnNotAcknowledged)
+	}
+
+	metadata := "generation=" + strconv.Itoa(int(session.GenerationID()))
+	if err := producer.AddMessageToTxn(msgConsumer, k.worker.parent.GetGroup(), &metadata); err != nil {
+		return k.abort(producer, err)
+	}
+	if err := producer.CommitTxn(); err != nil {
+		return k.abort(producer, err)
+	}
+	return nil
+}
+
+// abort aborts the current transaction, returning the cause along with the abort error (if any)
+func (k *transactionalKafkaConsumer) abort(producer sarama.AsyncProducer, cause error) error {
+	errs := multierror.Append(new(multierror.Error), cause)
+	if producer.TxnStatus()&sarama.ProducerTxnFlagFatalError == 0 {
+		if err := producer.AbortTxn(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// fail notifies the Supervisor so the worker is restarted and the partition consumed again from its committed
+// offset, as sarama stops consuming a claim returning an error until the next rebalance
+func (k *transactionalKafkaConsumer) fail(err error) error {
+	err = fmt.Errorf("kafka transaction: %w", err)
+	k.worker.notifyExit(k.done, err)
+	return err
+}
+
+// routeReports routes the producer delivery reports to the hooks, the returned function closes the producer
+func (k *transactionalKafkaConsumer) routeReports(ctx context.Context, producer sarama.AsyncProducer) func() {
+	wg := new(sync.WaitGroup)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for msg := range producer.Successes() {
+			if k.worker.cfg.Producer.OnSent != nil {
+				k.worker.cfg.Producer.OnSent(ctx, msg, msg.Partition, msg.Offset)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for errProducer := range producer.Errors() {
+			if k.worker.parent.Broker.ErrorHandler != nil {
+				k.worker.parent.Broker.ErrorHandler(ctx, errProducer)
+			}
+		}
+	}()
+	return func() {
+		producer.AsyncClose()
+		wg.Wait()
+	}
+}
+
+func (k *transactionalKafkaConsumer) transactionalID(topic string, partition int32) string {
+	prefix := k.worker.cfg.Consumer.Transaction.IdPrefix
+	if prefix == "" {
+		prefix = k.worker.parent.GetGroup()
+	}
+	return prefix + "-" + topic + "-" + strconv.Itoa(int(partition))
+}
+
+// newTransactionalProducerConfig copies the sarama configuration enabling the idempotent transactional producer
+func newTransactionalProducerConfig(cfg KafkaConfiguration, id string) *sarama.Config {
+	config := sarama.NewConfig()
+	if cfg.Config != nil {
+		*config = *cfg.Config
+	}
+	if !config.Version.IsAtLeast(sarama.V0_11_0_0) {
+		config.Version = sarama.V0_11_0_0
+	}
+	config.Producer.Idempotent = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Transaction.ID = id
+	config.Producer.Return.Successes = true
+	config.Producer.Return.Errors = true
+	config.Net.MaxOpenRequests = 1
+	return config
+}
+
+// newTransactionalConsumerConfig copies the sarama configuration reading committed messages only, so messages of
+// transactions aborted by upstream consume-transform-produce stages are skipped
+func newTransactionalConsumerConfig(cfg KafkaConfiguration) *sarama.Config {
+	config := sarama.NewConfig()
+	if cfg.Config != nil {
+		*config = *cfg.Config
+	}
+	if !config.Version.IsAtLeast(sarama.V0_11_0_0) {
+		config.Version = sarama.V0_11_0_0
+	}
+	config.Consumer.IsolationLevel = sarama.ReadCommitted
+	return config
+}
+
+// transactionalPublisher enqueues messages into the current transaction of a producer, delivery errors are returned
+// when committing the transaction
+type transactionalPublisher struct {
+	producer sarama.AsyncProducer
+	cfg      KafkaProducerConfig
+}
+
+var _ quark.Publisher = &transactionalPublisher{}
+
+func (p *transactionalPublisher) Publish(ctx context.Context, messages ...*quark.Message) error {
+	for _, msg := range messages {
+		if msg == nil {
+			return quark.ErrEmptyMessage
+		}
+		select {
+		case p.producer.Input() <- MarshalKafkaProducerMessage(msg, p.cfg):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTxnStub = errors.New("txn stub error")
+
+// txnProducerStub records the messages of committed and aborted transactions, messages enqueued into its Input
+// belong to the current transaction
+type txnProducerStub struct {
+	sarama.AsyncProducer
+	input     chan *sarama.ProducerMessage
+	successes chan *sarama.ProducerMessage
+	errors    chan *sarama.ProducerError
+	commitErr error
+	status    sarama.ProducerTxnStatusFlag
+	committed []string
+	aborted   []string
+	offsets   []string
+}
+
+func newTxnProducerStub() *txnProducerStub {
+	return &txnProducerStub{
+		input:     make(chan *sarama.ProducerMessage, 10),
+		successes: make(chan *sarama.ProducerMessage),
+		errors:    make(chan *sarama.ProducerError),
+		status:    sarama.ProducerTxnFlagReady,
+	}
+}
+
+func (p *txnProducerStub) Input() chan<- *sarama.ProducerMessage { return p.input }
+
+func (p *txnProducerStub) Successes() <-chan *sarama.ProducerMessage { return p.successes }
+
+func (p *txnProducerStub) Errors() <-chan *sarama.ProducerError { return p.errors }
+
+func (p *txnProducerStub) AsyncClose() {
+	close(p.successes)
+	close(p.errors)
+}
+
+func (p *txnProducerStub) TxnStatus() sarama.ProducerTxnStatusFlag { return p.status }
+
+func (p *txnProducerStub) BeginTxn() error {
+	p.status = sarama.ProducerTxnFlagInTransaction
+	return nil
+}
+
+func (p *txnProducerStub) AddMessageToTxn(msg *sarama.ConsumerMessage, groupId string, metadata *string) error {
+	p.offsets = append(p.offsets, groupId+"/"+msg.Topic+"/"+strconv.Itoa(int(msg.Offset+1))+"/"+*metadata)
+	return nil
+}
+
+func (p *txnProducerStub) CommitTxn() error {
+	if p.commitErr != nil {
+		p.status = sarama.ProducerTxnFlagAbortableError
+		return p.commitErr
+	}
+	p.committed = append(p.committed, p.drain()...)
+	p.status = sarama.ProducerTxnFlagReady
+	return nil
+}
+
+func (p *txnProducerStub) AbortTxn() error {
+	p.aborted = append(p.aborted, p.drain()...)
+	p.status = sarama.ProducerTxnFlagReady
+	return nil
+}
+
+func (p *txnProducerStub) drain() []string {
+	topics := make([]string, 0)
+	for {
+		select {
+		case msg := <-p.input:
+			topics = append(topics, msg.Topic)
+		default:
+			return topics
+		}
+	}
+}
+
+type groupSessionStub struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	marked int
+}
+
+func (s *groupSessionStub) Context() context.Context { return s.ctx }
+
+func (s *groupSessionStub) MemberID() string { return "member-0" }
+
+func (s *groupSessionStub) GenerationID() int32 { return 7 }
+
+func (s *groupSessionStub) MarkMessage(_ *sarama.ConsumerMessage, _ string) { s.marked++ }
+
+type groupClaimStub struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *groupClaimStub) Topic() string { return "ledger.in" }
+
+func (c *groupClaimStub) Partition() int32 { return 3 }
+
+func (c *groupClaimStub) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+func newTransactionalTestingConsumer(t *testing.T, ack func() bool) (*transactionalKafkaConsumer, *txnProducerStub) {
+	c := quark.NewConsumer("ledger.in").Group("ledger").HandleFunc(func(w quark.EventWriter, e *quark.Event) bool {
+		_, err := w.Write(e.Context, []byte("posted"), "ledger.out")
+		assert.Nil(t, err)
+		return ack()
+	})
+	w := &kafkaWorker{
+		parent: &quark.Supervisor{Broker: quark.NewBroker(quark.WithRetryBackoff(time.Millisecond)), Consumer: c},
+		cfg:    KafkaConfiguration{Consumer: KafkaConsumerConfig{Transaction: KafkaTransactionConfig{Enabled: true}}},
+	}
+	stub := newTxnProducerStub()
+	handler := newTransactionalKafkaConsumer(w, nil)
+	handler.newProducer = func(config *sarama.Config) (sarama.AsyncProducer, error) {
+		assert.Equal(t, "ledger-ledger.in-3", config.Producer.Transaction.ID)
+		return stub, nil
+	}
+	return handler, stub
+}
+
+func TestTransactionalKafkaConsumer_ConsumeClaim(t *testing.T) {
+	handler, stub := newTransactionalTestingConsumer(t, func() bool { return true })
+	session := &groupSessionStub{ctx: context.Background()}
+	claim := &groupClaimStub{messages: make(chan *sarama.ConsumerMessage, 2)}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "ledger.in", Partition: 3, Offset: 10}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "ledger.in", Partition: 3, Offset: 11}
+	close(claim.messages)
+
+	assert.Nil(t, handler.ConsumeClaim(session, claim))
+	assert.Equal(t, []string{"ledger.out", "ledger.out"}, stub.committed)
+	assert.Empty(t, stub.aborted)
+	assert.Equal(t, []string{"ledger/ledger.in/11/generation=7", "ledger/ledger.in/12/generation=7"}, stub.offsets)
+	assert.Equal(t, 0, session.marked) // offsets are committed by the transaction
+}
+
+func TestTransactionalKafkaConsumer_ConsumeClaimNotAcknowledged(t *testing.T) {
+	handler, stub := newTransactionalTestingConsumer(t, func() bool { return false })
+	done := make(chan struct{})
+	close(done) // exits of closed job runs are not notified to the (unscheduled) Supervisor
+	handler.done = done
+	session := &groupSessionStub{ctx: context.Background()}
+	claim := &groupClaimStub{messages: make(chan *sarama.ConsumerMessage, 2)}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "ledger.in", Partition: 3, Offset: 10}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "ledger.in", Partition: 3, Offset: 11}
+	close(claim.messages)
+
+	err := handler.ConsumeClaim(session, claim)
+	assert.True(t, errors.Is(err, errTxnNotAcknowledged))
+	assert.Equal(t, []string{"ledger.out"}, stub.aborted)
+	assert.Empty(t, stub.committed)
+	assert.Empty(t, stub.offsets) // following messages never commit an offset past the not acknowledged one
+	assert.Len(t, claim.messages, 1)
+}
+
+var transactionalConsumeTestingSuite = []struct {
+	description  string
+	ack          bool
+	sessionDone  bool
+	commitErr    error
+	expErr       error
+	expCommitted int
+	expAborted   int
+}{
+	{"Commit acknowledged message", true, false, nil, nil, 1, 0},
+	{"Abort not acknowledged message", false, false, nil, errTxnNotAcknowledged, 0, 1},
+	{"Abort not acknowledged message of ended generation", false, true, nil, nil, 0, 1},
+	{"Abort message of ended generation", true, true, nil, nil, 0, 1},
+	{"Abort failed commit", true, false, errTxnStub, errTxnStub, 0, 1},
+}
+
+func TestTransactionalKafkaConsumer_ConsumeMessage(t *testing.T) {
+	for _, tt := range transactionalConsumeTestingSuite {
+		t.Run(tt.description, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			handler, stub := newTransactionalTestingConsumer(t, func() bool {
+				if tt.sessionDone {
+					cancel() // rebalance while handling the message
+				}
+				return tt.ack
+			})
+			session := &groupSessionStub{ctx: ctx}
+			producer, err := handler.newProducer(newTransactionalProducerConfig(handler.worker.cfg,
+				"ledger-ledger.in-3"))
+			require.Nil(t, err)
+			stub.commitErr = tt.commitErr
+
+			err = handler.consumeMessage(session, producer, &sarama.ConsumerMessage{Topic: "ledger.in",
+				Partition: 3, Offset: 10})
+			if tt.expErr == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.expErr))
+			}
+			assert.Len(t, stub.committed, tt.expCommitted)
+			assert.Len(t, stub.aborted, tt.expAborted)
+		})
+	}
+}
+
+func TestNewTransactionalProducerConfig(t *testing.T) {
+	base := sarama.NewConfig()
+	base.ClientID = "ledger"
+	config := newTransactionalProducerConfig(KafkaConfiguration{Config: base}, "ledger-ledger.in-0")
+	assert.Equal(t, "ledger", config.ClientID)
+	assert.Equal(t, "ledger-ledger.in-0", config.Producer.Transaction.ID)
+	assert.True(t, config.Producer.Idempotent)
+	assert.Equal(t, sarama.WaitForAll, config.Producer.RequiredAcks)
+	assert.Equal(t, 1, config.Net.MaxOpenRequests)
+	assert.True(t, config.Version.IsAtLeast(sarama.V0_11_0_0))
+	assert.Nil(t, config.Validate())
+	assert.Empty(t, base.Producer.Transaction.ID)
+}
+
+func TestNewTransactionalConsumerConfig(t *testing.T) {
+	base := sarama.NewConfig()
+	base.ClientID = "ledger"
+	config := newTransactionalConsumerConfig(KafkaConfiguration{Config: base})
+	assert.Equal(t, "ledger", config.ClientID)
+	assert.Equal(t, sarama.ReadCommitted, config.Consumer.IsolationLevel)
+	assert.True(t, config.Version.IsAtLeast(sarama.V0_11_0_0))
+	assert.Nil(t, config.Validate())
+	assert.Equal(t, sarama.ReadUncommitted, base.Consumer.IsolationLevel)
+}
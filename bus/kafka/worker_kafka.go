@@ -3,6 +3,8 @@ package kafka
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Shopify/sarama"
@@ -11,13 +13,20 @@ import (
 )
 
 type kafkaWorker struct {
+	quark.WorkerTracker
+
 	id     int
 	parent *quark.Supervisor
 	cfg    KafkaConfiguration
 
+	client      sarama.Client
 	group       sarama.ConsumerGroup
 	consumer    sarama.Consumer
 	partitioner sarama.PartitionConsumer
+	paused      int32
+
+	runMu sync.Mutex
+	done  chan struct{} // closed once the current job run is closed
 }
 
 func (k *kafkaWorker) SetID(i int) {
@@ -29,7 +38,9 @@ func (k *kafkaWorker) Parent() *quark.Supervisor {
 }
 
 func (k *kafkaWorker) StartJob(ctx context.Context) error {
+	k.Reset()
 	if err := k.ensureGroup(); err != nil {
+		k.SetError(err)
 		return err
 	} else if len(k.parent.Consumer.GetTopics()) > 1 || k.parent.Consumer.GetGroup() != "" {
 		return k.startConsumerGroup(ctx)
@@ -47,15 +58,29 @@ func (k *kafkaWorker) ensureGroup() error {
 }
 
 func (k *kafkaWorker) startConsumerGroup(ctx context.Context) error {
-	group, err := sarama.NewConsumerGroup(k.parent.GetCluster(), k.parent.GetGroup(), k.cfg.Config)
+	config := k.cfg.Config
+	if k.cfg.Consumer.Transaction.Enabled {
+		config = newTransactionalConsumerConfig(k.cfg)
+	}
+	client, err := sarama.NewClient(k.parent.GetCluster(), config)
+	if err != nil {
+		k.SetError(err)
+		return err
+	}
+	k.client = client
+	group, err := sarama.NewConsumerGroupFromClient(k.parent.GetGroup(), client)
 	if err != nil {
+		k.SetError(err)
 		return err
 	}
 	k.group = group
+	k.SetState(quark.WorkerRunning)
+	done := k.startRun()
 
-	if k.cfg.Config.Consumer.Return.Errors && k.parent.Broker.ErrorHandler != nil {
+	if config.Consumer.Return.Errors && k.parent.Broker.ErrorHandler != nil {
 		go func() {
-			for e := range k.group.Errors() {
+			for e := range group.Errors() {
+				k.SetError(e)
 				if k.parent.Broker.ErrorHandler != nil {
 					k.parent.Broker.ErrorHandler(ctx, e)
 				}
@@ -67,22 +92,24 @@ func (k *kafkaWorker) startConsumerGroup(ctx context.Context) error {
 	go func() {
 		retries := 0
 		for {
-			err = k.group.Consume(ctx, k.parent.Consumer.GetTopics(), k.setDefaultConsumerGroupHandler())
+			err := group.Consume(ctx, k.parent.Consumer.GetTopics(), k.setDefaultConsumerGroupHandler(done))
 			if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				k.SetState(quark.WorkerClosed)
 				return
 			} else if errors.Is(err, sarama.ErrOutOfBrokers) {
+				k.SetError(err)
 				if retries <= k.parent.Broker.GetConnRetries() {
+					k.SetState(quark.WorkerReconnecting)
 					retries++
 					time.Sleep(k.parent.Broker.GetConnRetryBackoff() * time.Duration(retries))
 					continue
 				}
 			}
 			if err != nil {
-				if k.parent.Broker.ErrorHandler != nil {
-					go k.parent.Broker.ErrorHandler(ctx, err)
-				}
+				k.notifyExit(done, err) // reports the error and lets the supervisor restart the worker
 				return
 			}
+			k.SetState(quark.WorkerRunning)
 			retries = 0 // restart count since problem was fixed
 		}
 	}()
@@ -91,22 +118,41 @@ func (k *kafkaWorker) startConsumerGroup(ctx context.Context) error {
 }
 
 func (k *kafkaWorker) startConsumer(ctx context.Context) error {
-	consumer, err := sarama.NewConsumer(k.parent.GetCluster(), k.cfg.Config)
+	client, err := sarama.NewClient(k.parent.GetCluster(), k.cfg.Config)
 	if err != nil {
+		k.SetError(err)
+		return err
+	}
+	k.client = client
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		k.SetError(err)
 		return err
 	}
 	k.consumer = consumer
 
-	cPartition, err := k.consumer.ConsumePartition(k.parent.Consumer.GetTopics()[0], k.cfg.Consumer.Topic.Partition,
-		k.cfg.Consumer.Topic.Offset)
+	topic, partition := k.parent.Consumer.GetTopics()[0], k.cfg.Consumer.Topic.Partition
+	offset := k.cfg.Consumer.Topic.Offset
+	if startFrom := k.parent.Consumer.GetStartFrom(); !startFrom.IsDefault() {
+		if offset, err = ResolveStartOffset(client, topic, partition, startFrom); err != nil {
+			k.SetError(err)
+			return err
+		}
+	}
+	cPartition, err := k.consumer.ConsumePartition(topic, partition, offset)
 	if err != nil {
+		k.SetError(err)
 		return err
 	}
 	k.partitioner = cPartition
+	k.SetPartitions(map[string][]int32{topic: {partition}})
+	k.SetState(quark.WorkerRunning)
+	done := k.startRun()
 
 	if k.cfg.Config.Consumer.Return.Errors && k.parent.Broker.ErrorHandler != nil {
 		go func() {
-			for e := range k.partitioner.Errors() {
+			for e := range cPartition.Errors() {
+				k.SetError(e)
 				if k.parent.Broker.ErrorHandler != nil {
 					k.parent.Broker.ErrorHandler(ctx, e)
 				}
@@ -116,14 +162,75 @@ func (k *kafkaWorker) startConsumer(ctx context.Context) error {
 
 	// Blocking I/O
 	go func() {
-		k.setDefaultConsumerPartitionHandler().Consume(ctx, k.partitioner, k.parent.Consumer,
+		k.setDefaultConsumerPartitionHandler().Consume(ctx, cPartition, k.parent.Consumer,
 			k.parent.GetEventWriter())
+		k.notifyExit(done, quark.ErrWorkerExited)
 	}()
 
 	return nil
 }
 
+// Pause stops fetching records from every claimed partition, the consumer group membership is kept alive
+func (k *kafkaWorker) Pause() error {
+	atomic.StoreInt32(&k.paused, 1)
+	if k.group != nil {
+		k.group.PauseAll()
+	}
+	if k.partitioner != nil {
+		k.partitioner.Pause()
+	}
+	return nil
+}
+
+// Resume starts fetching records again from every claimed partition
+func (k *kafkaWorker) Resume() error {
+	atomic.StoreInt32(&k.paused, 0)
+	if k.group != nil {
+		k.group.ResumeAll()
+	}
+	if k.partitioner != nil {
+		k.partitioner.Resume()
+	}
+	return nil
+}
+
+func (k *kafkaWorker) isPaused() bool {
+	return atomic.LoadInt32(&k.paused) == 1
+}
+
+// startRun starts a new job run, its done channel is captured by the run goroutines so runs ended by Close are not
+// mistaken for crashes of a following run
+func (k *kafkaWorker) startRun() <-chan struct{} {
+	k.runMu.Lock()
+	defer k.runMu.Unlock()
+	k.done = make(chan struct{})
+	return k.done
+}
+
+func (k *kafkaWorker) closeRun() {
+	k.runMu.Lock()
+	defer k.runMu.Unlock()
+	if k.done != nil {
+		close(k.done)
+		k.done = nil
+	}
+}
+
+// notifyExit notifies the Supervisor the given job run exited unexpectedly, runs ended by Close are ignored
+func (k *kafkaWorker) notifyExit(done <-chan struct{}, err error) {
+	select {
+	case <-done:
+		return
+	default:
+	}
+	k.SetError(err)
+	k.SetState(quark.WorkerClosed)
+	k.parent.NotifyExit(k, err)
+}
+
 func (k *kafkaWorker) Close() error {
+	k.closeRun()
+	k.SetState(quark.WorkerClosed)
 	errs := new(multierror.Error)
 	if k.group != nil {
 		if err := k.group.Close(); err != nil {
@@ -140,13 +247,19 @@ func (k *kafkaWorker) Close() error {
 			errs = multierror.Append(errs, err)
 		}
 	}
-
+	if k.client != nil && !k.client.Closed() {
+		if err := k.client.Close(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
 	return errs.ErrorOrNil()
 }
 
-func (k *kafkaWorker) setDefaultConsumerGroupHandler() sarama.ConsumerGroupHandler {
+func (k *kafkaWorker) setDefaultConsumerGroupHandler(done <-chan struct{}) sarama.ConsumerGroupHandler {
 	if k.cfg.Consumer.GroupHandler != nil {
 		return k.cfg.Consumer.GroupHandler
+	} else if k.cfg.Consumer.Transaction.Enabled {
+		return newTransactionalKafkaConsumer(k, done)
 	}
 
 	return &defaultKafkaConsumer{
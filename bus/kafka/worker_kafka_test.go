@@ -0,0 +1,36 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKafkaWorker_Pause(t *testing.T) {
+	t.Run("Kafka worker pause and resume", func(t *testing.T) {
+		var w quark.Worker = new(kafkaWorker)
+		p, ok := w.(quark.PausableWorker)
+		assert.True(t, ok)
+		assert.Nil(t, p.Pause())
+		assert.True(t, w.(*kafkaWorker).isPaused())
+		assert.Nil(t, p.Resume())
+		assert.False(t, w.(*kafkaWorker).isPaused())
+	})
+}
+
+func TestKafkaWorker_NotifyExit(t *testing.T) {
+	t.Run("Kafka worker ignore exits of closed job runs", func(t *testing.T) {
+		w := new(kafkaWorker)
+		w.Reset()
+		w.SetState(quark.WorkerRunning)
+		stale := w.startRun()
+		assert.Nil(t, w.Close())
+		w.SetState(quark.WorkerRunning)
+		_ = w.startRun() // restarted by the Supervisor
+		w.notifyExit(stale, errors.New("kafka: consumer group closed"))
+		assert.Equal(t, quark.WorkerRunning, w.Status().State)
+		assert.Empty(t, w.Status().LastError)
+	})
+}
@@ -0,0 +1,27 @@
+package memory
+
+import "github.com/neutrinocorp/quark"
+
+// NewBroker allocates and returns an in-memory Broker using the given Bus
+func NewBroker(bus *Bus, opts ...quark.Option) *quark.Broker {
+	b := quark.NewBroker(opts...)
+	if len(b.Cluster) == 0 {
+		b.Cluster = []string{"memory"}
+	}
+	if b.Publisher == nil {
+		b.Publisher = bus
+	}
+	if b.WorkerFactory == nil {
+		b.WorkerFactory = newWorkerFactory(bus)
+	}
+	return b
+}
+
+func newWorkerFactory(bus *Bus) quark.WorkerFactory {
+	return func(parent *quark.Supervisor) quark.Worker {
+		return &worker{
+			bus:    bus,
+			parent: parent,
+		}
+	}
+}
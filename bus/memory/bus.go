@@ -0,0 +1,166 @@
+// Package memory In-memory Quark provider.
+//
+// Intended for testing and local development, messages are neither persisted nor acknowledged (at-most-once).
+// Consumers sharing the same group receive messages in a round-robin fashion while every group receives its own copy.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/neutrinocorp/quark"
+)
+
+const (
+	// HeaderOffset Message position inside its topic
+	HeaderOffset = "quark-memory-offset"
+)
+
+var defaultBufferSize = 256
+
+// Bus in-memory message bus, works as both the message broker and the quark.Publisher
+type Bus struct {
+	topics map[string]*topic
+	mu     sync.RWMutex
+}
+
+type topic struct {
+	offset int64
+	groups map[string]*group
+}
+
+type group struct {
+	next          int
+	subscriptions []*subscription
+}
+
+type subscription struct {
+	topic string
+	group string
+	ch    chan *delivery
+	done  chan struct{}
+}
+
+type delivery struct {
+	offset int64
+	msg    *quark.Message
+}
+
+var _ quark.Publisher = &Bus{}
+
+// NewBus allocates and returns a Bus
+func NewBus() *Bus {
+	return &Bus{
+		topics: map[string]*topic{},
+		mu:     sync.RWMutex{},
+	}
+}
+
+// Publish delivers a copy of the given messages to every group subscribed to the Message Type (topic).
+//
+// Blocks if a subscription buffer is full until it is drained, the subscription is closed or ctx is done
+func (b *Bus) Publish(ctx context.Context, msgs ...*quark.Message) error {
+	for _, msg := range msgs {
+		if msg == nil {
+			return quark.ErrEmptyMessage
+		}
+		for _, sub := range b.route(msg.Type) {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-sub.done:
+			case sub.ch <- &delivery{offset: b.nextOffset(msg.Type), msg: copyMessage(msg)}:
+			}
+		}
+	}
+	return nil
+}
+
+// route picks a subscription per group using round-robin
+func (b *Bus) route(name string) []*subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t, ok := b.topics[name]
+	if !ok {
+		return nil
+	}
+	subs := make([]*subscription, 0, len(t.groups))
+	for _, g := range t.groups {
+		if len(g.subscriptions) == 0 {
+			continue
+		}
+		subs = append(subs, g.subscriptions[g.next%len(g.subscriptions)])
+		g.next++
+	}
+	return subs
+}
+
+func (b *Bus) nextOffset(name string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := b.getTopicLocked(name)
+	offset := t.offset
+	t.offset++
+	return offset
+}
+
+func (b *Bus) getTopicLocked(name string) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{groups: map[string]*group{}}
+		b.topics[name] = t
+	}
+	return t
+}
+
+func (b *Bus) subscribe(topicName, groupName string) *subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := b.getTopicLocked(topicName)
+	g, ok := t.groups[groupName]
+	if !ok {
+		g = new(group)
+		t.groups[groupName] = g
+	}
+	sub := &subscription{
+		topic: topicName,
+		group: groupName,
+		ch:    make(chan *delivery, defaultBufferSize),
+		done:  make(chan struct{}),
+	}
+	g.subscriptions = append(g.subscriptions, sub)
+	return sub
+}
+
+func (b *Bus) unsubscribe(sub *subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t, ok := b.topics[sub.topic]
+	if !ok {
+		return
+	}
+	g, ok := t.groups[sub.group]
+	if !ok {
+		return
+	}
+	for i, s := range g.subscriptions {
+		if s == sub {
+			g.subscriptions = append(g.subscriptions[:i], g.subscriptions[i+1:]...)
+			close(sub.done)
+			break
+		}
+	}
+	if len(g.subscriptions) == 0 {
+		delete(t.groups, sub.group)
+	}
+}
+
+func copyMessage(msg *quark.Message) *quark.Message {
+	m := *msg
+	m.Data = append([]byte(nil), msg.Data...)
+	m.Metadata.ExternalData = make(map[string]string, len(msg.Metadata.ExternalData))
+	for k, v := range msg.Metadata.ExternalData {
+		m.Metadata.ExternalData[k] = v
+	}
+	return &m
+}
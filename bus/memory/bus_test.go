@@ -0,0 +1,268 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_Publish(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Bus deliver a copy per group", func(t *testing.T) {
+		b := NewBus()
+		subA := b.subscribe("chat.0", "a")
+		subB := b.subscribe("chat.0", "b")
+		assert.Nil(t, b.Publish(ctx, quark.NewMessage("1", "chat.0", []byte("hello"))))
+		assert.Equal(t, "1", (<-subA.ch).msg.Id)
+		assert.Equal(t, "1", (<-subB.ch).msg.Id)
+	})
+
+	t.Run("Bus round-robin inside a group", func(t *testing.T) {
+		b := NewBus()
+		sub0 := b.subscribe("chat.0", "a")
+		sub1 := b.subscribe("chat.0", "a")
+		assert.Nil(t, b.Publish(ctx, quark.NewMessage("1", "chat.0", nil),
+			quark.NewMessage("2", "chat.0", nil)))
+		assert.Equal(t, 1, len(sub0.ch))
+		assert.Equal(t, 1, len(sub1.ch))
+	})
+
+	t.Run("Bus ignore topics without subscriptions", func(t *testing.T) {
+		b := NewBus()
+		assert.Nil(t, b.Publish(ctx, quark.NewMessage("1", "chat.0", nil)))
+		assert.Equal(t, quark.ErrEmptyMessage, b.Publish(ctx, nil))
+	})
+
+	t.Run("Bus stop delivering to closed subscriptions", func(t *testing.T) {
+		b := NewBus()
+		sub := b.subscribe("chat.0", "a")
+		b.unsubscribe(sub)
+		assert.Nil(t, b.Publish(ctx, quark.NewMessage("1", "chat.0", nil)))
+		assert.Equal(t, 0, len(sub.ch))
+	})
+}
+
+func isSubscribed(b *Bus, name string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	t, ok := b.topics[name]
+	return ok && len(t.groups) > 0
+}
+
+func TestBroker_Request(t *testing.T) {
+	t.Run("Request and reply over the in-memory bus", func(t *testing.T) {
+		bus := NewBus()
+		b := NewBroker(bus, quark.WithReplyTopic("replies.0"), quark.WithPoolSize(1),
+			quark.WithRetryBackoff(time.Millisecond))
+		b.Topic("math.double").HandleFunc(func(w quark.EventWriter, e *quark.Event) bool {
+			return quark.Reply(e.Context, w, e, append(e.Body.Data, e.Body.Data...)) == nil
+		})
+		go func() {
+			_ = b.ListenAndServe()
+		}()
+		defer b.Shutdown(context.Background())
+		assert.Eventually(t, func() bool {
+			return isSubscribed(bus, "replies.0") && isSubscribed(bus, "math.double")
+		}, time.Second, time.Millisecond*10)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+		defer cancel()
+		req := quark.NewMessage("1", "math.double", []byte("ab"))
+		reply, err := quark.Request(ctx, quark.NewEventWriter(b), req)
+		assert.Nil(t, err)
+		assert.Equal(t, "abab", string(reply.Body.Data))
+		assert.Equal(t, req.Id, reply.Body.Metadata.CorrelationId)
+	})
+
+	t.Run("Request timeout", func(t *testing.T) {
+		b := NewBroker(NewBus(), quark.WithReplyTopic("replies.0"), quark.WithRetryBackoff(time.Millisecond))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		defer cancel()
+		_, err := quark.Request(ctx, quark.NewEventWriter(b), quark.NewMessage("1", "math.double", nil))
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("Request without reply topic", func(t *testing.T) {
+		b := NewBroker(NewBus())
+		_, err := quark.Request(context.Background(), quark.NewEventWriter(b), quark.NewMessage("1", "math.double", nil))
+		assert.Equal(t, quark.ErrReplyTopicNotDefined, err)
+	})
+}
+
+func TestBroker_AddConsumer(t *testing.T) {
+	t.Run("Add and remove consumers from a running broker", func(t *testing.T) {
+		bus := NewBus()
+		b := NewBroker(bus, quark.WithPoolSize(1), quark.WithRetryBackoff(time.Millisecond))
+		b.Topic("chat.0").HandleFunc(func(w quark.EventWriter, e *quark.Event) bool {
+			return true
+		})
+		go func() {
+			_ = b.ListenAndServe()
+		}()
+		defer b.Shutdown(context.Background())
+		assert.Eventually(t, func() bool {
+			return isSubscribed(bus, "chat.0")
+		}, time.Second, time.Millisecond*10)
+
+		received := make(chan string, 1)
+		c := quark.NewConsumer("chat.1", "chat.2").Group("audit").HandleFunc(
+			func(w quark.EventWriter, e *quark.Event) bool {
+				received <- e.Body.Id
+				return true
+			})
+		assert.Nil(t, b.AddConsumer(c))
+		assert.Nil(t, b.AddConsumer(c))
+		assert.Equal(t, 2, b.ActiveSupervisors())
+		assert.Eventually(t, func() bool {
+			return isSubscribed(bus, "chat.1") && isSubscribed(bus, "chat.2")
+		}, time.Second, time.Millisecond*10)
+
+		assert.Nil(t, bus.Publish(context.Background(), quark.NewMessage("1", "chat.2", nil)))
+		select {
+		case id := <-received:
+			assert.Equal(t, "1", id)
+		case <-time.After(time.Second):
+			t.Fatal("message was not delivered to the added consumer")
+		}
+
+		assert.Nil(t, b.RemoveConsumer(c))
+		assert.Equal(t, 1, b.ActiveSupervisors())
+		assert.False(t, b.EventMux.Contains("chat.1"))
+		assert.Eventually(t, func() bool {
+			return !isSubscribed(bus, "chat.1") && !isSubscribed(bus, "chat.2")
+		}, time.Second, time.Millisecond*10)
+	})
+
+	t.Run("Add consumer to a closed broker", func(t *testing.T) {
+		b := NewBroker(NewBus())
+		assert.Nil(t, b.Shutdown(context.Background()))
+		assert.Equal(t, quark.ErrBrokerClosed, b.AddConsumer(quark.NewConsumer("chat.0")))
+		assert.Equal(t, quark.ErrEmptyConsumer, b.AddConsumer(nil))
+	})
+}
+
+func TestBroker_Pause(t *testing.T) {
+	t.Run("Pause and resume a topic", func(t *testing.T) {
+		bus := NewBus()
+		b := NewBroker(bus, quark.WithPoolSize(1), quark.WithRetryBackoff(time.Millisecond))
+		received := make(chan string, 2)
+		b.Topic("chat.0").HandleFunc(func(w quark.EventWriter, e *quark.Event) bool {
+			received <- e.Body.Id
+			return true
+		})
+		go func() {
+			_ = b.ListenAndServe()
+		}()
+		defer b.Shutdown(context.Background())
+		assert.Eventually(t, func() bool {
+			return isSubscribed(bus, "chat.0")
+		}, time.Second, time.Millisecond*10)
+
+		assert.Nil(t, b.Pause("chat.0"))
+		assert.Nil(t, bus.Publish(context.Background(), quark.NewMessage("1", "chat.0", nil)))
+		select {
+		case <-received:
+			t.Fatal("message was delivered to a paused consumer")
+		case <-time.After(time.Millisecond * 50):
+		}
+
+		assert.Nil(t, b.Resume("chat.0"))
+		select {
+		case id := <-received:
+			assert.Equal(t, "1", id)
+		case <-time.After(time.Second):
+			t.Fatal("message was not delivered after resume")
+		}
+		assert.Equal(t, quark.ErrTopicNotFound, b.Pause("chat.1"))
+	})
+}
+
+func TestBroker_Status(t *testing.T) {
+	t.Run("Broker status snapshot", func(t *testing.T) {
+		bus := NewBus()
+		b := NewBroker(bus, quark.WithPoolSize(2), quark.WithRetryBackoff(time.Millisecond))
+		received := make(chan struct{}, 1)
+		b.Topic("chat.0").Group("audit").HandleFunc(func(w quark.EventWriter, e *quark.Event) bool {
+			received <- struct{}{}
+			return true
+		})
+		assert.False(t, b.Status().Serving)
+		go func() {
+			_ = b.ListenAndServe()
+		}()
+		assert.Eventually(t, func() bool {
+			return isSubscribed(bus, "chat.0")
+		}, time.Second, time.Millisecond*10)
+		assert.Nil(t, bus.Publish(context.Background(), quark.NewMessage("1", "chat.0", nil)))
+		<-received
+
+		st := b.Status()
+		assert.True(t, st.Serving)
+		assert.Equal(t, 1, len(st.Supervisors))
+		assert.Equal(t, []string{"chat.0"}, st.Supervisors[0].Topics)
+		assert.Equal(t, "audit", st.Supervisors[0].Group)
+		assert.Equal(t, 2, st.Supervisors[0].PoolSize)
+		assert.Equal(t, 2, len(st.Supervisors[0].Workers))
+		assert.Equal(t, 2, b.ActiveWorkers())
+		processed := false
+		for _, w := range st.Supervisors[0].Workers {
+			assert.Equal(t, quark.WorkerRunning, w.State)
+			_, ok := w.Offsets["chat.0"][0]
+			processed = processed || ok
+		}
+		assert.True(t, processed)
+
+		assert.Nil(t, b.Shutdown(context.Background()))
+		st = b.Status()
+		assert.True(t, st.ShuttingDown)
+		assert.Equal(t, 0, len(st.Supervisors))
+		assert.Equal(t, 0, b.ActiveWorkers())
+	})
+}
+
+var brokerStartupTestingSuite = []struct {
+	mode                quark.StartupMode
+	expectedErr         error
+	expectedSupervisors int
+}{
+	{quark.StartupAtomic, quark.ErrNotEnoughHandlers, 0},
+	{quark.StartupBestEffort, quark.ErrBrokerClosed, 2},
+}
+
+func TestBroker_StartupMode(t *testing.T) {
+	for _, tt := range brokerStartupTestingSuite {
+		t.Run("Broker startup with a failing consumer", func(t *testing.T) {
+			bus := NewBus()
+			reported := make(chan error, 1)
+			var b *quark.Broker
+			b = NewBroker(bus, quark.WithStartupMode(tt.mode), quark.WithPoolSize(1),
+				quark.WithErrorHandler(func(_ context.Context, err error) {
+					_ = b.Status() // handlers querying the Broker must not deadlock
+					reported <- err
+				}))
+			b.Topic("chat.0").HandleFunc(func(w quark.EventWriter, e *quark.Event) bool {
+				return true
+			})
+			b.Topic("chat.1") // no handler
+			errC := make(chan error, 1)
+			go func() {
+				errC <- b.ListenAndServe()
+			}()
+			if tt.mode == quark.StartupBestEffort {
+				assert.Equal(t, quark.ErrNotEnoughHandlers, <-reported)
+				assert.Eventually(t, func() bool {
+					return b.Status().Serving
+				}, time.Second, time.Millisecond*10)
+				assert.Equal(t, tt.expectedSupervisors, b.ActiveSupervisors())
+				assert.Nil(t, b.Shutdown(context.Background()))
+			}
+			assert.True(t, errors.Is(<-errC, tt.expectedErr))
+			assert.Equal(t, 0, b.ActiveSupervisors())
+			assert.False(t, isSubscribed(bus, "chat.0"))
+		})
+	}
+}
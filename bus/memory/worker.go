@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/neutrinocorp/quark"
+)
+
+type worker struct {
+	quark.WorkerTracker
+
+	id     int
+	bus    *Bus
+	parent *quark.Supervisor
+
+	subscriptions []*subscription
+	wg            sync.WaitGroup
+
+	mu      sync.Mutex
+	resumed chan struct{} // closed while the worker is not paused
+}
+
+func (w *worker) SetID(i int) {
+	w.id = i
+}
+
+func (w *worker) Parent() *quark.Supervisor {
+	return w.parent
+}
+
+func (w *worker) StartJob(ctx context.Context) error {
+	w.Reset()
+	defer w.SetState(quark.WorkerRunning)
+	for _, t := range w.parent.Consumer.GetTopics() {
+		sub := w.bus.subscribe(t, w.parent.GetGroup())
+		w.subscriptions = append(w.subscriptions, sub)
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.consume(ctx, sub)
+		}()
+	}
+	return nil
+}
+
+func (w *worker) consume(ctx context.Context, sub *subscription) {
+	for {
+		select {
+		case <-sub.done:
+			return
+		case d := <-sub.ch:
+			select {
+			case <-sub.done:
+				return
+			case <-w.getResumedChan(): // hold the delivery while paused
+			}
+			w.serve(ctx, sub, d)
+		}
+	}
+}
+
+func (w *worker) serve(ctx context.Context, sub *subscription, d *delivery) {
+	h := newHeader(d.msg)
+	h.Set(HeaderOffset, strconv.FormatInt(d.offset, 10))
+	h.Set(quark.HeaderConsumerGroup, sub.group)
+	e := &quark.Event{
+		Context:    ctx,
+		Topic:      sub.topic,
+		Header:     h,
+		Body:       d.msg,
+		RawValue:   d.msg.Data,
+		RawSession: sub,
+	}
+	if handler := w.parent.Consumer.GetHandle(); handler != nil {
+		evWriter := w.parent.GetEventWriter()
+		evWriter.ReplaceHeader(newQuarkHeaders(h))
+		w.parent.Dispatch(handler.ServeEvent, evWriter, e)
+	}
+	if handlerFunc := w.parent.Consumer.GetHandleFunc(); handlerFunc != nil {
+		evWriter := w.parent.GetEventWriter()
+		evWriter.ReplaceHeader(newQuarkHeaders(h))
+		w.parent.Dispatch(handlerFunc, evWriter, e)
+	}
+	w.SetOffset(sub.topic, 0, d.offset)
+}
+
+// Pause stops pulling deliveries, they are kept buffered in the subscriptions until the worker is resumed
+func (w *worker) Pause() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.resumed == nil {
+		w.resumed = make(chan struct{})
+	}
+	return nil
+}
+
+// Resume starts pulling buffered deliveries again
+func (w *worker) Resume() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.resumed != nil {
+		close(w.resumed)
+		w.resumed = nil
+	}
+	return nil
+}
+
+func (w *worker) getResumedChan() <-chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.resumed == nil {
+		return closedChan
+	}
+	return w.resumed
+}
+
+func (w *worker) Close() error {
+	for _, sub := range w.subscriptions {
+		w.bus.unsubscribe(sub)
+	}
+	w.wg.Wait()
+	w.subscriptions = nil
+	w.SetState(quark.WorkerClosed)
+	return w.Resume()
+}
+
+var closedChan = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// newHeader creates an Event Header from the given Message attributes
+func newHeader(msg *quark.Message) quark.Header {
+	h := quark.Header{}
+	h.Set(quark.HeaderMessageId, msg.Id)
+	h.Set(quark.HeaderMessageType, msg.Type)
+	h.Set(quark.HeaderMessageSpecVersion, msg.SpecVersion)
+	h.Set(quark.HeaderMessageSource, msg.Source)
+	h.Set(quark.HeaderMessageDataContentType, msg.ContentType)
+	h.Set(quark.HeaderMessageSubject, msg.Subject)
+	h.Set(quark.HeaderMessageCorrelationId, msg.Metadata.CorrelationId)
+	h.Set(quark.HeaderMessageHost, msg.Metadata.Host)
+	h.Set(quark.HeaderMessageRedeliveryCount, strconv.Itoa(msg.Metadata.RedeliveryCount))
+	for k, v := range msg.Metadata.ExternalData {
+		h.Set(k, v)
+	}
+	return h
+}
+
+func newQuarkHeaders(h quark.Header) quark.Header {
+	hEv := quark.Header{}
+	hEv.Set(quark.HeaderSpanContext, h.Get(quark.HeaderSpanContext))
+	hEv.Set(quark.HeaderMessageCorrelationId, h.Get(quark.HeaderMessageCorrelationId))
+	hEv.Set(quark.HeaderMessageRedeliveryCount, h.Get(quark.HeaderMessageRedeliveryCount))
+	return hEv
+}
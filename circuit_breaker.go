@@ -0,0 +1,276 @@
+package quark
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CircuitState is the current state of a CircuitBreakerPublisher
+type CircuitState int
+
+const (
+	// CircuitClosed messages are published using the underlying Publisher
+	CircuitClosed CircuitState = iota
+	// CircuitOpen the underlying Publisher is failing, messages are published using the fallback Publisher
+	CircuitOpen
+	// CircuitHalfOpen a limited number of probes are published using the underlying Publisher to verify it recovered
+	CircuitHalfOpen
+)
+
+var circuitStateNames = map[CircuitState]string{
+	CircuitClosed:   "closed",
+	CircuitOpen:     "open",
+	CircuitHalfOpen: "half_open",
+}
+
+func (s CircuitState) String() string {
+	if name, ok := circuitStateNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// MarshalText encodes the CircuitState using its name
+func (s CircuitState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+var (
+	defaultCircuitFailureThreshold = 5
+	defaultCircuitOpenTimeout      = time.Second * 30
+	defaultCircuitHalfOpenProbes   = 1
+)
+
+// CircuitBreakerConfig defines when a CircuitBreakerPublisher opens and closes its circuit, zero values fall back to
+// defaults
+type CircuitBreakerConfig struct {
+	// Name identifies the circuit breaker in the Broker status
+	Name string
+	// FailureThreshold consecutive publishing failures opening the circuit (default 5)
+	FailureThreshold int
+	// OpenTimeout time the circuit stays open before probing the underlying Publisher (default 30 seconds)
+	OpenTimeout time.Duration
+	// HalfOpenProbes successful probes required to close the circuit, it is also the maximum number of concurrent
+	// probes (default 1)
+	HalfOpenProbes int
+	// Fallback publishes messages rejected by the circuit or failed by the underlying Publisher (e.g. a local spool).
+	// ErrCircuitOpen is returned while the circuit is open if nil
+	Fallback Publisher
+	// OnStateChange is called every time the circuit changes its state, it must not call the circuit breaker
+	OnStateChange func(from, to CircuitState)
+}
+
+// CircuitBreakerStatus is a snapshot of a CircuitBreakerPublisher state
+type CircuitBreakerStatus struct {
+	Name  string       `json:"name"`
+	State CircuitState `json:"state"`
+	// ConsecutiveFailures publishing failures since the last success
+	ConsecutiveFailures int `json:"consecutive_failures"`
+	// OpenedAt last time the circuit was opened, zero if never opened
+	OpenedAt time.Time `json:"opened_at"`
+	// Rejected number of publish calls rejected by the open circuit since the breaker was created
+	Rejected int64 `json:"rejected"`
+	// Fallbacks number of publish calls handled by the fallback Publisher since the breaker was created
+	Fallbacks int64 `json:"fallbacks"`
+}
+
+// CircuitAware is a Publisher able to report how its circuit handles the next publish call (e.g.
+// CircuitBreakerPublisher), EventWriter(s) use it to skip the retry backoff while the circuit is open
+type CircuitAware interface {
+	// Rejects reports if the next publish call would fail fast with ErrCircuitOpen
+	Rejects() bool
+	// Diverts reports if the next publish call would be routed to the fallback Publisher
+	Diverts() bool
+}
+
+// CircuitBreakerPublisher is a Publisher decorator which stops calling a failing Publisher once it reaches the
+// failure threshold, publishing into a fallback Publisher instead. After the open timeout, probes are published to
+// verify the underlying Publisher recovered.
+//
+// Broker status reports every circuit breaker used as Broker or Consumer Publisher
+type CircuitBreakerPublisher struct {
+	publisher Publisher
+	cfg       CircuitBreakerConfig
+	now       func() time.Time
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	successes int
+	probes    int
+	openedAt  time.Time
+	rejected  int64
+	fallbacks int64
+}
+
+var (
+	_ Publisher    = &CircuitBreakerPublisher{}
+	_ CircuitAware = &CircuitBreakerPublisher{}
+)
+
+// NewCircuitBreakerPublisher allocates and returns a CircuitBreakerPublisher decorating the given Publisher
+func NewCircuitBreakerPublisher(p Publisher, cfg CircuitBreakerConfig) *CircuitBreakerPublisher {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaultCircuitFailureThreshold
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = defaultCircuitOpenTimeout
+	}
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = defaultCircuitHalfOpenProbes
+	}
+	return &CircuitBreakerPublisher{
+		publisher: p,
+		cfg:       cfg,
+		now:       time.Now,
+	}
+}
+
+// Publish pushes the given messages using the underlying Publisher if the circuit allows it, the fallback
+// Publisher is used otherwise or if the underlying Publisher fails.
+//
+// Returns ErrCircuitOpen if the circuit rejected the messages and no fallback was defined
+func (c *CircuitBreakerPublisher) Publish(ctx context.Context, msgs ...*Message) error {
+	if c.publisher == nil {
+		return ErrPublisherNotImplemented
+	}
+	probe, ok := c.allow()
+	if !ok {
+		return c.fallback(ctx, ErrCircuitOpen, msgs)
+	}
+	err := c.publisher.Publish(ctx, msgs...)
+	if err != nil && ctx.Err() != nil {
+		c.release(probe) // cancelled by the caller, the Publisher is not at fault
+		return err
+	}
+	c.record(probe, err)
+	if err != nil {
+		return c.fallback(ctx, err, msgs)
+	}
+	return nil
+}
+
+// State returns the current circuit state
+func (c *CircuitBreakerPublisher) State() CircuitState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.currentStateLocked()
+}
+
+// CircuitStatus returns a snapshot of the current circuit breaker state
+func (c *CircuitBreakerPublisher) CircuitStatus() CircuitBreakerStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CircuitBreakerStatus{
+		Name:                c.cfg.Name,
+		State:               c.currentStateLocked(),
+		ConsecutiveFailures: c.failures,
+		OpenedAt:            c.openedAt,
+		Rejected:            c.rejected,
+		Fallbacks:           c.fallbacks,
+	}
+}
+
+// Rejects reports if the next publish call would fail fast with ErrCircuitOpen (open circuit without fallback)
+func (c *CircuitBreakerPublisher) Rejects() bool {
+	return c.cfg.Fallback == nil && c.State() == CircuitOpen
+}
+
+// Diverts reports if the next publish call would be routed to the fallback Publisher (open circuit with fallback)
+func (c *CircuitBreakerPublisher) Diverts() bool {
+	return c.cfg.Fallback != nil && c.State() == CircuitOpen
+}
+
+// allow reports if the messages may be published using the underlying Publisher and if they are a probe
+func (c *CircuitBreakerPublisher) allow() (probe bool, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch c.currentStateLocked() {
+	case CircuitOpen:
+		c.rejected++
+		return false, false
+	case CircuitHalfOpen:
+		c.setStateLocked(CircuitHalfOpen)
+		if c.probes >= c.cfg.HalfOpenProbes {
+			c.rejected++
+			return false, false
+		}
+		c.probes++
+		return true, true
+	default:
+		return false, true
+	}
+}
+
+func (c *CircuitBreakerPublisher) release(probe bool) {
+	if !probe {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.releaseProbeLocked()
+}
+
+// record updates the circuit using the result of a publish call
+func (c *CircuitBreakerPublisher) record(probe bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if probe {
+		c.releaseProbeLocked()
+	}
+	if err != nil {
+		c.failures++
+		c.successes = 0
+		if probe || (c.state == CircuitClosed && c.failures >= c.cfg.FailureThreshold) {
+			c.openedAt = c.now()
+			c.setStateLocked(CircuitOpen)
+		}
+		return
+	}
+	c.failures = 0
+	if c.state != CircuitHalfOpen {
+		return
+	}
+	c.successes++
+	if c.successes >= c.cfg.HalfOpenProbes {
+		c.setStateLocked(CircuitClosed)
+	}
+}
+
+func (c *CircuitBreakerPublisher) releaseProbeLocked() {
+	if c.probes > 0 { // probes are reset when the state changes
+		c.probes--
+	}
+}
+
+func (c *CircuitBreakerPublisher) fallback(ctx context.Context, cause error, msgs []*Message) error {
+	if c.cfg.Fallback == nil {
+		return cause
+	}
+	c.mu.Lock()
+	c.fallbacks++
+	c.mu.Unlock()
+	return c.cfg.Fallback.Publish(ctx, msgs...)
+}
+
+// currentStateLocked returns the circuit state, an open circuit is half-open once its timeout elapsed
+func (c *CircuitBreakerPublisher) currentStateLocked() CircuitState {
+	if c.state == CircuitOpen && c.now().Sub(c.openedAt) >= c.cfg.OpenTimeout {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+func (c *CircuitBreakerPublisher) setStateLocked(s CircuitState) {
+	if c.state == s {
+		return
+	}
+	from := c.state
+	c.state = s
+	c.successes = 0
+	c.probes = 0
+	if c.cfg.OnStateChange != nil {
+		c.cfg.OnStateChange(from, s)
+	}
+}
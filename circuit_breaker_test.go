@@ -0,0 +1,193 @@
+package quark
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errPublisherStub = errors.New("kafka: client has run out of available brokers")
+
+type failingPublisher struct {
+	err   error
+	calls int
+}
+
+func (p *failingPublisher) Publish(context.Context, ...*Message) error {
+	p.calls++
+	return p.err
+}
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func newTestingCircuitBreaker(p Publisher, cfg CircuitBreakerConfig) (*CircuitBreakerPublisher, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cb := NewCircuitBreakerPublisher(p, cfg)
+	cb.now = clock.now
+	return cb, clock
+}
+
+var circuitStateTestingSuite = []struct {
+	s        CircuitState
+	expected string
+}{
+	{CircuitClosed, "closed"},
+	{CircuitOpen, "open"},
+	{CircuitHalfOpen, "half_open"},
+	{CircuitState(99), "unknown"},
+}
+
+func TestCircuitState_String(t *testing.T) {
+	for _, tt := range circuitStateTestingSuite {
+		t.Run("Circuit state name", func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.s.String())
+		})
+	}
+}
+
+func TestCircuitBreakerPublisher_Publish(t *testing.T) {
+	ctx := context.Background()
+	msg := NewMessage("123", "foo", []byte("bar"))
+
+	t.Run("Open circuit after failure threshold", func(t *testing.T) {
+		p := &failingPublisher{err: errPublisherStub}
+		var changes []CircuitState
+		cb, _ := newTestingCircuitBreaker(p, CircuitBreakerConfig{
+			FailureThreshold: 2,
+			OnStateChange: func(_, to CircuitState) {
+				changes = append(changes, to)
+			},
+		})
+		assert.True(t, errors.Is(cb.Publish(ctx, msg), errPublisherStub))
+		assert.Equal(t, CircuitClosed, cb.State())
+		assert.True(t, errors.Is(cb.Publish(ctx, msg), errPublisherStub))
+		assert.Equal(t, CircuitOpen, cb.State())
+		assert.True(t, errors.Is(cb.Publish(ctx, msg), ErrCircuitOpen))
+		assert.Equal(t, 2, p.calls)
+		assert.Equal(t, []CircuitState{CircuitOpen}, changes)
+
+		st := cb.CircuitStatus()
+		assert.Equal(t, 2, st.ConsecutiveFailures)
+		assert.Equal(t, int64(1), st.Rejected)
+		assert.False(t, st.OpenedAt.IsZero())
+	})
+
+	t.Run("Close circuit after successful probes", func(t *testing.T) {
+		p := &failingPublisher{err: errPublisherStub}
+		cb, clock := newTestingCircuitBreaker(p, CircuitBreakerConfig{
+			FailureThreshold: 1,
+			OpenTimeout:      time.Second,
+			HalfOpenProbes:   2,
+		})
+		assert.NotNil(t, cb.Publish(ctx, msg))
+		assert.Equal(t, CircuitOpen, cb.State())
+
+		clock.t = clock.t.Add(time.Second)
+		assert.Equal(t, CircuitHalfOpen, cb.State())
+		p.err = nil
+		assert.Nil(t, cb.Publish(ctx, msg))
+		assert.Equal(t, CircuitHalfOpen, cb.State())
+		assert.Nil(t, cb.Publish(ctx, msg))
+		assert.Equal(t, CircuitClosed, cb.State())
+		assert.Equal(t, 0, cb.CircuitStatus().ConsecutiveFailures)
+	})
+
+	t.Run("Open circuit after failed probe", func(t *testing.T) {
+		p := &failingPublisher{err: errPublisherStub}
+		cb, clock := newTestingCircuitBreaker(p, CircuitBreakerConfig{FailureThreshold: 3, OpenTimeout: time.Second})
+		for i := 0; i < 3; i++ {
+			_ = cb.Publish(ctx, msg)
+		}
+		clock.t = clock.t.Add(time.Second)
+		assert.True(t, errors.Is(cb.Publish(ctx, msg), errPublisherStub))
+		assert.Equal(t, CircuitOpen, cb.State())
+		assert.Equal(t, clock.t, cb.CircuitStatus().OpenedAt)
+		assert.Equal(t, 4, p.calls)
+	})
+
+	t.Run("Publish into fallback", func(t *testing.T) {
+		p := &failingPublisher{err: errPublisherStub}
+		fallback := &recordingPublisher{}
+		cb, _ := newTestingCircuitBreaker(p, CircuitBreakerConfig{FailureThreshold: 1, Fallback: fallback})
+		assert.Nil(t, cb.Publish(ctx, msg))
+		assert.Nil(t, cb.Publish(ctx, msg))
+		assert.Equal(t, 1, p.calls)
+		assert.Len(t, fallback.published, 2)
+		assert.Equal(t, int64(2), cb.CircuitStatus().Fallbacks)
+		assert.False(t, cb.Rejects())
+		assert.True(t, cb.Diverts())
+	})
+
+	t.Run("Ignore failures of cancelled contexts", func(t *testing.T) {
+		p := &failingPublisher{err: context.Canceled}
+		cb, _ := newTestingCircuitBreaker(p, CircuitBreakerConfig{FailureThreshold: 1})
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.True(t, errors.Is(cb.Publish(cancelCtx, msg), context.Canceled))
+		assert.Equal(t, CircuitClosed, cb.State())
+	})
+
+	t.Run("Publish without publisher", func(t *testing.T) {
+		cb := NewCircuitBreakerPublisher(nil, CircuitBreakerConfig{})
+		assert.True(t, errors.Is(cb.Publish(ctx, msg), ErrPublisherNotImplemented))
+	})
+}
+
+func TestEventWriter_CircuitBreaker(t *testing.T) {
+	t.Run("Skip retry backoff while publishing into fallback", func(t *testing.T) {
+		fallback := &recordingPublisher{}
+		cb := NewCircuitBreakerPublisher(&failingPublisher{err: errPublisherStub}, CircuitBreakerConfig{
+			FailureThreshold: 1,
+			Fallback:         fallback,
+		})
+		assert.Nil(t, cb.Publish(context.Background(), NewMessage("1", "foo", nil))) // opens the circuit
+		s := newSupervisor(NewBroker(WithRetryBackoff(time.Hour)), NewConsumer("foo"))
+
+		start := time.Now()
+		_, err := newEventWriter(s, cb).Write(context.Background(), []byte("bar"), "foo")
+		assert.Nil(t, err)
+		assert.Less(t, int64(time.Since(start)), int64(time.Second))
+		assert.Len(t, fallback.published, 2)
+	})
+}
+
+func TestBroker_StatusCircuitBreakers(t *testing.T) {
+	primary := NewCircuitBreakerPublisher(&failingPublisher{err: errPublisherStub}, CircuitBreakerConfig{
+		Name:             "kafka",
+		FailureThreshold: 1,
+	})
+	audit := NewCircuitBreakerPublisher(&failingPublisher{}, CircuitBreakerConfig{Name: "audit"})
+	b := NewBroker(WithPublisher(primary), WithRetryBackoff(time.Millisecond))
+	consumers := []*Consumer{
+		NewConsumer("foo"),
+		NewConsumer("bar").Publisher(audit),
+		NewConsumer("baz").Publisher(audit),
+	}
+	for _, c := range consumers {
+		b.supervisors[c] = newSupervisor(b, c)
+	}
+
+	s := newSupervisor(b, NewConsumer("foo"))
+	w := newEventWriter(s, primary)
+	_, err := w.Write(context.Background(), []byte("bar"), "foo")
+	assert.NotNil(t, err)
+	_, err = w.Write(context.Background(), []byte("bar"), "foo")
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+
+	st := b.Status()
+	require.Len(t, st.CircuitBreakers, 2)
+	assert.Equal(t, "audit", st.CircuitBreakers[0].Name)
+	assert.Equal(t, CircuitClosed, st.CircuitBreakers[0].State)
+	assert.Equal(t, "kafka", st.CircuitBreakers[1].Name)
+	assert.Equal(t, CircuitOpen, st.CircuitBreakers[1].State)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/neutrinocorp/quark/bus/kafka"
+)
+
+// kafkaTimestampLayout layout of the kafka.HeaderTimestamp header (time.Time String)
+const kafkaTimestampLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+func runConsume(ctx context.Context, env *environment, args []string) error {
+	fs := env.newFlagSet("consume", "-topic <topic> -group <group> [-from newest|oldest|<offset>|<RFC 3339 time>]")
+	topic := fs.String("topic", "", "topic to consume")
+	group := fs.String("group", "", "consumer group, offsets are committed to it")
+	from := fs.String("from", "", "start position used when the group has no committed offset")
+	poolSize := fs.Int("pool-size", 1, "number of workers")
+	asJSON := fs.Bool("json", false, "print messages as JSON lines")
+	if err := parseFlags(fs, args, "topic", "group"); err != nil {
+		return err
+	}
+	pos, err := quark.ParseStartPosition(*from)
+	if err != nil {
+		return err
+	}
+
+	b := kafka.NewKafkaBroker(env.Config,
+		quark.WithCluster(env.Cluster...),
+		quark.WithErrorHandler(func(_ context.Context, err error) {
+			fmt.Fprintln(env.Err, "quarkctl:", err)
+		}))
+	p := printer{out: env.Out, json: *asJSON}
+	mu := sync.Mutex{}
+	b.Topic(*topic).Group(*group).PoolSize(*poolSize).StartFrom(pos).
+		HandleFunc(func(_ quark.EventWriter, e *quark.Event) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if err := p.print(eventRecord(e)); err != nil {
+				fmt.Fprintln(env.Err, "quarkctl:", err)
+			}
+			return true
+		})
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- b.ListenAndServe()
+	}()
+	select {
+	case err = <-errC:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	return b.Shutdown(shutdownCtx)
+}
+
+// eventRecord builds a record from an Event received by the Apache Kafka provider
+func eventRecord(e *quark.Event) record {
+	partition, _ := strconv.ParseInt(e.Header.Get(kafka.HeaderPartition), 10, 32)
+	offset, _ := strconv.ParseInt(e.Header.Get(kafka.HeaderOffset), 10, 64)
+	timestamp, _ := time.Parse(kafkaTimestampLayout, e.Header.Get(kafka.HeaderTimestamp))
+	return record{
+		Topic:     e.Topic,
+		Partition: int32(partition),
+		Offset:    offset,
+		Timestamp: timestamp,
+		Message:   e.Body,
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/quark"
+	"github.com/neutrinocorp/quark/bus/kafka"
+	"github.com/neutrinocorp/quark/dlq"
+)
+
+// dlqTopicPrefix and dlqTopicSuffix naming conventions used to derive the original topic of messages published into
+// a DLQ without an original type header (e.g. dlq.orders or orders.dlq)
+const (
+	dlqTopicPrefix = "dlq."
+	dlqTopicSuffix = ".dlq"
+)
+
+// kafkaHeaderPrefix prefix of the headers set by the Apache Kafka provider when a message is received
+const kafkaHeaderPrefix = "quark-kafka-"
+
+func runDLQ(ctx context.Context, env *environment, args []string) error {
+	usage := "usage: quarkctl dlq <list|redrive> -topic <dlq topic> [flags]"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "list":
+		return runDLQList(ctx, env, args[1:])
+	case "redrive":
+		return runDLQRedrive(ctx, env, args[1:])
+	default:
+		return fmt.Errorf("unknown dlq command %q, %s", args[0], usage)
+	}
+}
+
+// filterFlags dlq.Filter flags shared by dlq sub-commands
+type filterFlags struct {
+	ids   *string
+	typ   *string
+	since *string
+	until *string
+}
+
+func newFilterFlags(fs *flag.FlagSet) filterFlags {
+	return filterFlags{
+		ids:   fs.String("id", "", "comma-separated message ids"),
+		typ:   fs.String("type", "", "original message type"),
+		since: fs.String("since", "", "RFC 3339 time or duration (e.g. 24h), messages published at or after it"),
+		until: fs.String("until", "", "RFC 3339 time or duration (e.g. 1h), messages published at or before it"),
+	}
+}
+
+func (f filterFlags) filter(now time.Time) (dlq.Filter, error) {
+	since, err := parseTime(*f.since, now)
+	if err != nil {
+		return dlq.Filter{}, err
+	}
+	until, err := parseTime(*f.until, now)
+	if err != nil {
+		return dlq.Filter{}, err
+	}
+	return dlq.Filter{
+		Ids:   splitList(*f.ids),
+		Type:  *f.typ,
+		Since: since,
+		Until: until,
+	}, nil
+}
+
+// parseTime parses an RFC 3339 time or a duration relative to now (e.g. 24h is 24 hours ago)
+func parseTime(v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected an RFC 3339 time or a duration", v)
+	}
+	return t, nil
+}
+
+func runDLQList(ctx context.Context, env *environment, args []string) error {
+	fs := env.newFlagSet("dlq list", "-topic <dlq topic> [-summary] [-id <id>,...] [-since <time>]")
+	topic := fs.String("topic", "", "dead-letter queue topic")
+	summary := fs.Bool("summary", false, "print message counts grouped by error and original type")
+	asJSON := fs.Bool("json", false, "print messages as JSON lines")
+	flags := newFilterFlags(fs)
+	if err := parseFlags(fs, args, "topic"); err != nil {
+		return err
+	}
+	filter, err := flags.filter(time.Now())
+	if err != nil {
+		return err
+	}
+
+	p := printer{out: env.Out, json: *asJSON}
+	messages := make([]*quark.Message, 0)
+	var printErr error
+	r := kafka.NewTopicReader(env.Config, env.Cluster...)
+	err = r.ReadMessages(ctx, *topic, func(msg *sarama.ConsumerMessage) bool {
+		rec := newRecord(msg)
+		if !filter.Match(rec.Message) {
+			return true
+		}
+		messages = append(messages, rec.Message)
+		if !*summary {
+			printErr = p.print(rec)
+		}
+		return printErr == nil
+	})
+	if err != nil {
+		return err
+	} else if printErr != nil {
+		return printErr
+	}
+	if *summary {
+		return printGroups(env, dlq.GroupMessages(messages))
+	} else if !*asJSON {
+		fmt.Fprintf(env.Out, "%d message(s) in %s\n", len(messages), *topic)
+	}
+	return nil
+}
+
+func printGroups(env *environment, groups []dlq.Group) error {
+	w := tabwriter.NewWriter(env.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COUNT\tTYPE\tERROR")
+	for _, g := range groups {
+		typ, cause := g.Type, g.Error
+		if typ == "" {
+			typ = "-"
+		}
+		if cause == "" {
+			cause = "-"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", len(g.Messages), typ, cause)
+	}
+	return w.Flush()
+}
+
+// originalTopic derives the original topic of a DLQ following dlq.<topic> or <topic>.dlq naming conventions
+func originalTopic(dlqTopic string) (string, bool) {
+	switch {
+	case strings.HasPrefix(dlqTopic, dlqTopicPrefix) && len(dlqTopic) > len(dlqTopicPrefix):
+		return strings.TrimPrefix(dlqTopic, dlqTopicPrefix), true
+	case strings.HasSuffix(dlqTopic, dlqTopicSuffix) && len(dlqTopic) > len(dlqTopicSuffix):
+		return strings.TrimSuffix(dlqTopic, dlqTopicSuffix), true
+	default:
+		return "", false
+	}
+}
+
+// dropProviderHeaders removes the headers set by the Apache Kafka provider when the DLQ message was received
+func dropProviderHeaders(msg *quark.Message) {
+	for k := range msg.Metadata.ExternalData {
+		if strings.HasPrefix(k, kafkaHeaderPrefix) {
+			delete(msg.Metadata.ExternalData, k)
+		}
+	}
+}
+
+func runDLQRedrive(ctx context.Context, env *environment, args []string) error {
+	opts := dlq.RedriveOptions{}
+	fs := env.newFlagSet("dlq redrive", "-topic <dlq topic> [-to <topic>] [-id <id>,...] [-since <time>]")
+	topic := fs.String("topic", "", "dead-letter queue topic")
+	fs.StringVar(&opts.Topic, "to", "", "topic to republish into, the message original type by default")
+	fs.BoolVar(&opts.KeepRedeliveries, "keep-redeliveries", false, "keep the message redelivery count")
+	dryRun := fs.Bool("dry-run", false, "print the selected messages without republishing them")
+	flags := newFilterFlags(fs)
+	if err := parseFlags(fs, args, "topic"); err != nil {
+		return err
+	}
+	filter, err := flags.filter(time.Now())
+	if err != nil {
+		return err
+	}
+	opts.DefaultTopic, _ = originalTopic(*topic)
+
+	r := kafka.NewTopicReader(env.Config, env.Cluster...)
+	messages, err := dlq.Inspect(ctx, r, *topic, filter)
+	if err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		dropProviderHeaders(msg)
+		redriven, err := dlq.RedriveMessage(msg, opts)
+		if err != nil {
+			return fmt.Errorf("message %s: %w, use -to", msg.Id, err)
+		}
+		fmt.Fprintf(env.Out, "re-driving message %s into %s\n", msg.Id, redriven.Type)
+	}
+	if *dryRun || len(messages) == 0 {
+		fmt.Fprintf(env.Out, "%d message(s) selected\n", len(messages))
+		return nil
+	}
+	env.Config.Producer.Return.Successes = true
+	p := kafka.NewKafkaPublisher(kafka.KafkaConfiguration{Config: env.Config}, env.Cluster...)
+	n, err := dlq.Redrive(ctx, p, opts, messages...)
+	fmt.Fprintf(env.Out, "%d message(s) re-driven\n", n)
+	return err
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/neutrinocorp/quark/bus/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+var originalTopicTestingSuite = []struct {
+	topic    string
+	expected string
+	ok       bool
+}{
+	{"dlq.chat.0", "chat.0", true},
+	{"org.neutrinocorp.event.user.created.dlq", "org.neutrinocorp.event.user.created", true},
+	{"dlq.", "", false},
+	{"chat.0", "", false},
+}
+
+func TestOriginalTopic(t *testing.T) {
+	for _, tt := range originalTopicTestingSuite {
+		t.Run("Derive original topic from DLQ name", func(t *testing.T) {
+			topic, ok := originalTopic(tt.topic)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, topic)
+		})
+	}
+}
+
+var parseTimeTestingSuite = []struct {
+	value    string
+	expected time.Time
+	ok       bool
+}{
+	{"", time.Time{}, true},
+	{"24h", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), true},
+	{"2020-12-31T12:00:00Z", time.Date(2020, 12, 31, 12, 0, 0, 0, time.UTC), true},
+	{"yesterday", time.Time{}, false},
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)
+	for _, tt := range parseTimeTestingSuite {
+		t.Run("Parse filter time", func(t *testing.T) {
+			v, err := parseTime(tt.value, now)
+			assert.Equal(t, tt.ok, err == nil)
+			assert.True(t, tt.expected.Equal(v))
+		})
+	}
+}
+
+func TestDropProviderHeaders(t *testing.T) {
+	t.Run("Drop Apache Kafka provider headers", func(t *testing.T) {
+		msg := quark.NewMessage("123", "dlq.chat.0", nil)
+		msg.Metadata.ExternalData[kafka.HeaderOffset] = "42"
+		msg.Metadata.ExternalData[quark.HeaderMessageError] = "handler failed"
+		dropProviderHeaders(msg)
+		assert.Equal(t, map[string]string{quark.HeaderMessageError: "handler failed"}, msg.Metadata.ExternalData)
+	})
+}
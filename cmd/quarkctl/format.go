@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/quark"
+	"github.com/neutrinocorp/quark/bus/kafka"
+)
+
+// record an Apache Kafka message and its decoded Quark Message
+type record struct {
+	Topic     string         `json:"topic"`
+	Partition int32          `json:"partition"`
+	Offset    int64          `json:"offset"`
+	Timestamp time.Time      `json:"timestamp"`
+	Message   *quark.Message `json:"message"`
+}
+
+func newRecord(msg *sarama.ConsumerMessage) record {
+	m := new(quark.Message)
+	kafka.UnmarshalKafkaMessage(msg, m)
+	return record{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Timestamp: msg.Timestamp,
+		Message:   m,
+	}
+}
+
+// printer writes records either as human-readable text or as JSON lines
+type printer struct {
+	out  io.Writer
+	json bool
+}
+
+func (p printer) print(r record) error {
+	if p.json {
+		return p.printJSON(r)
+	}
+	m := r.Message
+	fmt.Fprintf(p.out, "%s/%d@%d %s\n", r.Topic, r.Partition, r.Offset, r.Timestamp.UTC().Format(time.RFC3339Nano))
+	printAttr(p.out, "id", m.Id)
+	printAttr(p.out, "type", m.Type)
+	printAttr(p.out, "specversion", m.SpecVersion)
+	printAttr(p.out, "source", m.Source)
+	printAttr(p.out, "datacontenttype", m.ContentType)
+	printAttr(p.out, "dataschema", m.DataSchema)
+	printAttr(p.out, "subject", m.Subject)
+	if !m.Time.IsZero() {
+		printAttr(p.out, "time", m.Time.UTC().Format(time.RFC3339Nano))
+	}
+	printAttr(p.out, "correlation-id", m.Metadata.CorrelationId)
+	printAttr(p.out, "host", m.Metadata.Host)
+	printAttr(p.out, "redelivery-count", fmt.Sprint(m.Metadata.RedeliveryCount))
+	keys := make([]string, 0, len(m.Metadata.ExternalData))
+	for k := range m.Metadata.ExternalData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		printAttr(p.out, k, m.Metadata.ExternalData[k])
+	}
+	_, err := fmt.Fprintf(p.out, "  data: %s\n\n", m.Data)
+	return err
+}
+
+func printAttr(w io.Writer, key, value string) {
+	if value != "" {
+		fmt.Fprintf(w, "  %s: %s\n", key, value)
+	}
+}
+
+// printJSON writes the record as a single JSON line, message data is embedded as JSON if valid or as a string
+func (p printer) printJSON(r record) error {
+	type jsonMessage struct {
+		quark.Message
+		Data interface{} `json:"data,omitempty"`
+	}
+	msg := jsonMessage{Message: *r.Message}
+	msg.Message.Data = nil
+	if json.Valid(r.Message.Data) {
+		msg.Data = json.RawMessage(r.Message.Data)
+	} else if len(r.Message.Data) > 0 {
+		msg.Data = string(r.Message.Data)
+	}
+	return json.NewEncoder(p.out).Encode(struct {
+		record
+		Message jsonMessage `json:"message"`
+	}{record: r, Message: msg})
+}
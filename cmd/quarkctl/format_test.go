@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRecord() record {
+	return newRecord(&sarama.ConsumerMessage{
+		Topic:     "chat.0",
+		Partition: 1,
+		Offset:    42,
+		Timestamp: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		Key:       []byte("123"),
+		Value:     []byte(`{"text":"hello"}`),
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte(quark.HeaderMessageType), Value: []byte("chat.0")},
+			{Key: []byte(quark.HeaderMessageSource), Value: []byte("quarkctl")},
+			{Key: []byte(quark.HeaderMessageError), Value: []byte("handler failed")},
+		},
+	})
+}
+
+func TestPrinter(t *testing.T) {
+	t.Run("Print decoded attributes", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		require.NoError(t, printer{out: out}.print(newTestRecord()))
+		assert.Contains(t, out.String(), "chat.0/1@42 2021-01-01T00:00:00Z")
+		assert.Contains(t, out.String(), "  id: 123\n")
+		assert.Contains(t, out.String(), "  source: quarkctl\n")
+		assert.Contains(t, out.String(), "  "+quark.HeaderMessageError+": handler failed\n")
+		assert.Contains(t, out.String(), `  data: {"text":"hello"}`)
+	})
+
+	t.Run("Print JSON lines", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		require.NoError(t, printer{out: out, json: true}.print(newTestRecord()))
+		v := struct {
+			Offset  int64 `json:"offset"`
+			Message struct {
+				Id   string          `json:"id"`
+				Data json.RawMessage `json:"data"`
+			} `json:"message"`
+		}{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &v))
+		assert.Equal(t, int64(42), v.Offset)
+		assert.Equal(t, "123", v.Message.Id)
+		assert.JSONEq(t, `{"text":"hello"}`, string(v.Message.Data))
+	})
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/Shopify/sarama"
+)
+
+// partitionLag committed offset of a consumer group on a topic partition and how far it is behind its end
+type partitionLag struct {
+	Topic     string
+	Partition int32
+	Committed int64
+	End       int64
+}
+
+// Lag number of messages not consumed yet, every message is pending if the group has not committed any offset
+func (l partitionLag) Lag() int64 {
+	if l.Committed < 0 {
+		return l.End
+	}
+	return l.End - l.Committed
+}
+
+func runLag(_ context.Context, env *environment, args []string) error {
+	fs := env.newFlagSet("lag", "-group <group> [-topic <topic>]")
+	group := fs.String("group", "", "consumer group")
+	topic := fs.String("topic", "", "topic, every topic the group committed offsets to by default")
+	if err := parseFlags(fs, args, "group"); err != nil {
+		return err
+	}
+
+	client, err := sarama.NewClient(env.Cluster, env.Config)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		return err
+	}
+	lags, err := groupLag(client, admin, *group, *topic)
+	if err != nil {
+		return err
+	}
+	return printLag(env.Out, lags)
+}
+
+// groupLag returns the lag of the given consumer group, if no topic is given every topic is verified and only
+// partitions with committed offsets are returned
+func groupLag(client sarama.Client, admin sarama.ClusterAdmin, group, topic string) ([]partitionLag, error) {
+	topics := []string{topic}
+	if topic == "" {
+		all, err := client.Topics()
+		if err != nil {
+			return nil, err
+		}
+		topics = all
+	}
+	request := make(map[string][]int32, len(topics))
+	for _, t := range topics {
+		partitions, err := client.Partitions(t)
+		if err != nil {
+			return nil, err
+		}
+		request[t] = partitions
+	}
+	resp, err := admin.ListConsumerGroupOffsets(group, request)
+	if err != nil {
+		return nil, err
+	} else if resp.Err != sarama.ErrNoError {
+		return nil, resp.Err
+	}
+
+	lags := make([]partitionLag, 0)
+	for t, partitions := range request {
+		for _, partition := range partitions {
+			committed := int64(-1)
+			if block := resp.GetBlock(t, partition); block != nil {
+				committed = block.Offset
+			}
+			if committed < 0 && topic == "" {
+				continue // topic not consumed by the group
+			}
+			end, err := client.GetOffset(t, partition, sarama.OffsetNewest)
+			if err != nil {
+				return nil, err
+			}
+			lags = append(lags, partitionLag{Topic: t, Partition: partition, Committed: committed, End: end})
+		}
+	}
+	sort.Slice(lags, func(i, j int) bool {
+		if lags[i].Topic != lags[j].Topic {
+			return lags[i].Topic < lags[j].Topic
+		}
+		return lags[i].Partition < lags[j].Partition
+	})
+	return lags, nil
+}
+
+func printLag(out io.Writer, lags []partitionLag) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOPIC\tPARTITION\tCOMMITTED\tEND\tLAG")
+	total := int64(0)
+	for _, l := range lags {
+		committed := "-"
+		if l.Committed >= 0 {
+			committed = fmt.Sprint(l.Committed)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%d\n", l.Topic, l.Partition, committed, l.End, l.Lag())
+		total += l.Lag()
+	}
+	fmt.Fprintf(w, "\t\t\tTOTAL\t%d\n", total)
+	return w.Flush()
+}
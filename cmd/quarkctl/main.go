@@ -0,0 +1,210 @@
+// Command quarkctl operates Quark applications running on Apache Kafka.
+//
+// Publishes messages with Quark headers, tails and consumes topics decoding CloudEvents attributes, inspects
+// consumer group lag and lists or re-drives dead-letter queue (DLQ) messages.
+//
+//	quarkctl [-brokers host:port,...] [-config quark.yaml] <command> [flags]
+//
+// Apache Kafka TLS and SASL settings are read from the kafka section of the Quark configuration file (if any) and
+// QUARK_KAFKA_* environment variables (see kafka.ConfigSection)
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/quark"
+	"github.com/neutrinocorp/quark/bus/kafka"
+)
+
+const defaultBroker = "localhost:9092"
+
+// errUsage a command was invoked with invalid arguments, its usage was already printed
+var errUsage = errors.New("quarkctl: invalid usage")
+
+// command is a quarkctl sub-command
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, env *environment, args []string) error
+}
+
+var commands = []command{
+	{name: "publish", summary: "publish a message, data is read from a file or stdin", run: runPublish},
+	{name: "tail", summary: "print messages published into a topic", run: runTail},
+	{name: "consume", summary: "consume a topic using a consumer group", run: runConsume},
+	{name: "lag", summary: "print consumer group lag per partition", run: runLag},
+	{name: "dlq", summary: "list or re-drive dead-letter queue messages", run: runDLQ},
+}
+
+// environment connection settings and output shared by every command
+type environment struct {
+	Cluster []string
+	Config  *sarama.Config
+	Out     io.Writer
+	Err     io.Writer
+}
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, "quarkctl:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("quarkctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	brokers := fs.String("brokers", "", "comma-separated Apache Kafka cluster addresses (default $"+
+		quark.EnvPrefix+"CLUSTER or "+defaultBroker+")")
+	configPath := fs.String("config", "", "Quark configuration file, its cluster and kafka section are used")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: quarkctl [flags] <command> [command flags]")
+		fmt.Fprintln(stderr, "\ncommands:")
+		for _, c := range commands {
+			fmt.Fprintf(stderr, "  %-8s %s\n", c.name, c.summary)
+		}
+		fmt.Fprintln(stderr, "\nflags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	cmd, ok := findCommand(fs.Arg(0))
+	if !ok {
+		fmt.Fprintf(stderr, "quarkctl: unknown command %q\n", fs.Arg(0))
+		fs.Usage()
+		return errUsage
+	}
+	env, err := newEnvironment(*brokers, *configPath, os.LookupEnv)
+	if err != nil {
+		return err
+	}
+	env.Out, env.Err = stdout, stderr
+	return cmd.run(ctx, env, fs.Args()[1:])
+}
+
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// newEnvironment resolves the cluster addresses and sarama configuration, brokers flag takes precedence over the
+// configuration file and environment variables
+func newEnvironment(brokers, configPath string, lookup func(string) (string, bool)) (*environment, error) {
+	section := kafka.ConfigSection{}
+	cluster := make([]string, 0)
+	if configPath != "" {
+		cfg, err := quark.LoadConfig(configPath)
+		if err != nil {
+			return nil, err
+		}
+		if err = cfg.DecodeProvider(kafka.ConfigSectionName, &section); err != nil &&
+			!errors.Is(err, quark.ErrProviderConfigNotFound) {
+			return nil, err
+		}
+		cluster = cfg.Cluster
+	} else if v, ok := lookup(quark.EnvPrefix + "CLUSTER"); ok {
+		cluster = splitList(v)
+	}
+	if brokers != "" {
+		cluster = splitList(brokers)
+	}
+	if len(cluster) == 0 {
+		cluster = []string{defaultBroker}
+	}
+
+	if err := section.ApplyEnv(lookup); err != nil {
+		return nil, err
+	}
+	cfg, err := section.SaramaConfig()
+	if err != nil {
+		return nil, err
+	}
+	return &environment{
+		Cluster: cluster,
+		Config:  cfg,
+	}, nil
+}
+
+func splitList(v string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// newFlagSet allocates a sub-command flag set printing its usage into the environment error output
+func (e *environment) newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.Err)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: quarkctl %s %s\n\nflags:\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses sub-command arguments, missing required flags print the usage
+func parseFlags(fs *flag.FlagSet, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	for _, name := range required {
+		if f := fs.Lookup(name); f != nil && f.Value.String() == "" {
+			fmt.Fprintf(fs.Output(), "flag -%s is required\n", name)
+			fs.Usage()
+			return errUsage
+		}
+	}
+	return nil
+}
+
+// headerFlag repeatable key=value flag
+type headerFlag map[string]string
+
+func (h headerFlag) String() string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+h[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (h headerFlag) Set(v string) error {
+	kv := strings.SplitN(v, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return fmt.Errorf("invalid header %q, expected key=value", v)
+	}
+	h[kv[0]] = kv[1]
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaderFlag(t *testing.T) {
+	t.Run("Parse repeated key=value headers", func(t *testing.T) {
+		h := headerFlag{}
+		require.NoError(t, h.Set("tenant=acme"))
+		require.NoError(t, h.Set("trace=a=b"))
+		assert.Equal(t, "tenant=acme,trace=a=b", h.String())
+		assert.Error(t, h.Set("tenant"))
+		assert.Error(t, h.Set("=acme"))
+	})
+}
+
+var newEnvironmentTestingSuite = []struct {
+	brokers  string
+	env      map[string]string
+	expected []string
+}{
+	{"", nil, []string{defaultBroker}},
+	{"", map[string]string{"QUARK_CLUSTER": "a:9092, b:9092"}, []string{"a:9092", "b:9092"}},
+	{"c:9092", map[string]string{"QUARK_CLUSTER": "a:9092"}, []string{"c:9092"}},
+}
+
+func TestNewEnvironment(t *testing.T) {
+	for _, tt := range newEnvironmentTestingSuite {
+		t.Run("Resolve cluster addresses", func(t *testing.T) {
+			env, err := newEnvironment(tt.brokers, "", func(key string) (string, bool) {
+				v, ok := tt.env[key]
+				return v, ok
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, env.Cluster)
+			assert.NotNil(t, env.Config)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("Reject unknown commands", func(t *testing.T) {
+		stderr := new(bytes.Buffer)
+		err := run(context.Background(), []string{"foo"}, new(bytes.Buffer), stderr)
+		assert.ErrorIs(t, err, errUsage)
+		assert.Contains(t, stderr.String(), `unknown command "foo"`)
+	})
+
+	t.Run("Require command flags", func(t *testing.T) {
+		stderr := new(bytes.Buffer)
+		err := run(context.Background(), []string{"-brokers", "a:9092", "lag"}, new(bytes.Buffer), stderr)
+		assert.ErrorIs(t, err, errUsage)
+		assert.Contains(t, stderr.String(), "flag -group is required")
+	})
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/neutrinocorp/quark"
+	"github.com/neutrinocorp/quark/bus/kafka"
+)
+
+// publishOptions Message attributes set from publish flags
+type publishOptions struct {
+	Topic         string
+	Id            string
+	Source        string
+	ContentType   string
+	DataSchema    string
+	Subject       string
+	CorrelationId string
+	Host          string
+	Headers       headerFlag
+}
+
+// message builds the Message to publish, a random id is generated if none was given
+func (o publishOptions) message(data []byte) *quark.Message {
+	id := o.Id
+	if id == "" {
+		id = uuid.New().String()
+	}
+	msg := quark.NewMessage(id, o.Topic, data)
+	msg.Source = o.Source
+	msg.ContentType = o.ContentType
+	msg.DataSchema = o.DataSchema
+	msg.Subject = o.Subject
+	msg.Metadata.Host = o.Host
+	if o.CorrelationId != "" {
+		msg.Metadata.CorrelationId = o.CorrelationId
+	}
+	for k, v := range o.Headers {
+		msg.Metadata.ExternalData[k] = v
+	}
+	return msg
+}
+
+func runPublish(ctx context.Context, env *environment, args []string) error {
+	opts := publishOptions{Headers: headerFlag{}}
+	fs := env.newFlagSet("publish", "-topic <topic> [-file data.json]")
+	fs.StringVar(&opts.Topic, "topic", "", "topic (message type) to publish into")
+	fs.StringVar(&opts.Id, "id", "", "message id, random by default")
+	fs.StringVar(&opts.Source, "source", "", "message source")
+	fs.StringVar(&opts.ContentType, "content-type", "application/json", "message data content type")
+	fs.StringVar(&opts.DataSchema, "data-schema", "", "message data schema")
+	fs.StringVar(&opts.Subject, "subject", "", "message subject")
+	fs.StringVar(&opts.CorrelationId, "correlation-id", "", "message correlation id, the message id by default")
+	fs.StringVar(&opts.Host, "host", "", "message sender host")
+	fs.Var(opts.Headers, "header", "additional header as key=value, may be repeated")
+	file := fs.String("file", "", "file containing message data, stdin is read if empty or -")
+	if err := parseFlags(fs, args, "topic"); err != nil {
+		return err
+	}
+
+	data, err := readData(*file)
+	if err != nil {
+		return err
+	}
+	msg := opts.message(data)
+	env.Config.Producer.Return.Successes = true
+	p := kafka.NewKafkaPublisher(kafka.KafkaConfiguration{Config: env.Config}, env.Cluster...)
+	if err = p.Publish(ctx, msg); err != nil {
+		return err
+	}
+	fmt.Fprintf(env.Out, "published message %s into %s\n", msg.Id, msg.Type)
+	return nil
+}
+
+func readData(path string) ([]byte, error) {
+	if path == "" || path == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(path)
+}
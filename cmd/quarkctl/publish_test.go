@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishOptions_Message(t *testing.T) {
+	t.Run("Build message from flags", func(t *testing.T) {
+		opts := publishOptions{
+			Topic:         "org.neutrinocorp.event.user.created",
+			Id:            "123",
+			Source:        "quarkctl",
+			ContentType:   "application/json",
+			CorrelationId: "abc",
+			Headers:       headerFlag{"tenant": "acme"},
+		}
+		msg := opts.message([]byte(`{"name":"aruizeac"}`))
+		assert.Equal(t, "123", msg.Id)
+		assert.Equal(t, opts.Topic, msg.Type)
+		assert.Equal(t, quark.CloudEventsVersion, msg.SpecVersion)
+		assert.Equal(t, "quarkctl", msg.Source)
+		assert.Equal(t, "abc", msg.Metadata.CorrelationId)
+		assert.Equal(t, "acme", msg.Metadata.ExternalData["tenant"])
+		assert.Equal(t, `{"name":"aruizeac"}`, string(msg.Data))
+	})
+
+	t.Run("Generate message id", func(t *testing.T) {
+		msg := publishOptions{Topic: "chat.0", Headers: headerFlag{}}.message(nil)
+		assert.NotEmpty(t, msg.Id)
+		assert.Equal(t, msg.Id, msg.Metadata.CorrelationId)
+	})
+}
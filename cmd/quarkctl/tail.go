@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/quark"
+	"github.com/neutrinocorp/quark/bus/kafka"
+)
+
+func runTail(ctx context.Context, env *environment, args []string) error {
+	fs := env.newFlagSet("tail", "-topic <topic> [-from newest|oldest|<offset>|<RFC 3339 time>]")
+	topic := fs.String("topic", "", "topic to read from")
+	from := fs.String("from", "newest", "start position: newest, oldest, an offset or an RFC 3339 timestamp")
+	limit := fs.Int("n", 0, "stop after n messages, 0 reads until interrupted")
+	follow := fs.Bool("f", true, "wait for new messages, otherwise stop at the end of every partition")
+	asJSON := fs.Bool("json", false, "print messages as JSON lines")
+	if err := parseFlags(fs, args, "topic"); err != nil {
+		return err
+	}
+	pos, err := quark.ParseStartPosition(*from)
+	if err != nil {
+		return err
+	}
+
+	p := printer{out: env.Out, json: *asJSON}
+	count := 0
+	var printErr error
+	r := kafka.NewTopicReader(env.Config, env.Cluster...)
+	r.From, r.Follow = pos, *follow
+	err = r.ReadMessages(ctx, *topic, func(msg *sarama.ConsumerMessage) bool {
+		if printErr = p.print(newRecord(msg)); printErr != nil {
+			return false
+		}
+		count++
+		return *limit <= 0 || count < *limit
+	})
+	if err != nil {
+		return err
+	}
+	return printErr
+}
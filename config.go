@@ -0,0 +1,368 @@
+package quark
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of every environment variable overriding a Config value
+const EnvPrefix = "QUARK_"
+
+// Duration is a time.Duration readable from text (e.g. "3s", "500ms") on YAML, JSON and environment variables
+type Duration time.Duration
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Config is the declarative configuration of a Broker and its consumers.
+//
+// JSON documents are accepted as well since they are valid YAML
+type Config struct {
+	Cluster          []string `yaml:"cluster"`
+	PoolSize         int      `yaml:"pool_size"`
+	MaxRetries       int      `yaml:"max_retries"`
+	RetryBackoff     Duration `yaml:"retry_backoff"`
+	ConnRetries      int      `yaml:"conn_retries"`
+	ConnRetryBackoff Duration `yaml:"conn_retry_backoff"`
+	Source           string   `yaml:"source"`
+	ContentType      string   `yaml:"content_type"`
+	ReplyTopic       string   `yaml:"reply_topic"`
+	HandlerTimeout   Duration `yaml:"handler_timeout"`
+	// StartupMode is either "atomic" (default) or "best_effort"
+	StartupMode string               `yaml:"startup_mode"`
+	Restart     *RestartPolicyConfig `yaml:"restart"`
+	// Provider contains provider-specific sections by name (e.g. kafka), see DecodeProvider
+	Provider  map[string]interface{} `yaml:"provider"`
+	Consumers []ConsumerConfig       `yaml:"consumers"`
+}
+
+// RestartPolicyConfig is the declarative configuration of a RestartPolicy
+type RestartPolicyConfig struct {
+	// Strategy is either "one_for_one" (default), "one_for_all" or "never"
+	Strategy    string   `yaml:"strategy"`
+	MaxRestarts int      `yaml:"max_restarts"`
+	Window      Duration `yaml:"window"`
+	Escalate    bool     `yaml:"escalate"`
+}
+
+// ConsumerConfig is the declarative configuration of a Consumer, zero values fall back to the Broker ones
+type ConsumerConfig struct {
+	// Name identifies the consumer on environment variables (e.g. QUARK_CONSUMER_<NAME>_POOL_SIZE)
+	Name         string   `yaml:"name"`
+	Topics       []string `yaml:"topics"`
+	Group        string   `yaml:"group"`
+	Cluster      []string `yaml:"cluster"`
+	PoolSize     int      `yaml:"pool_size"`
+	MaxRetries   int      `yaml:"max_retries"`
+	RetryBackoff Duration `yaml:"retry_backoff"`
+	Source       string   `yaml:"source"`
+	ContentType  string   `yaml:"content_type"`
+	// HandlerTimeout maximum time the handler may take to serve an Event
+	HandlerTimeout Duration `yaml:"handler_timeout"`
+	// StartFrom accepts "newest", "oldest", an offset or an RFC 3339 timestamp
+	StartFrom string               `yaml:"start_from"`
+	Restart   *RestartPolicyConfig `yaml:"restart"`
+	// Handler is the name of a Handler or HandlerFunc registered in the HandlerRegistry
+	Handler string `yaml:"handler"`
+}
+
+// LoadConfig reads a YAML or JSON configuration file and applies QUARK_* environment variable overrides
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	if err = cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ParseConfig decodes the given YAML or JSON document
+func ParseConfig(data []byte) (*Config, error) {
+	cfg := new(Config)
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ApplyEnv overrides configuration values with the environment variables found by lookup (e.g. os.LookupEnv).
+//
+// Broker values use QUARK_<FIELD> (e.g. QUARK_CLUSTER=a:9092,b:9092, QUARK_POOL_SIZE=10) while consumer values use
+// QUARK_CONSUMER_<NAME>_<FIELD> (e.g. QUARK_CONSUMER_PAYMENTS_GROUP), where NAME is the upper-cased consumer name
+// with non-alphanumeric characters replaced by underscores
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	env := envReader{lookup: lookup}
+	env.strings(EnvPrefix+"CLUSTER", &c.Cluster)
+	env.int(EnvPrefix+"POOL_SIZE", &c.PoolSize)
+	env.int(EnvPrefix+"MAX_RETRIES", &c.MaxRetries)
+	env.duration(EnvPrefix+"RETRY_BACKOFF", &c.RetryBackoff)
+	env.int(EnvPrefix+"CONN_RETRIES", &c.ConnRetries)
+	env.duration(EnvPrefix+"CONN_RETRY_BACKOFF", &c.ConnRetryBackoff)
+	env.string(EnvPrefix+"SOURCE", &c.Source)
+	env.string(EnvPrefix+"CONTENT_TYPE", &c.ContentType)
+	env.string(EnvPrefix+"REPLY_TOPIC", &c.ReplyTopic)
+	env.duration(EnvPrefix+"HANDLER_TIMEOUT", &c.HandlerTimeout)
+	env.string(EnvPrefix+"STARTUP_MODE", &c.StartupMode)
+	for i := range c.Consumers {
+		cs := &c.Consumers[i]
+		if cs.Name == "" {
+			continue
+		}
+		prefix := EnvPrefix + "CONSUMER_" + EnvName(cs.Name) + "_"
+		env.strings(prefix+"TOPICS", &cs.Topics)
+		env.string(prefix+"GROUP", &cs.Group)
+		env.strings(prefix+"CLUSTER", &cs.Cluster)
+		env.int(prefix+"POOL_SIZE", &cs.PoolSize)
+		env.int(prefix+"MAX_RETRIES", &cs.MaxRetries)
+		env.duration(prefix+"RETRY_BACKOFF", &cs.RetryBackoff)
+		env.duration(prefix+"HANDLER_TIMEOUT", &cs.HandlerTimeout)
+		env.string(prefix+"START_FROM", &cs.StartFrom)
+		env.string(prefix+"HANDLER", &cs.Handler)
+	}
+	return env.err.ErrorOrNil()
+}
+
+// DecodeProvider decodes the provider section with the given name into out using its yaml tags
+func (c *Config) DecodeProvider(name string, out interface{}) error {
+	section, ok := c.Provider[name]
+	if !ok {
+		return fmt.Errorf("provider %s: %w", name, ErrProviderConfigNotFound)
+	}
+	data, err := yaml.Marshal(section)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, out)
+}
+
+// EnvName converts the given name into an environment variable name segment (e.g. "user-events" -> "USER_EVENTS")
+func EnvName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// Options returns the Broker options described by the configuration
+func (c *Config) Options() ([]Option, error) {
+	opts := []Option{
+		WithPoolSize(c.PoolSize),
+		WithMaxRetries(c.MaxRetries),
+		WithRetryBackoff(time.Duration(c.RetryBackoff)),
+		WithMaxConnRetries(c.ConnRetries),
+		WithConnRetryBackoff(time.Duration(c.ConnRetryBackoff)),
+		WithHandlerTimeout(time.Duration(c.HandlerTimeout)),
+	}
+	if len(c.Cluster) > 0 {
+		opts = append(opts, WithCluster(c.Cluster...))
+	}
+	if c.Source != "" {
+		opts = append(opts, WithBaseMessageSource(c.Source))
+	}
+	if c.ContentType != "" {
+		opts = append(opts, WithBaseMessageContentType(c.ContentType))
+	}
+	if c.ReplyTopic != "" {
+		opts = append(opts, WithReplyTopic(c.ReplyTopic))
+	}
+	switch c.StartupMode {
+	case "", "atomic":
+	case "best_effort":
+		opts = append(opts, WithStartupMode(StartupBestEffort))
+	default:
+		return nil, fmt.Errorf("startup mode %q: %w", c.StartupMode, ErrInvalidConfig)
+	}
+	if c.Restart != nil {
+		p, err := c.Restart.Policy()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithRestartPolicy(p))
+	}
+	return opts, nil
+}
+
+// Policy returns the RestartPolicy described by the configuration
+func (c RestartPolicyConfig) Policy() (RestartPolicy, error) {
+	p := RestartPolicy{
+		MaxRestarts: c.MaxRestarts,
+		Window:      time.Duration(c.Window),
+		Escalate:    c.Escalate,
+	}
+	switch c.Strategy {
+	case "", "one_for_one":
+		p.Strategy = OneForOne
+	case "one_for_all":
+		p.Strategy = OneForAll
+	case "never":
+		p.Strategy = NeverRestart
+	default:
+		return RestartPolicy{}, fmt.Errorf("restart strategy %q: %w", c.Strategy, ErrInvalidConfig)
+	}
+	return p, nil
+}
+
+// Consumer builds a Consumer from the configuration binding its handler from the given registry
+func (c ConsumerConfig) Consumer(r *HandlerRegistry) (*Consumer, error) {
+	startFrom, err := ParseStartPosition(c.StartFrom)
+	if err != nil {
+		return nil, err
+	}
+	cs := NewConsumer(c.Topics...).
+		Group(c.Group).
+		PoolSize(c.PoolSize).
+		MaxRetries(c.MaxRetries).
+		RetryBackoff(time.Duration(c.RetryBackoff)).
+		HandlerTimeout(time.Duration(c.HandlerTimeout)).
+		Source(c.Source).
+		ContentType(c.ContentType).
+		StartFrom(startFrom)
+	if len(c.Cluster) > 0 {
+		cs.Address(c.Cluster...)
+	}
+	if c.Restart != nil {
+		p, err := c.Restart.Policy()
+		if err != nil {
+			return nil, err
+		}
+		cs.RestartPolicy(p)
+	}
+	if err = r.bind(c.Handler, cs); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// NewBrokerFromConfig allocates and returns a Broker and its consumers using the given configuration, consumer
+// handlers are bound by name from the given registry.
+//
+// Options given explicitly override the configuration ones
+func NewBrokerFromConfig(cfg *Config, r *HandlerRegistry, opts ...Option) (*Broker, error) {
+	cfgOpts, err := cfg.Options()
+	if err != nil {
+		return nil, err
+	}
+	b := NewBroker(append(cfgOpts, opts...)...)
+	for _, cc := range cfg.Consumers {
+		c, err := cc.Consumer(r)
+		if err != nil {
+			return nil, fmt.Errorf("consumer %s: %w", consumerConfigName(cc), err)
+		}
+		b.EventMux.Add(c)
+	}
+	return b, nil
+}
+
+func consumerConfigName(c ConsumerConfig) string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return strings.Join(c.Topics, ",")
+}
+
+// HandlerRegistry binds Handler(s) and HandlerFunc(s) to names, so consumers can reference them from a Config
+type HandlerRegistry struct {
+	handlers     map[string]Handler
+	handlerFuncs map[string]HandlerFunc
+}
+
+// NewHandlerRegistry allocates and returns an empty HandlerRegistry
+func NewHandlerRegistry() *HandlerRegistry {
+	return &HandlerRegistry{
+		handlers:     make(map[string]Handler),
+		handlerFuncs: make(map[string]HandlerFunc),
+	}
+}
+
+// Register binds the given Handler to the given name
+func (r *HandlerRegistry) Register(name string, h Handler) *HandlerRegistry {
+	r.handlers[name] = h
+	return r
+}
+
+// RegisterFunc binds the given HandlerFunc to the given name
+func (r *HandlerRegistry) RegisterFunc(name string, f HandlerFunc) *HandlerRegistry {
+	r.handlerFuncs[name] = f
+	return r
+}
+
+func (r *HandlerRegistry) bind(name string, c *Consumer) error {
+	if r != nil {
+		if h, ok := r.handlers[name]; ok {
+			c.Handle(h)
+			return nil
+		} else if f, ok := r.handlerFuncs[name]; ok {
+			c.HandleFunc(f)
+			return nil
+		}
+	}
+	return fmt.Errorf("handler %q: %w", name, ErrHandlerNotFound)
+}
+
+// envReader reads typed environment variables, collecting parsing errors
+type envReader struct {
+	lookup func(string) (string, bool)
+	err    *multierror.Error
+}
+
+func (e *envReader) string(key string, dst *string) {
+	if v, ok := e.lookup(key); ok {
+		*dst = v
+	}
+}
+
+func (e *envReader) strings(key string, dst *[]string) {
+	if v, ok := e.lookup(key); ok {
+		*dst = strings.Split(v, ",")
+	}
+}
+
+func (e *envReader) int(key string, dst *int) {
+	if v, ok := e.lookup(key); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			e.err = multierror.Append(e.err, fmt.Errorf("%s: %w", key, err))
+			return
+		}
+		*dst = n
+	}
+}
+
+func (e *envReader) duration(key string, dst *Duration) {
+	if v, ok := e.lookup(key); ok {
+		if err := dst.UnmarshalText([]byte(v)); err != nil {
+			e.err = multierror.Append(e.err, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+}
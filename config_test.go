@@ -0,0 +1,169 @@
+package quark
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testingConfigYAML = `
+cluster: [localhost:9092]
+pool_size: 3
+retry_backoff: 500ms
+source: /payments
+startup_mode: best_effort
+restart:
+  strategy: one_for_all
+  max_restarts: 5
+  window: 30s
+provider:
+  kafka:
+    version: 2.8.0
+consumers:
+  - name: payments-audit
+    topics: [payments.0, payments.1]
+    group: audit
+    start_from: oldest
+    handler_timeout: 10s
+    handler: audit
+`
+
+const testingConfigJSON = `{
+  "cluster": ["localhost:9092"],
+  "pool_size": 3,
+  "consumers": [{"name": "chat", "topics": ["chat.0"], "handler": "audit"}]
+}`
+
+func TestParseConfig(t *testing.T) {
+	t.Run("Parse YAML config", func(t *testing.T) {
+		cfg, err := ParseConfig([]byte(testingConfigYAML))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"localhost:9092"}, cfg.Cluster)
+		assert.Equal(t, Duration(time.Millisecond*500), cfg.RetryBackoff)
+		assert.Equal(t, Duration(time.Second*30), cfg.Restart.Window)
+		assert.Equal(t, "audit", cfg.Consumers[0].Group)
+
+		section := struct {
+			Version string `yaml:"version"`
+		}{}
+		assert.Nil(t, cfg.DecodeProvider("kafka", &section))
+		assert.Equal(t, "2.8.0", section.Version)
+		assert.True(t, errors.Is(cfg.DecodeProvider("nats", &section), ErrProviderConfigNotFound))
+	})
+
+	t.Run("Parse JSON config", func(t *testing.T) {
+		cfg, err := ParseConfig([]byte(testingConfigJSON))
+		assert.Nil(t, err)
+		assert.Equal(t, 3, cfg.PoolSize)
+		assert.Equal(t, []string{"chat.0"}, cfg.Consumers[0].Topics)
+	})
+}
+
+func TestConfig_ApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"QUARK_CLUSTER":                               "a:9092,b:9092",
+		"QUARK_POOL_SIZE":                             "10",
+		"QUARK_CONSUMER_PAYMENTS_AUDIT_GROUP":         "audit-v2",
+		"QUARK_CONSUMER_PAYMENTS_AUDIT_RETRY_BACKOFF": "2s",
+	}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+	t.Run("Config environment overrides", func(t *testing.T) {
+		cfg, err := ParseConfig([]byte(testingConfigYAML))
+		assert.Nil(t, err)
+		assert.Nil(t, cfg.ApplyEnv(lookup))
+		assert.Equal(t, []string{"a:9092", "b:9092"}, cfg.Cluster)
+		assert.Equal(t, 10, cfg.PoolSize)
+		assert.Equal(t, "audit-v2", cfg.Consumers[0].Group)
+		assert.Equal(t, Duration(time.Second*2), cfg.Consumers[0].RetryBackoff)
+	})
+
+	t.Run("Config invalid environment value", func(t *testing.T) {
+		env["QUARK_MAX_RETRIES"] = "many"
+		defer delete(env, "QUARK_MAX_RETRIES")
+		assert.NotNil(t, new(Config).ApplyEnv(lookup))
+	})
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("Load config file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "quark")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "quark.json")
+		assert.Nil(t, ioutil.WriteFile(path, []byte(testingConfigJSON), 0600))
+		cfg, err := LoadConfig(path)
+		assert.Nil(t, err)
+		assert.Equal(t, "chat", cfg.Consumers[0].Name)
+
+		_, err = LoadConfig(filepath.Join(dir, "missing.yaml"))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestNewBrokerFromConfig(t *testing.T) {
+	audit := func(w EventWriter, e *Event) bool {
+		return true
+	}
+	t.Run("New broker from config", func(t *testing.T) {
+		cfg, err := ParseConfig([]byte(testingConfigYAML))
+		assert.Nil(t, err)
+		b, err := NewBrokerFromConfig(cfg, NewHandlerRegistry().RegisterFunc("audit", audit),
+			WithPoolSize(7))
+		assert.Nil(t, err)
+		assert.Equal(t, 7, b.PoolSize)
+		assert.Equal(t, time.Millisecond*500, b.RetryBackoff)
+		assert.Equal(t, StartupBestEffort, b.StartupMode)
+		assert.Equal(t, OneForAll, b.RestartPolicy.Strategy)
+		assert.Equal(t, "/payments", b.BaseMessageSource)
+
+		consumers := b.EventMux.Get("payments.1")
+		assert.Equal(t, 1, len(consumers))
+		assert.Equal(t, "audit", consumers[0].GetGroup())
+		assert.Equal(t, Oldest, consumers[0].GetStartFrom())
+		assert.Equal(t, time.Second*10, consumers[0].GetHandlerTimeout())
+		assert.NotNil(t, consumers[0].GetHandleFunc())
+	})
+
+	t.Run("New broker from config with unknown handler", func(t *testing.T) {
+		cfg, err := ParseConfig([]byte(testingConfigYAML))
+		assert.Nil(t, err)
+		_, err = NewBrokerFromConfig(cfg, NewHandlerRegistry())
+		assert.True(t, errors.Is(err, ErrHandlerNotFound))
+	})
+
+	t.Run("New broker from config with invalid values", func(t *testing.T) {
+		_, err := NewBrokerFromConfig(&Config{StartupMode: "eventually"}, nil)
+		assert.True(t, errors.Is(err, ErrInvalidConfig))
+	})
+}
+
+var parseStartPositionTestingSuite = []struct {
+	text     string
+	expected StartPosition
+	err      error
+}{
+	{"", StartPosition{}, nil},
+	{"newest", Newest, nil},
+	{"oldest", Oldest, nil},
+	{"42", Offset(42), nil},
+	{"2021-05-01T00:00:00Z", Timestamp(time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)), nil},
+	{"yesterday", StartPosition{}, ErrInvalidStartPosition},
+}
+
+func TestParseStartPosition(t *testing.T) {
+	for _, tt := range parseStartPositionTestingSuite {
+		t.Run("Parse start position", func(t *testing.T) {
+			p, err := ParseStartPosition(tt.text)
+			assert.True(t, errors.Is(err, tt.err))
+			assert.Equal(t, tt.expected, p)
+		})
+	}
+}
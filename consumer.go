@@ -26,6 +26,8 @@ type Consumer struct {
 	maxRetries int
 	// RetryBackoff time to wait between each retry
 	retryBackoff time.Duration
+	// HandlerTimeout maximum time a handler may take to serve an Event
+	handlerTimeout time.Duration
 	// Handler specific struct Quark will use to send messages
 	handler Handler
 	// HandlerFunc specific func Quark will use to send messages
@@ -46,6 +48,21 @@ type Consumer struct {
 	//
 	// e.g. application/avro, application/json, application/cloudevents+json
 	contentType string
+	// StartFrom position the Consumer starts consuming from
+	startFrom StartPosition
+	// RestartPolicy defines how the Supervisor restarts crashed workers
+	restartPolicy *RestartPolicy
+	// PanicPolicy defines how handler panics are handled
+	panicPolicy *PanicPolicy
+	// Publishes topics the Consumer handlers publish to, used for documentation purposes (e.g. AsyncAPI)
+	publishes []string
+}
+
+// NewConsumer allocates and returns a Consumer subscribed to the given topics.
+//
+// Useful to register consumers into a running Broker (see Broker.AddConsumer)
+func NewConsumer(topics ...string) *Consumer {
+	return new(Consumer).Topics(topics...)
 }
 
 // Topic A Broker will use this topic to subscribe the Consumer
@@ -86,6 +103,15 @@ func (c *Consumer) RetryBackoff(t time.Duration) *Consumer {
 	return c
 }
 
+// HandlerTimeout maximum time a handler may take to serve an Event, Event.Context carries the resulting deadline.
+//
+// Events exceeding it are reported and, if not acknowledged, retried using the retry policy. Overrides the Broker
+// timeout
+func (c *Consumer) HandlerTimeout(d time.Duration) *Consumer {
+	c.handlerTimeout = d
+	return c
+}
+
 // ProviderConfig Custom provider configuration (e.g. sarama config, aws credentials)
 func (c *Consumer) ProviderConfig(cfg interface{}) *Consumer {
 	c.providerConfig = cfg
@@ -157,6 +183,34 @@ func (c *Consumer) ContentType(t string) *Consumer {
 	return c
 }
 
+// StartFrom position the Consumer starts consuming from, accepts an Offset, Newest, Oldest or a Timestamp.
+//
+// Applied only to partitions without a position committed by the provider (e.g. a new Apache Kafka consumer group),
+// so process restarts, deploys and rebalances resume from the committed positions. Use a new consumer group to
+// consume again from the given position
+func (c *Consumer) StartFrom(p StartPosition) *Consumer {
+	c.startFrom = p
+	return c
+}
+
+// RestartPolicy defines how the Consumer Supervisor restarts crashed workers, overrides the Broker policy
+func (c *Consumer) RestartPolicy(p RestartPolicy) *Consumer {
+	c.restartPolicy = &p
+	return c
+}
+
+// PanicPolicy defines how the Consumer handles Events whose handler panicked, overrides the Broker policy
+func (c *Consumer) PanicPolicy(p PanicPolicy) *Consumer {
+	c.panicPolicy = &p
+	return c
+}
+
+// Publishes declares the topics the Consumer handlers publish to. Only used for documentation purposes (e.g. AsyncAPI)
+func (c *Consumer) Publishes(topics ...string) *Consumer {
+	c.publishes = append(c.publishes, topics...)
+	return c
+}
+
 // TopicString returns every topic registered into the current consumer as string
 func (c Consumer) TopicString() string {
 	topics := ""
@@ -189,3 +243,28 @@ func (c *Consumer) GetHandleFunc() HandlerFunc {
 func (c *Consumer) GetTopics() []string {
 	return c.topics
 }
+
+// GetStartFrom returns the current Consumer start position
+func (c *Consumer) GetStartFrom() StartPosition {
+	return c.startFrom
+}
+
+// GetPublishes returns the topics the Consumer handlers publish to
+func (c *Consumer) GetPublishes() []string {
+	return c.publishes
+}
+
+// GetRestartPolicy returns the current Consumer restart policy, nil if not defined
+func (c *Consumer) GetRestartPolicy() *RestartPolicy {
+	return c.restartPolicy
+}
+
+// GetHandlerTimeout returns the current Consumer handler timeout, zero if not defined
+func (c *Consumer) GetHandlerTimeout() time.Duration {
+	return c.handlerTimeout
+}
+
+// GetPanicPolicy returns the current Consumer panic policy, nil if not defined
+func (c *Consumer) GetPanicPolicy() *PanicPolicy {
+	return c.panicPolicy
+}
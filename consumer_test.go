@@ -155,6 +155,21 @@ func TestConsumer_Publisher(t *testing.T) {
 	})
 }
 
+func TestConsumer_StartFrom(t *testing.T) {
+	t.Run("Consumer start position mutation", func(t *testing.T) {
+		c := Consumer{}
+		assert.True(t, c.GetStartFrom().IsDefault())
+		c.StartFrom(Oldest)
+		assert.Equal(t, StartOldest, c.GetStartFrom().Kind)
+		c.StartFrom(Offset(30))
+		assert.Equal(t, int64(30), c.GetStartFrom().Offset)
+		now := time.Now()
+		c.StartFrom(Timestamp(now))
+		assert.Equal(t, StartTime, c.GetStartFrom().Kind)
+		assert.Equal(t, now, c.GetStartFrom().Time)
+	})
+}
+
 func BenchmarkConsumer(b *testing.B) {
 	topics := []string{"chat.0", "chat.1", "chat.2", "chat.3"}
 	b.Run("Consumer add topics", func(b *testing.B) {
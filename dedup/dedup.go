@@ -0,0 +1,148 @@
+// Package dedup Idempotent consumer mechanisms for Quark.
+//
+// Wraps a quark.Handler (or quark.HandlerFunc) to record processed messages in a Store and skip duplicates within a
+// configurable time window. Duplicates are acknowledged without calling the actual handler, so providers with
+// at-least-once semantics (e.g. Apache Kafka) or retried messages do not execute side-effects twice.
+//
+// Keys are claimed as in progress before calling the handler and recorded as processed only once it acknowledges
+// the message. Duplicates received while the message is in progress are not acknowledged, so the provider delivers
+// them again if the handler fails.
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+)
+
+// ClaimState state of a claimed message key
+type ClaimState uint8
+
+const (
+	// Claimed the key was claimed by the caller, its message must be processed
+	Claimed ClaimState = iota
+	// InProgress another delivery of the message is being processed
+	InProgress
+	// Processed the message was already processed within its window
+	Processed
+)
+
+// Store keeps track of processed message keys
+type Store interface {
+	// Claim atomically records the given key as in progress for the given lease if it was not recorded yet (or its
+	// lease or window passed), returns the current state of the key otherwise
+	Claim(ctx context.Context, key string, lease time.Duration) (ClaimState, error)
+	// Complete records the claimed key as processed for the given window
+	Complete(ctx context.Context, key string, window time.Duration) error
+	// Release removes the claim of the given key, so it is processed again
+	Release(ctx context.Context, key string) error
+}
+
+// KeyFunc returns the deduplication key of the given Event
+type KeyFunc func(*quark.Event) string
+
+// DefaultKey uses the Message Id as deduplication key, falls back to the quark-id header
+var DefaultKey KeyFunc = func(e *quark.Event) string {
+	if e.Body != nil && e.Body.Id != "" {
+		return e.Body.Id
+	}
+	return e.Header.Get(quark.HeaderMessageId)
+}
+
+var (
+	defaultWindow = time.Hour * 24
+	defaultLease  = time.Minute * 5
+)
+
+type deduplicator struct {
+	store   Store
+	options options
+}
+
+func newDeduplicator(s Store, opts ...Option) *deduplicator {
+	options := options{
+		window:  defaultWindow,
+		lease:   defaultLease,
+		keyFunc: DefaultKey,
+	}
+	for _, o := range opts {
+		o.apply(&options)
+	}
+	return &deduplicator{
+		store:   s,
+		options: options,
+	}
+}
+
+// NewHandler wraps the given Handler, skipping (and acknowledging) every Event already processed
+func NewHandler(s Store, next quark.Handler, opts ...Option) quark.Handler {
+	d := newDeduplicator(s, opts...)
+	return handler{
+		d:    d,
+		next: next.ServeEvent,
+	}
+}
+
+// NewHandlerFunc wraps the given HandlerFunc, skipping (and acknowledging) every Event already processed
+func NewHandlerFunc(s Store, next quark.HandlerFunc, opts ...Option) quark.HandlerFunc {
+	return handler{
+		d:    newDeduplicator(s, opts...),
+		next: next,
+	}.ServeEvent
+}
+
+type handler struct {
+	d    *deduplicator
+	next quark.HandlerFunc
+}
+
+func (h handler) ServeEvent(w quark.EventWriter, e *quark.Event) bool {
+	return h.d.serve(w, e, h.next)
+}
+
+func (d *deduplicator) serve(w quark.EventWriter, e *quark.Event, next quark.HandlerFunc) bool {
+	ctx := e.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	key := d.options.keyFunc(e)
+	if key == "" {
+		return next(w, e) // nothing to deduplicate with
+	}
+	key = d.options.namespace + key
+
+	state, err := d.store.Claim(ctx, key, d.options.lease)
+	if err != nil {
+		// fail-open, processing twice is preferred over losing a message
+		d.handleError(ctx, fmt.Errorf("dedup: claim %s: %w", key, err))
+		return next(w, e)
+	} else if state == InProgress {
+		return false // redelivered later, acknowledged once the in progress delivery is processed
+	} else if state == Processed {
+		return true
+	}
+
+	ack := false
+	defer func() {
+		if ack {
+			if errComplete := d.store.Complete(ctx, key, d.options.window); errComplete != nil {
+				d.handleError(ctx, fmt.Errorf("dedup: complete %s: %w", key, errComplete))
+			}
+			return
+		}
+		// not acknowledged (or panicked), the redelivered message must be processed again
+		if errRelease := d.store.Release(ctx, key); errRelease != nil {
+			d.handleError(ctx, fmt.Errorf("dedup: release %s: %w", key, errRelease))
+		}
+	}()
+	ack = next(w, e)
+	return ack
+}
+
+func (d *deduplicator) handleError(ctx context.Context, err error) {
+	if d.options.errHandler != nil {
+		d.options.errHandler(ctx, err)
+	}
+}
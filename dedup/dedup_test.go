@@ -0,0 +1,149 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubHandler struct {
+	calls int32
+	ack   bool
+}
+
+func (h *stubHandler) ServeEvent(quark.EventWriter, *quark.Event) bool {
+	atomic.AddInt32(&h.calls, 1)
+	return h.ack
+}
+
+type failingStore struct{}
+
+var errStubStore = errors.New("generic stub store error")
+
+func (failingStore) Claim(context.Context, string, time.Duration) (ClaimState, error) {
+	return InProgress, errStubStore
+}
+
+func (failingStore) Complete(context.Context, string, time.Duration) error {
+	return errStubStore
+}
+
+func (failingStore) Release(context.Context, string) error {
+	return errStubStore
+}
+
+func newStubEvent(id string) *quark.Event {
+	return &quark.Event{
+		Context: context.Background(),
+		Topic:   "payments.charged",
+		Header:  quark.Header{},
+		Body:    quark.NewMessage(id, "payments.charged", nil),
+	}
+}
+
+func TestNewHandler(t *testing.T) {
+	t.Run("Deduplicate acknowledged events", func(t *testing.T) {
+		next := &stubHandler{ack: true}
+		h := NewHandler(NewMemoryStore(10), next)
+		assert.True(t, h.ServeEvent(nil, newStubEvent("1")))
+		assert.True(t, h.ServeEvent(nil, newStubEvent("1")))
+		assert.True(t, h.ServeEvent(nil, newStubEvent("2")))
+		assert.Equal(t, int32(2), next.calls)
+	})
+
+	t.Run("Do not record negative acknowledged events", func(t *testing.T) {
+		next := &stubHandler{ack: false}
+		h := NewHandler(NewMemoryStore(10), next)
+		assert.False(t, h.ServeEvent(nil, newStubEvent("1")))
+		assert.False(t, h.ServeEvent(nil, newStubEvent("1")))
+		assert.Equal(t, int32(2), next.calls)
+	})
+
+	t.Run("Deduplicate using namespaces", func(t *testing.T) {
+		s := NewMemoryStore(10)
+		next := &stubHandler{ack: true}
+		hA := NewHandler(s, next, WithNamespace("a:"))
+		hB := NewHandler(s, next, WithNamespace("b:"))
+		hA.ServeEvent(nil, newStubEvent("1"))
+		hB.ServeEvent(nil, newStubEvent("1"))
+		assert.Equal(t, int32(2), next.calls)
+	})
+
+	t.Run("Fail-open when store fails", func(t *testing.T) {
+		next := &stubHandler{ack: true}
+		errs := 0
+		h := NewHandler(failingStore{}, next, WithErrorHandler(func(_ context.Context, err error) {
+			assert.True(t, errors.Is(err, errStubStore))
+			errs++
+		}))
+		assert.True(t, h.ServeEvent(nil, newStubEvent("1")))
+		assert.Equal(t, int32(1), next.calls)
+		assert.Equal(t, 1, errs)
+	})
+
+	t.Run("Release claims of panicking handlers", func(t *testing.T) {
+		s := NewMemoryStore(10)
+		h := NewHandlerFunc(s, func(quark.EventWriter, *quark.Event) bool {
+			panic("handler panic")
+		})
+		assert.Panics(t, func() { h(nil, newStubEvent("1")) })
+		assert.Equal(t, 0, s.Len())
+	})
+
+	t.Run("Process concurrent duplicates once", func(t *testing.T) {
+		next := &stubHandler{ack: true}
+		h := NewHandler(NewMemoryStore(10), next)
+		wg := sync.WaitGroup{}
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				h.ServeEvent(nil, newStubEvent("1")) // in progress duplicates are not acknowledged
+			}()
+		}
+		wg.Wait()
+		assert.True(t, h.ServeEvent(nil, newStubEvent("1")))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&next.calls))
+	})
+
+	t.Run("Do not acknowledge duplicates of in progress events", func(t *testing.T) {
+		s := NewMemoryStore(10)
+		inProgress, release := make(chan struct{}), make(chan bool)
+		h := NewHandlerFunc(s, func(quark.EventWriter, *quark.Event) bool {
+			close(inProgress)
+			return <-release
+		})
+		done := make(chan bool)
+		go func() { done <- h(nil, newStubEvent("1")) }()
+		<-inProgress
+		assert.False(t, h(nil, newStubEvent("1")))
+		release <- false // original delivery failed, the duplicate is processed on its redelivery
+		assert.False(t, <-done)
+
+		next := &stubHandler{ack: true}
+		h = NewHandler(s, next).ServeEvent
+		assert.True(t, h(nil, newStubEvent("1")))
+		assert.Equal(t, int32(1), next.calls)
+	})
+}
+
+func TestNewHandlerFunc(t *testing.T) {
+	t.Run("Deduplicate using custom key", func(t *testing.T) {
+		calls := 0
+		h := NewHandlerFunc(NewMemoryStore(10), func(quark.EventWriter, *quark.Event) bool {
+			calls++
+			return true
+		}, WithKeyFunc(func(e *quark.Event) string {
+			return e.Body.Type
+		}))
+		h(nil, newStubEvent("1"))
+		h(nil, newStubEvent("2"))
+		assert.Equal(t, 1, calls)
+	})
+}
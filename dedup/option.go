@@ -0,0 +1,91 @@
+package dedup
+
+import (
+	"time"
+
+	"github.com/neutrinocorp/quark"
+)
+
+// Option is a unit of configuration of a deduplication handler
+type Option interface {
+	apply(*options)
+}
+
+type options struct {
+	window     time.Duration
+	lease      time.Duration
+	keyFunc    KeyFunc
+	namespace  string
+	errHandler quark.ErrorHandler
+}
+
+type windowOption time.Duration
+
+func (o windowOption) apply(opts *options) {
+	opts.window = time.Duration(o)
+}
+
+// WithWindow defines the time a processed key is kept, duplicates received after this window are processed again
+func WithWindow(d time.Duration) Option {
+	if d <= 0 {
+		return windowOption(defaultWindow)
+	}
+	return windowOption(d)
+}
+
+type leaseOption time.Duration
+
+func (o leaseOption) apply(opts *options) {
+	opts.lease = time.Duration(o)
+}
+
+// WithLease defines the time a key is kept in progress while its message is processed, the message is processed
+// again if the handler did not acknowledge it within the lease (e.g. the process crashed). Must be greater than the
+// handler timeout
+func WithLease(d time.Duration) Option {
+	if d <= 0 {
+		return leaseOption(defaultLease)
+	}
+	return leaseOption(d)
+}
+
+type keyFuncOption struct {
+	Func KeyFunc
+}
+
+func (o keyFuncOption) apply(opts *options) {
+	if o.Func != nil {
+		opts.keyFunc = o.Func
+	}
+}
+
+// WithKeyFunc defines a custom deduplication key (e.g. a business identifier inside the payload)
+func WithKeyFunc(f KeyFunc) Option {
+	return keyFuncOption{Func: f}
+}
+
+type namespaceOption string
+
+func (o namespaceOption) apply(opts *options) {
+	opts.namespace = string(o)
+}
+
+// WithNamespace prefixes every key, useful to share a Store between multiple consumer groups
+//
+//	e.g. "payments-group:"
+func WithNamespace(ns string) Option {
+	return namespaceOption(ns)
+}
+
+type errHandlerOption struct {
+	Handler quark.ErrorHandler
+}
+
+func (o errHandlerOption) apply(opts *options) {
+	opts.errHandler = o.Handler
+}
+
+// WithErrorHandler defines an error hook executed when the Store fails
+func WithErrorHandler(h quark.ErrorHandler) Option {
+	return errHandlerOption{Handler: h}
+}
@@ -0,0 +1,103 @@
+package dedup
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+var defaultMemoryCapacity = 10000
+
+// MemoryStore in-memory least recently used (LRU) Store.
+//
+// When capacity is reached, the least recently marked key is evicted even if its window has not passed yet.
+type MemoryStore struct {
+	capacity int
+	entries  map[string]*list.Element
+	lru      *list.List
+	mu       sync.Mutex
+}
+
+type memoryEntry struct {
+	key       string
+	expiresAt time.Time
+	processed bool
+}
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore allocates and returns a MemoryStore holding up to capacity keys
+func NewMemoryStore(capacity int) *MemoryStore {
+	if capacity <= 0 {
+		capacity = defaultMemoryCapacity
+	}
+	return &MemoryStore{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		lru:      list.New(),
+		mu:       sync.Mutex{},
+	}
+}
+
+// Claim atomically records the given key as in progress for the given lease if it was not recorded yet (or its
+// lease or window passed), returns the current state of the key otherwise
+func (s *MemoryStore) Claim(_ context.Context, key string, lease time.Duration) (ClaimState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if el, ok := s.entries[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		s.lru.MoveToFront(el)
+		if now.Before(entry.expiresAt) && entry.processed {
+			return Processed, nil
+		} else if now.Before(entry.expiresAt) {
+			return InProgress, nil
+		}
+		entry.expiresAt, entry.processed = now.Add(lease), false
+		return Claimed, nil
+	}
+
+	s.putLocked(&memoryEntry{key: key, expiresAt: now.Add(lease)})
+	return Claimed, nil
+}
+
+// Complete records the claimed key as processed for the given window
+func (s *MemoryStore) Complete(_ context.Context, key string, window time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[key]; ok {
+		s.removeElement(el)
+	}
+	s.putLocked(&memoryEntry{key: key, expiresAt: time.Now().Add(window), processed: true})
+	return nil
+}
+
+// Release removes the claim of the given key, so it is processed again
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[key]; ok {
+		s.removeElement(el)
+	}
+	return nil
+}
+
+// Len returns the current number of keys
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
+
+func (s *MemoryStore) putLocked(entry *memoryEntry) {
+	s.entries[entry.key] = s.lru.PushFront(entry)
+	for s.lru.Len() > s.capacity {
+		s.removeElement(s.lru.Back())
+	}
+}
+
+func (s *MemoryStore) removeElement(el *list.Element) {
+	s.lru.Remove(el)
+	delete(s.entries, el.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,65 @@
+package dedup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Memory store lease and window expiration", func(t *testing.T) {
+		s := NewMemoryStore(10)
+		state, _ := s.Claim(ctx, "1", time.Millisecond*10)
+		assert.Equal(t, Claimed, state)
+		state, _ = s.Claim(ctx, "1", time.Millisecond*10)
+		assert.Equal(t, InProgress, state)
+		time.Sleep(time.Millisecond * 15)
+		state, _ = s.Claim(ctx, "1", time.Minute)
+		assert.Equal(t, Claimed, state)
+
+		assert.Nil(t, s.Complete(ctx, "1", time.Millisecond*10))
+		state, _ = s.Claim(ctx, "1", time.Minute)
+		assert.Equal(t, Processed, state)
+		time.Sleep(time.Millisecond * 15)
+		state, _ = s.Claim(ctx, "1", time.Minute)
+		assert.Equal(t, Claimed, state)
+		assert.Equal(t, 1, s.Len())
+	})
+
+	t.Run("Memory store least recently used eviction", func(t *testing.T) {
+		s := NewMemoryStore(2)
+		_, _ = s.Claim(ctx, "1", time.Minute)
+		_, _ = s.Claim(ctx, "2", time.Minute)
+		_, _ = s.Claim(ctx, "1", time.Minute) // 1 is now the most recently used
+		_, _ = s.Claim(ctx, "3", time.Minute)
+		assert.Equal(t, 2, s.Len())
+		state, _ := s.Claim(ctx, "1", time.Minute)
+		assert.Equal(t, InProgress, state)
+		state, _ = s.Claim(ctx, "2", time.Minute)
+		assert.Equal(t, Claimed, state)
+	})
+
+	t.Run("Memory store release", func(t *testing.T) {
+		s := NewMemoryStore(10)
+		_, _ = s.Claim(ctx, "1", time.Minute)
+		assert.Nil(t, s.Release(ctx, "1"))
+		assert.Nil(t, s.Release(ctx, "2"))
+		state, _ := s.Claim(ctx, "1", time.Minute)
+		assert.Equal(t, Claimed, state)
+	})
+}
+
+func BenchmarkMemoryStore(b *testing.B) {
+	ctx := context.Background()
+	b.Run("Memory store claim and release", func(b *testing.B) {
+		s := NewMemoryStore(100)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = s.Claim(ctx, "foo", time.Minute)
+			_ = s.Release(ctx, "foo")
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package dedup
+
+import (
+	"context"
+	"time"
+)
+
+// RedisClient minimal Redis command set required by RedisStore.
+//
+// Keeps Quark free of a specific Redis driver, wrap your client of choice (e.g. go-redis) to satisfy it.
+type RedisClient interface {
+	// SetNX stores the given key with an expiration only if it does not exist (SET key value NX PX ttl), returns
+	// false if the key already exists
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	// Set stores the given key with an expiration (SET key value PX ttl)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	// Get returns the value of the given key (GET key), an empty string if the key does not exist
+	Get(ctx context.Context, key string) (string, error)
+	// Del removes the given key (DEL key)
+	Del(ctx context.Context, key string) error
+}
+
+const (
+	redisInProgress = "in_progress"
+	redisProcessed  = "processed"
+)
+
+// RedisStore Store backed by Redis, windows are handled by Redis key expiration
+type RedisStore struct {
+	Client RedisClient
+	Prefix string
+}
+
+var _ Store = RedisStore{}
+
+// NewRedisStore allocates and returns a RedisStore, every key is prefixed with "quark:dedup:"
+func NewRedisStore(c RedisClient) RedisStore {
+	return RedisStore{
+		Client: c,
+		Prefix: "quark:dedup:",
+	}
+}
+
+// Claim atomically records the given key as in progress for the given lease if it was not recorded yet (or its
+// lease or window passed), returns the current state of the key otherwise
+func (s RedisStore) Claim(ctx context.Context, key string, lease time.Duration) (ClaimState, error) {
+	claimed, err := s.Client.SetNX(ctx, s.Prefix+key, redisInProgress, lease)
+	if err != nil {
+		return InProgress, err
+	} else if claimed {
+		return Claimed, nil
+	}
+	value, err := s.Client.Get(ctx, s.Prefix+key)
+	if err != nil {
+		return InProgress, err
+	} else if value == redisProcessed {
+		return Processed, nil
+	}
+	return InProgress, nil // still in progress or released meanwhile, redelivered later
+}
+
+// Complete records the claimed key as processed for the given window
+func (s RedisStore) Complete(ctx context.Context, key string, window time.Duration) error {
+	return s.Client.Set(ctx, s.Prefix+key, redisProcessed, window)
+}
+
+// Release removes the claim of the given key, so it is processed again
+func (s RedisStore) Release(ctx context.Context, key string) error {
+	return s.Client.Del(ctx, s.Prefix+key)
+}
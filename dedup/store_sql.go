@@ -0,0 +1,116 @@
+package dedup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/neutrinocorp/quark/dialect"
+)
+
+// DefaultTable default deduplication table name
+const DefaultTable = "quark_processed_messages"
+
+// SQLStore Store backed by a database/sql compatible database (e.g. Postgres, SQLite, MySQL)
+//
+// Expired keys are not removed automatically, call Purge periodically to keep the table small.
+type SQLStore struct {
+	DB      *sql.DB
+	Dialect dialect.Dialect
+	Table   string
+}
+
+var _ Store = &SQLStore{}
+
+// NewSQLStore allocates and returns a SQLStore using the DefaultTable
+func NewSQLStore(db *sql.DB, d dialect.Dialect) *SQLStore {
+	return &SQLStore{
+		DB:      db,
+		Dialect: d,
+		Table:   DefaultTable,
+	}
+}
+
+// Migrate creates the deduplication table if it does not exist
+func (s *SQLStore) Migrate(ctx context.Context) error {
+	_, err := s.DB.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+s.setDefaultTable()+` (
+	message_key VARCHAR(255) NOT NULL PRIMARY KEY,
+	processed SMALLINT NOT NULL,
+	expires_at TIMESTAMP NOT NULL
+)`)
+	return err
+}
+
+// Claim atomically records the given key as in progress for the given lease if it was not recorded yet (or its
+// lease or window passed), returns the current state of the key otherwise.
+//
+// The key primary key decides between concurrent claims, only one insert succeeds.
+func (s *SQLStore) Claim(ctx context.Context, key string, lease time.Duration) (ClaimState, error) {
+	now := time.Now().UTC()
+	expired := s.Dialect.Rebind(`DELETE FROM ` + s.setDefaultTable() + ` WHERE message_key = ? AND expires_at <= ?`)
+	if _, err := s.DB.ExecContext(ctx, expired, key, now); err != nil {
+		return InProgress, err
+	}
+
+	query := `INSERT INTO ` + s.setDefaultTable() + ` (message_key, processed, expires_at) VALUES (?, 0, ?) `
+	if s.Dialect == dialect.MySQL {
+		// unlike INSERT IGNORE, only duplicated keys are ignored (0 rows affected), other errors are returned
+		query += `ON DUPLICATE KEY UPDATE message_key = message_key`
+	} else {
+		query += `ON CONFLICT (message_key) DO NOTHING`
+	}
+	res, err := s.DB.ExecContext(ctx, s.Dialect.Rebind(query), key, now.Add(lease))
+	if err != nil {
+		return InProgress, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return InProgress, err
+	} else if n == 1 {
+		return Claimed, nil
+	}
+
+	processed := 0
+	err = s.DB.QueryRowContext(ctx, s.Dialect.Rebind(`SELECT processed FROM `+s.setDefaultTable()+
+		` WHERE message_key = ?`), key).Scan(&processed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return InProgress, nil // released meanwhile, redelivered later
+	} else if err != nil {
+		return InProgress, err
+	} else if processed == 1 {
+		return Processed, nil
+	}
+	return InProgress, nil
+}
+
+// Complete records the claimed key as processed for the given window
+func (s *SQLStore) Complete(ctx context.Context, key string, window time.Duration) error {
+	query := s.Dialect.Rebind(`UPDATE ` + s.setDefaultTable() + ` SET processed = 1, expires_at = ? WHERE message_key = ?`)
+	_, err := s.DB.ExecContext(ctx, query, time.Now().UTC().Add(window), key)
+	return err
+}
+
+// Release removes the claim of the given key, so it is processed again
+func (s *SQLStore) Release(ctx context.Context, key string) error {
+	query := s.Dialect.Rebind(`DELETE FROM ` + s.setDefaultTable() + ` WHERE message_key = ?`)
+	_, err := s.DB.ExecContext(ctx, query, key)
+	return err
+}
+
+// Purge removes every expired key
+func (s *SQLStore) Purge(ctx context.Context) (int64, error) {
+	query := s.Dialect.Rebind(`DELETE FROM ` + s.setDefaultTable() + ` WHERE expires_at <= ?`)
+	res, err := s.DB.ExecContext(ctx, query, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *SQLStore) setDefaultTable() string {
+	if s.Table != "" {
+		return s.Table
+	}
+	return DefaultTable
+}
@@ -0,0 +1,79 @@
+package dedup
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/quark/dialect"
+	"github.com/neutrinocorp/quark/internal/sqltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLiteTestingStore(t *testing.T) *SQLStore {
+	s := NewSQLStore(sqltest.Open(t, "dedup.db"), dialect.SQLite)
+	require.Nil(t, s.Migrate(context.Background()))
+	return s
+}
+
+func TestSQLStore(t *testing.T) {
+	ctx := context.Background()
+	t.Run("SQL store lease and window expiration", func(t *testing.T) {
+		s := newSQLiteTestingStore(t)
+		state, err := s.Claim(ctx, "1", time.Millisecond*50)
+		assert.Nil(t, err)
+		assert.Equal(t, Claimed, state)
+		state, err = s.Claim(ctx, "1", time.Millisecond*50)
+		assert.Nil(t, err)
+		assert.Equal(t, InProgress, state)
+		time.Sleep(time.Millisecond * 60)
+		state, err = s.Claim(ctx, "1", time.Minute)
+		assert.Nil(t, err)
+		assert.Equal(t, Claimed, state)
+
+		assert.Nil(t, s.Complete(ctx, "1", time.Millisecond*50))
+		state, err = s.Claim(ctx, "1", time.Minute)
+		assert.Nil(t, err)
+		assert.Equal(t, Processed, state)
+		time.Sleep(time.Millisecond * 60)
+		state, err = s.Claim(ctx, "1", time.Minute)
+		assert.Nil(t, err)
+		assert.Equal(t, Claimed, state)
+	})
+
+	t.Run("SQL store release and purge", func(t *testing.T) {
+		s := newSQLiteTestingStore(t)
+		_, _ = s.Claim(ctx, "1", time.Minute)
+		assert.Nil(t, s.Release(ctx, "1"))
+		state, err := s.Claim(ctx, "1", time.Minute)
+		assert.Nil(t, err)
+		assert.Equal(t, Claimed, state)
+
+		_, _ = s.Claim(ctx, "2", -time.Minute)
+		n, err := s.Purge(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+	})
+
+	t.Run("SQL store concurrent claims", func(t *testing.T) {
+		s := newSQLiteTestingStore(t)
+		claims := int32(0)
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				state, err := s.Claim(ctx, "1", time.Minute)
+				assert.Nil(t, err)
+				if state == Claimed {
+					atomic.AddInt32(&claims, 1)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), claims)
+	})
+}
@@ -0,0 +1,119 @@
+// Package dialect SQL dialects supported by Quark's database-backed components (e.g. transactional outbox,
+// deduplication stores, event stores).
+//
+// Queries inside Quark are written using the question mark (?) bind variable; a Dialect rewrites them to the
+// bind variable form its database engine expects.
+package dialect
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Dialect SQL database engine flavour
+type Dialect int
+
+const (
+	// SQLite uses question mark (?) bind variables and INTEGER PRIMARY KEY AUTOINCREMENT sequences
+	SQLite Dialect = iota
+	// Postgres uses ordinal ($1, $2, ..., $N) bind variables and BIGSERIAL sequences
+	Postgres
+	// MySQL uses question mark (?) bind variables and AUTO_INCREMENT sequences
+	MySQL
+)
+
+// String returns the dialect name
+func (d Dialect) String() string {
+	switch d {
+	case SQLite:
+		return "sqlite"
+	case Postgres:
+		return "postgres"
+	case MySQL:
+		return "mysql"
+	default:
+		return "unknown"
+	}
+}
+
+// Rebind rewrites every question mark (?) bind variable from the given query into the dialect form.
+//
+// Question marks inside quoted string literals ('...') and identifiers ("...") are kept as they are.
+func (d Dialect) Rebind(query string) string {
+	if d != Postgres {
+		return query
+	}
+
+	b := strings.Builder{}
+	b.Grow(len(query) + 8)
+	n := 0
+	var quote rune // current quote character, zero if outside a quoted section
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0 // escaped quotes ('') close and open a section again
+			}
+			b.WriteRune(r)
+			continue
+		case r == '\'' || r == '"':
+			quote = r
+			b.WriteRune(r)
+			continue
+		case r != '?':
+			b.WriteRune(r)
+			continue
+		}
+		n++
+		b.WriteByte('$')
+		b.WriteString(strconv.Itoa(n))
+	}
+	return b.String()
+}
+
+// TransactionalDDL verifies if schema changes (e.g. CREATE, DROP, ALTER TABLE) are rolled back along with their
+// transaction. MySQL commits every DDL statement implicitly
+func (d Dialect) TransactionalDDL() bool {
+	return d != MySQL
+}
+
+// sqlStateError driver error exposing its SQLSTATE code (e.g. pgx, lib/pq)
+type sqlStateError interface {
+	SQLState() string
+}
+
+// IsUniqueViolation verifies if the given driver error reports a unique (or primary) key constraint violation.
+//
+// Quark does not depend on specific drivers, errors are detected using their SQLSTATE code when available and
+// the engine error message otherwise.
+func (d Dialect) IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	var stateErr sqlStateError
+	if d == Postgres && errors.As(err, &stateErr) {
+		return stateErr.SQLState() == "23505"
+	}
+	msg := err.Error()
+	switch d {
+	case Postgres:
+		return strings.Contains(msg, "23505") || strings.Contains(msg, "violates unique constraint")
+	case MySQL:
+		return strings.Contains(msg, "Error 1062") || strings.Contains(msg, "Duplicate entry")
+	default:
+		return strings.Contains(msg, "UNIQUE constraint failed")
+	}
+}
+
+// SerialPrimaryKey returns the column definition of an auto-incremented 64-bit primary key
+func (d Dialect) SerialPrimaryKey() string {
+	switch d {
+	case Postgres:
+		return "BIGSERIAL PRIMARY KEY"
+	case MySQL:
+		return "BIGINT AUTO_INCREMENT PRIMARY KEY"
+	default:
+		return "INTEGER PRIMARY KEY AUTOINCREMENT"
+	}
+}
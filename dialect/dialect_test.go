@@ -0,0 +1,77 @@
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var dialectRebindTestingSuite = []struct {
+	d     Dialect
+	query string
+	exp   string
+}{
+	{SQLite, "SELECT id FROM foo WHERE a = ? AND b = ?", "SELECT id FROM foo WHERE a = ? AND b = ?"},
+	{MySQL, "SELECT id FROM foo WHERE a = ?", "SELECT id FROM foo WHERE a = ?"},
+	{Postgres, "SELECT id FROM foo WHERE a = ? AND b = ?", "SELECT id FROM foo WHERE a = $1 AND b = $2"},
+	{Postgres, "SELECT id FROM foo", "SELECT id FROM foo"},
+	{Postgres, "SELECT id FROM foo WHERE a = '?' AND b = ?", "SELECT id FROM foo WHERE a = '?' AND b = $1"},
+	{Postgres, "SELECT \"a?\" FROM foo WHERE b = 'it''s ?' AND c = ?",
+		"SELECT \"a?\" FROM foo WHERE b = 'it''s ?' AND c = $1"},
+}
+
+func TestDialect_Rebind(t *testing.T) {
+	for _, tt := range dialectRebindTestingSuite {
+		t.Run("Dialect rebind "+tt.d.String(), func(t *testing.T) {
+			assert.Equal(t, tt.exp, tt.d.Rebind(tt.query))
+		})
+	}
+}
+
+func TestDialect_TransactionalDDL(t *testing.T) {
+	t.Run("Dialect transactional ddl", func(t *testing.T) {
+		assert.True(t, SQLite.TransactionalDDL())
+		assert.True(t, Postgres.TransactionalDDL())
+		assert.False(t, MySQL.TransactionalDDL())
+	})
+}
+
+type stubStateError string
+
+func (e stubStateError) Error() string { return "stub driver error" }
+
+func (e stubStateError) SQLState() string { return string(e) }
+
+var dialectUniqueViolationTestingSuite = []struct {
+	d   Dialect
+	err error
+	exp bool
+}{
+	{SQLite, nil, false},
+	{SQLite, errors.New("UNIQUE constraint failed: quark_events.stream_id, quark_events.version"), true},
+	{SQLite, errors.New("database is locked"), false},
+	{Postgres, stubStateError("23505"), true},
+	{Postgres, fmt.Errorf("insert: %w", stubStateError("40001")), false},
+	{Postgres, errors.New(`pq: duplicate key value violates unique constraint "quark_events_pkey"`), true},
+	{MySQL, errors.New("Error 1062 (23000): Duplicate entry 'order-1-1' for key 'PRIMARY'"), true},
+	{MySQL, errors.New("Error 1213 (40001): Deadlock found when trying to get lock"), false},
+}
+
+func TestDialect_IsUniqueViolation(t *testing.T) {
+	for _, tt := range dialectUniqueViolationTestingSuite {
+		t.Run("Dialect unique violation "+tt.d.String(), func(t *testing.T) {
+			assert.Equal(t, tt.exp, tt.d.IsUniqueViolation(tt.err))
+		})
+	}
+}
+
+func BenchmarkDialect_Rebind(b *testing.B) {
+	b.Run("Dialect rebind postgres", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = Postgres.Rebind("SELECT id FROM foo WHERE a = ? AND b = ?")
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package quark
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Dispatch serves the given Event using the handler function (e.g. Handler.ServeEvent), recovering handler panics
+// based on the Consumer PanicPolicy. Returns the Event acknowledgement.
+//
+// If the Consumer has a handler timeout, the Event context carries a deadline; Events exceeding it are reported to
+// the Broker ErrorHandler. Slow Events the handler did not acknowledge are retried (see WriteRetryError),
+// acknowledged ones already produced their side-effects so they are not processed again.
+//
+// Recovered panics are reported to the Broker ErrorHandler as PanicError(s) and counted in the Supervisor status.
+// Providers must dispatch every Event through this method
+func (n *Supervisor) Dispatch(serve HandlerFunc, w EventWriter, e *Event) bool {
+	timeout := n.setDefaultHandlerTimeout()
+	if timeout <= 0 {
+		return n.serve(serve, w, e)
+	}
+	parent := e.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	e.Context = ctx
+	start := time.Now()
+	ack := n.serve(serve, w, e)
+	e.Context = parent
+	if ctx.Err() != context.DeadlineExceeded || parent.Err() != nil {
+		return ack // parent context closed (e.g. partition revoked), not a slow handler
+	}
+	atomic.AddInt64(&n.timeouts, 1)
+	errTimeout := fmt.Errorf("topic %s: %w (timeout %s, took %s)", e.Topic, ErrHandlerTimeout, timeout,
+		time.Since(start).Round(time.Millisecond))
+	n.reportEventError(e, errTimeout)
+	if ack {
+		return true
+	}
+	return n.retry(w, e, errTimeout)
+}
+
+func (n *Supervisor) serve(serve HandlerFunc, w EventWriter, e *Event) (ack bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ack = n.recoverPanic(r, w, e)
+		}
+	}()
+	return serve(w, e)
+}
+
+// retry publishes the failed Event body again following the retry policy, the Event is not acknowledged if it could
+// not be retried (e.g. redelivered too much)
+func (n *Supervisor) retry(w EventWriter, e *Event, cause error) bool {
+	if err := WriteRetryError(e.Context, w, e.Body, cause); err != nil {
+		n.reportEventError(e, fmt.Errorf("topic %s: retry failed event: %w", e.Topic, err))
+		return false
+	}
+	return true
+}
+
+func (n *Supervisor) reportEventError(e *Event, err error) {
+	if n.Broker.ErrorHandler != nil {
+		n.Broker.ErrorHandler(e.Context, err)
+	}
+}
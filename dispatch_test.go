@@ -0,0 +1,96 @@
+package quark
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func blockingHandler(ack bool) HandlerFunc {
+	return func(_ EventWriter, e *Event) bool {
+		<-e.Context.Done()
+		return ack
+	}
+}
+
+var dispatchTimeoutTestingSuite = []struct {
+	description     string
+	brokerTimeout   time.Duration
+	consumerTimeout time.Duration
+	redelivery      int
+	handler         HandlerFunc
+	expAck          bool
+	expTimeouts     int64
+	expRetried      bool
+}{
+	{"Serve event without timeout", 0, 0, 0, func(EventWriter, *Event) bool { return true }, true, 0, false},
+	{"Serve event within timeout", 0, time.Second, 0, func(_ EventWriter, e *Event) bool {
+		_, ok := e.Context.Deadline()
+		return ok
+	}, true, 0, false},
+	{"Retry event exceeding consumer timeout", time.Hour, time.Millisecond * 5, 0, blockingHandler(false), true, 1,
+		true},
+	{"Retry event exceeding broker timeout", time.Millisecond * 5, 0, 0, blockingHandler(false), true, 1, true},
+	{"Report acknowledged event exceeding timeout", 0, time.Millisecond * 5, 0, blockingHandler(true), true, 1, false},
+	{"Report slow acknowledged event ignoring its context", 0, time.Millisecond * 5, 0, func(EventWriter,
+		*Event) bool {
+		time.Sleep(time.Millisecond * 15)
+		return true
+	}, true, 1, false},
+	{"Nack event exceeding timeout redelivered too much", 0, time.Millisecond * 5, 5, blockingHandler(false), false,
+		1, false},
+}
+
+func TestSupervisor_DispatchTimeout(t *testing.T) {
+	for _, tt := range dispatchTimeoutTestingSuite {
+		t.Run(tt.description, func(t *testing.T) {
+			var reported []error
+			b := NewBroker(WithHandlerTimeout(tt.brokerTimeout), WithErrorHandler(func(_ context.Context, err error) {
+				reported = append(reported, err)
+			}))
+			c := NewConsumer("foo").HandlerTimeout(tt.consumerTimeout).RetryBackoff(time.Millisecond)
+			p := &recordingPublisher{}
+			s := newSupervisor(b, c)
+			body := NewMessage("123", "foo", []byte("bar"))
+			body.Metadata.RedeliveryCount = tt.redelivery
+			ctx := context.Background()
+			e := &Event{Context: ctx, Topic: "foo", Body: body}
+
+			ack := s.Dispatch(tt.handler, newEventWriter(s, p), e)
+			assert.Equal(t, tt.expAck, ack)
+			assert.Equal(t, ctx, e.Context)
+			assert.Equal(t, tt.expTimeouts, s.Status().Timeouts)
+			if tt.expTimeouts > 0 {
+				require.NotEmpty(t, reported)
+				assert.True(t, errors.Is(reported[0], ErrHandlerTimeout))
+			} else {
+				assert.Empty(t, reported)
+			}
+			if !tt.expRetried {
+				assert.Empty(t, p.published)
+				return
+			}
+			require.Len(t, p.published, 1)
+			f, ok := GetFailure(p.published[0])
+			assert.True(t, ok)
+			assert.Equal(t, "*errors.errorString", f.ErrorType)
+			assert.Contains(t, f.Error, ErrHandlerTimeout.Error())
+		})
+	}
+
+	t.Run("Ignore timeout of closed event context", func(t *testing.T) {
+		s := newSupervisor(NewBroker(), NewConsumer("foo").HandlerTimeout(time.Millisecond*5))
+		p := &recordingPublisher{}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		ack := s.Dispatch(blockingHandler(false), newEventWriter(s, p),
+			&Event{Context: ctx, Topic: "foo", Body: NewMessage("123", "foo", nil)})
+		assert.False(t, ack)
+		assert.Equal(t, int64(0), s.Status().Timeouts)
+		assert.Empty(t, p.published)
+	})
+}
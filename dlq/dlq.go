@@ -0,0 +1,156 @@
+// Package dlq Dead Letter Queue (DLQ) publishing, inspection and re-drive for Quark.
+//
+// Failed messages are published into a DLQ topic keeping their id, correlation id and data, along with the
+// processing error (quark.HeaderMessageError) and the topic they were originally published to
+// (quark.HeaderMessageOriginalType). Operators may then read the DLQ using a provider Reader (e.g. kafka.TopicReader),
+// group and filter its messages and republish them into their original topic.
+package dlq
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+)
+
+var (
+	// ErrOriginalTopicNotFound the message has no original type and no re-drive topic was given
+	ErrOriginalTopicNotFound = errors.New("dlq: original topic not found")
+	// ErrNilReader the given DLQ reader is nil
+	ErrNilReader = errors.New("dlq: reader is nil")
+)
+
+// Reader reads every message stored in a DLQ topic, calling fn for each message until fn returns false
+type Reader interface {
+	Read(ctx context.Context, topic string, fn func(*quark.Message) bool) error
+}
+
+// NewMessage builds the DLQ message of the given failed message, the original message is not modified.
+//
+// The processing error is recorded into the message failure headers (see quark.Failure)
+func NewMessage(topic string, msg *quark.Message, cause error) *quark.Message {
+	return quark.NewDeadLetterMessage(topic, msg, cause, "")
+}
+
+// Write publishes the given Event body into the DLQ topic using the EventWriter Publisher, the Event consumer group
+// is recorded as the failing group.
+//
+// Messages are published directly, so the maximum redelivery cap of the EventWriter does not apply
+func Write(ctx context.Context, w quark.EventWriter, topic string, e *quark.Event, cause error) error {
+	if w.Publisher() == nil {
+		return quark.ErrPublisherNotImplemented
+	} else if e == nil || e.Body == nil {
+		return quark.ErrEmptyMessage
+	}
+	body := e.Body
+	if body.Type == "" {
+		cp := *body
+		cp.Type = e.Topic
+		body = &cp
+	}
+	group := e.Header.Get(quark.HeaderConsumerGroup)
+	return w.Publisher().Publish(ctx, quark.NewDeadLetterMessage(topic, body, cause, group))
+}
+
+// OriginalType returns the type (topic) the given DLQ message was originally published to
+func OriginalType(msg *quark.Message) string {
+	return msg.Metadata.ExternalData[quark.HeaderMessageOriginalType]
+}
+
+// Error returns the processing error of the given DLQ message
+func Error(msg *quark.Message) string {
+	return msg.Metadata.ExternalData[quark.HeaderMessageError]
+}
+
+// Filter selects DLQ messages, zero values match every message
+type Filter struct {
+	// Ids message identifiers
+	Ids []string
+	// Type original message type
+	Type string
+	// Since and Until message time range, both inclusive
+	Since time.Time
+	Until time.Time
+}
+
+// Match verifies if the given message is selected by the filter
+func (f Filter) Match(msg *quark.Message) bool {
+	if len(f.Ids) > 0 && !containsString(f.Ids, msg.Id) {
+		return false
+	} else if f.Type != "" && OriginalType(msg) != f.Type {
+		return false
+	} else if !f.Since.IsZero() && msg.Time.Before(f.Since) {
+		return false
+	} else if !f.Until.IsZero() && msg.Time.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Inspect reads the given DLQ topic returning messages matched by the filter
+func Inspect(ctx context.Context, r Reader, topic string, f Filter) ([]*quark.Message, error) {
+	if r == nil {
+		return nil, ErrNilReader
+	}
+	messages := make([]*quark.Message, 0)
+	err := r.Read(ctx, topic, func(msg *quark.Message) bool {
+		if f.Match(msg) {
+			messages = append(messages, msg)
+		}
+		return true
+	})
+	return messages, err
+}
+
+// Group DLQ messages sharing the same processing error and original type
+type Group struct {
+	Error    string
+	Type     string
+	Messages []*quark.Message
+}
+
+// GroupMessages groups the given messages by processing error and original type, largest groups come first
+func GroupMessages(messages []*quark.Message) []Group {
+	type key struct{ err, typ string }
+	index := make(map[key]int)
+	groups := make([]Group, 0)
+	for _, msg := range messages {
+		k := key{err: Error(msg), typ: OriginalType(msg)}
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, Group{Error: k.err, Type: k.typ})
+		}
+		groups[i].Messages = append(groups[i].Messages, msg)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i].Messages) != len(groups[j].Messages) {
+			return len(groups[i].Messages) > len(groups[j].Messages)
+		} else if groups[i].Type != groups[j].Type {
+			return groups[i].Type < groups[j].Type
+		}
+		return groups[i].Error < groups[j].Error
+	})
+	return groups
+}
+
+func copyMessage(msg *quark.Message) *quark.Message {
+	m := *msg
+	m.Metadata.ExternalData = make(map[string]string, len(msg.Metadata.ExternalData))
+	for k, v := range msg.Metadata.ExternalData {
+		m.Metadata.ExternalData[k] = v
+	}
+	return &m
+}
+
+func containsString(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package dlq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubPublisher struct {
+	published []*quark.Message
+	err       error
+}
+
+func (p *stubPublisher) Publish(_ context.Context, msgs ...*quark.Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, msgs...)
+	return nil
+}
+
+type stubReader struct {
+	messages map[string][]*quark.Message
+}
+
+func (r stubReader) Read(_ context.Context, topic string, fn func(*quark.Message) bool) error {
+	for _, msg := range r.messages[topic] {
+		if !fn(msg) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func newFailedMessage(id, msgType, cause string, t time.Time) *quark.Message {
+	msg := quark.NewMessageFromParent("root", id, msgType, []byte("hello"))
+	msg.Time = t
+	return NewMessage("dlq.payments", msg, errors.New(cause))
+}
+
+func TestNewMessage(t *testing.T) {
+	t.Run("Keep message attributes and add failure headers", func(t *testing.T) {
+		msg := quark.NewMessageFromParent("root", "123", "payment.charged", []byte("hello"))
+		msg.Metadata.RedeliveryCount = 3
+		dlqMsg := NewMessage("dlq.payments", msg, errors.New("card declined"))
+		assert.Equal(t, "dlq.payments", dlqMsg.Type)
+		assert.Equal(t, "123", dlqMsg.Id)
+		assert.Equal(t, "root", dlqMsg.Metadata.CorrelationId)
+		assert.Equal(t, 3, dlqMsg.Metadata.RedeliveryCount)
+		assert.Equal(t, "payment.charged", OriginalType(dlqMsg))
+		assert.Equal(t, "card declined", Error(dlqMsg))
+		assert.Empty(t, msg.Metadata.ExternalData)
+		assert.Equal(t, "payment.charged", msg.Type)
+	})
+
+	t.Run("Keep original type of dead-lettered messages", func(t *testing.T) {
+		msg := newFailedMessage("123", "payment.charged", "card declined", time.Now())
+		dlqMsg := NewMessage("dlq.payments.2", msg, nil)
+		assert.Equal(t, "payment.charged", OriginalType(dlqMsg))
+		assert.Equal(t, "card declined", Error(dlqMsg))
+	})
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("Publish event body bypassing redelivery cap", func(t *testing.T) {
+		p := &stubPublisher{}
+		b := quark.NewBroker(quark.WithPublisher(p), quark.WithMaxRetries(1))
+		w := quark.NewEventWriter(b)
+		body := quark.NewMessage("123", "", []byte("hello"))
+		body.Metadata.RedeliveryCount = 5
+		err := Write(context.Background(), w, "dlq.payments", &quark.Event{
+			Topic:  "payment.charged",
+			Header: quark.Header{quark.HeaderConsumerGroup: "billing"},
+			Body:   body,
+		}, errors.New("card declined"))
+		require.NoError(t, err)
+		require.Len(t, p.published, 1)
+		assert.Equal(t, "dlq.payments", p.published[0].Type)
+		assert.Equal(t, "payment.charged", OriginalType(p.published[0]))
+		assert.Equal(t, 5, p.published[0].Metadata.RedeliveryCount)
+		f, ok := quark.GetFailure(p.published[0])
+		assert.True(t, ok)
+		assert.Equal(t, "card declined", f.Error)
+		assert.Equal(t, "billing", f.Group)
+	})
+
+	t.Run("Require event body", func(t *testing.T) {
+		w := quark.NewEventWriter(quark.NewBroker(quark.WithPublisher(&stubPublisher{})))
+		err := Write(context.Background(), w, "dlq.payments", &quark.Event{}, nil)
+		assert.True(t, errors.Is(err, quark.ErrEmptyMessage))
+	})
+}
+
+func TestInspect(t *testing.T) {
+	now := time.Now()
+	r := stubReader{messages: map[string][]*quark.Message{
+		"dlq.payments": {
+			newFailedMessage("1", "payment.charged", "card declined", now.Add(-time.Hour)),
+			newFailedMessage("2", "payment.charged", "card declined", now),
+			newFailedMessage("3", "payment.refunded", "timeout", now),
+			newFailedMessage("4", "payment.charged", "timeout", now.Add(time.Hour)),
+		},
+	}}
+
+	var inspectTestingSuite = []struct {
+		description string
+		filter      Filter
+		expected    []string
+	}{
+		{"Inspect every message", Filter{}, []string{"1", "2", "3", "4"}},
+		{"Inspect messages by id", Filter{Ids: []string{"2", "4"}}, []string{"2", "4"}},
+		{"Inspect messages by original type", Filter{Type: "payment.refunded"}, []string{"3"}},
+		{"Inspect messages by time", Filter{Since: now, Until: now}, []string{"2", "3"}},
+	}
+	for _, tt := range inspectTestingSuite {
+		t.Run(tt.description, func(t *testing.T) {
+			messages, err := Inspect(context.Background(), r, "dlq.payments", tt.filter)
+			require.NoError(t, err)
+			ids := make([]string, 0, len(messages))
+			for _, msg := range messages {
+				ids = append(ids, msg.Id)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+
+	t.Run("Require reader", func(t *testing.T) {
+		_, err := Inspect(context.Background(), nil, "dlq.payments", Filter{})
+		assert.True(t, errors.Is(err, ErrNilReader))
+	})
+
+	t.Run("Group messages by error and original type", func(t *testing.T) {
+		messages, err := Inspect(context.Background(), r, "dlq.payments", Filter{})
+		require.NoError(t, err)
+		groups := GroupMessages(messages)
+		require.Len(t, groups, 3)
+		assert.Equal(t, "card declined", groups[0].Error)
+		assert.Equal(t, "payment.charged", groups[0].Type)
+		assert.Len(t, groups[0].Messages, 2)
+		assert.Equal(t, "timeout", groups[1].Error)
+		assert.Equal(t, "payment.charged", groups[1].Type)
+		assert.Equal(t, "payment.refunded", groups[2].Type)
+	})
+}
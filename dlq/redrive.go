@@ -0,0 +1,74 @@
+package dlq
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/neutrinocorp/quark"
+)
+
+// failureHeaders headers recorded by quark.Failure, the failing consumer group is kept for tracing purposes
+var failureHeaders = []string{
+	quark.HeaderMessageError,
+	quark.HeaderMessageErrorType,
+	quark.HeaderMessageFirstFailureTime,
+	quark.HeaderMessageFailureTime,
+}
+
+// RedriveOptions defines how DLQ messages are republished
+type RedriveOptions struct {
+	// Topic overrides the original type of every message
+	Topic string
+	// DefaultTopic is used for messages without an original type (e.g. published into the DLQ manually)
+	DefaultTopic string
+	// KeepRedeliveries keeps the message redelivery count, it is reset to zero otherwise
+	KeepRedeliveries bool
+}
+
+// RedriveMessage builds the message republished into the original topic of the given DLQ message, keeping its id
+// and correlation id. The failure and original type headers are removed
+func RedriveMessage(msg *quark.Message, opts RedriveOptions) (*quark.Message, error) {
+	topic := opts.Topic
+	if topic == "" {
+		topic = OriginalType(msg)
+	}
+	if topic == "" {
+		topic = opts.DefaultTopic
+	}
+	if topic == "" {
+		return nil, ErrOriginalTopicNotFound
+	}
+	m := copyMessage(msg)
+	m.Type = topic
+	for _, h := range failureHeaders {
+		delete(m.Metadata.ExternalData, h)
+	}
+	delete(m.Metadata.ExternalData, quark.HeaderMessageOriginalType)
+	if !opts.KeepRedeliveries {
+		m.Metadata.RedeliveryCount = 0
+	}
+	return m, nil
+}
+
+// Redrive republishes the given DLQ messages into their original topic using the Publisher.
+//
+// Returns the number of messages republished, messages without a topic are not published
+func Redrive(ctx context.Context, p quark.Publisher, opts RedriveOptions, messages ...*quark.Message) (int, error) {
+	if p == nil {
+		return 0, quark.ErrPublisherNotImplemented
+	}
+	errs := new(multierror.Error)
+	published := 0
+	for _, msg := range messages {
+		m, err := RedriveMessage(msg, opts)
+		if err == nil {
+			err = p.Publish(ctx, m)
+		}
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		published++
+	}
+	return published, errs.ErrorOrNil()
+}
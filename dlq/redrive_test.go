@@ -0,0 +1,79 @@
+package dlq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedriveMessage(t *testing.T) {
+	dlqMsg := newFailedMessage("123", "payment.charged", "card declined", time.Now())
+	dlqMsg.Metadata.RedeliveryCount = 3
+	dlqMsg.Metadata.ExternalData["tenant"] = "acme"
+
+	t.Run("Reset redelivery count", func(t *testing.T) {
+		msg, err := RedriveMessage(dlqMsg, RedriveOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "payment.charged", msg.Type)
+		assert.Equal(t, "123", msg.Id)
+		assert.Equal(t, "root", msg.Metadata.CorrelationId)
+		assert.Equal(t, 0, msg.Metadata.RedeliveryCount)
+		assert.Equal(t, map[string]string{"tenant": "acme"}, msg.Metadata.ExternalData)
+		assert.Equal(t, "dlq.payments", dlqMsg.Type)
+		assert.Equal(t, "card declined", Error(dlqMsg))
+	})
+
+	t.Run("Keep redelivery count", func(t *testing.T) {
+		msg, err := RedriveMessage(dlqMsg, RedriveOptions{KeepRedeliveries: true})
+		require.NoError(t, err)
+		assert.Equal(t, 3, msg.Metadata.RedeliveryCount)
+	})
+
+	t.Run("Override topic", func(t *testing.T) {
+		msg, err := RedriveMessage(dlqMsg, RedriveOptions{Topic: "payment.charged.v2", DefaultTopic: "foo"})
+		require.NoError(t, err)
+		assert.Equal(t, "payment.charged.v2", msg.Type)
+	})
+
+	t.Run("Use default topic", func(t *testing.T) {
+		manual := quark.NewMessage("456", "dlq.payments", nil)
+		msg, err := RedriveMessage(manual, RedriveOptions{DefaultTopic: "payments"})
+		require.NoError(t, err)
+		assert.Equal(t, "payments", msg.Type)
+		_, err = RedriveMessage(manual, RedriveOptions{})
+		assert.True(t, errors.Is(err, ErrOriginalTopicNotFound))
+	})
+}
+
+func TestRedrive(t *testing.T) {
+	t.Run("Republish messages into original topics", func(t *testing.T) {
+		p := &stubPublisher{}
+		n, err := Redrive(context.Background(), p, RedriveOptions{},
+			newFailedMessage("1", "payment.charged", "card declined", time.Now()),
+			quark.NewMessage("2", "dlq.payments", nil),
+			newFailedMessage("3", "payment.refunded", "timeout", time.Now()))
+		assert.True(t, errors.Is(err, ErrOriginalTopicNotFound))
+		assert.Equal(t, 2, n)
+		require.Len(t, p.published, 2)
+		assert.Equal(t, "payment.charged", p.published[0].Type)
+		assert.Equal(t, "payment.refunded", p.published[1].Type)
+	})
+
+	t.Run("Report publisher errors", func(t *testing.T) {
+		errPublish := errors.New("broker unavailable")
+		n, err := Redrive(context.Background(), &stubPublisher{err: errPublish}, RedriveOptions{},
+			newFailedMessage("1", "payment.charged", "card declined", time.Now()))
+		assert.True(t, errors.Is(err, errPublish))
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("Require publisher", func(t *testing.T) {
+		_, err := Redrive(context.Background(), nil, RedriveOptions{})
+		assert.True(t, errors.Is(err, quark.ErrPublisherNotImplemented))
+	})
+}
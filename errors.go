@@ -22,6 +22,42 @@ var (
 	ErrEmptyCluster = errors.New("consumer cluster is empty")
 	// ErrRequiredGroup a consumer group is required
 	ErrRequiredGroup = errors.New("consumer group is required")
+	// ErrBrokerNotServing the broker has not started serving yet
+	ErrBrokerNotServing = errors.New("broker is not serving")
+	// ErrWorkerExited the worker stopped consuming without being closed
+	ErrWorkerExited = errors.New("worker exited unexpectedly")
+	// ErrMaxRestartsExceeded the supervisor restarted its workers too many times within the restart window
+	ErrMaxRestartsExceeded = errors.New("max worker restarts exceeded")
+	// ErrInvalidStartPosition the given start position could not be parsed
+	ErrInvalidStartPosition = errors.New("invalid start position")
+	// ErrInvalidConfig the configuration contains an invalid value
+	ErrInvalidConfig = errors.New("invalid configuration")
+	// ErrHandlerNotFound no handler was registered with the given name
+	ErrHandlerNotFound = errors.New("handler not found")
+	// ErrProviderConfigNotFound the configuration does not contain the given provider section
+	ErrProviderConfigNotFound = errors.New("provider configuration not found")
+	// ErrTopicNotFound no running consumer is subscribed to the given topic
+	ErrTopicNotFound = errors.New("topic not found")
+	// ErrPauseNotSupported the worker does not support pausing and resuming
+	ErrPauseNotSupported = errors.New("worker does not support pause")
+	// ErrRemoveNotSupported the EventMux does not support removing a single Consumer
+	ErrRemoveNotSupported = errors.New("event mux does not support consumer removal")
+	// ErrRequestNotSupported the EventWriter does not support request-reply
+	ErrRequestNotSupported = errors.New("event writer does not support request")
+	// ErrEmptyConsumer no consumer was found
+	ErrEmptyConsumer = errors.New("consumer is empty")
+	// ErrReplyTopicNotDefined the broker does not have a reply topic to receive replies from
+	ErrReplyTopicNotDefined = errors.New("reply topic is not defined")
+	// ErrHandlerPanic a handler panicked while serving an Event (see PanicError)
+	ErrHandlerPanic = errors.New("handler panic")
+	// ErrHandlerTimeout a handler exceeded its Consumer handler timeout while serving an Event
+	ErrHandlerTimeout = errors.New("handler timeout")
+	// ErrCircuitOpen the circuit breaker rejected the messages because the Publisher is failing
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+	// ErrPublisherClosed the publisher was already closed
+	ErrPublisherClosed = errors.New("publisher closed")
+	// ErrReplyToNotFound the given Event does not have a topic to reply to
+	ErrReplyToNotFound = errors.New("reply to topic not found")
 )
 
 // ErrorHandler is a Hook that may be called when a error occurs inside Quark processes
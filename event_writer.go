@@ -43,9 +43,55 @@ type EventWriter interface {
 	WriteRetry(ctx context.Context, msg *Message) error
 }
 
+// RetryErrorWriter is an EventWriter able to record processing errors into retried messages
+type RetryErrorWriter interface {
+	EventWriter
+	// WriteRetryError push the given Event into a retry topic like WriteRetry, recording the processing error, consumer
+	// group, host and failure times into the message headers (see Failure)
+	WriteRetryError(ctx context.Context, msg *Message, err error) error
+}
+
+// WriteRetryError push the given Event into a retry topic recording the processing error (see
+// RetryErrorWriter), EventWriter(s) without RetryErrorWriter support fall back to WriteRetry
+func WriteRetryError(ctx context.Context, w EventWriter, msg *Message, err error) error {
+	if rw, ok := w.(RetryErrorWriter); ok {
+		return rw.WriteRetryError(ctx, msg, err)
+	}
+	return w.WriteRetry(ctx, msg)
+}
+
+// Requester is an EventWriter able to wait for the reply of a message (request-reply)
+type Requester interface {
+	EventWriter
+	// Request push the given message into the Event-Driven ecosystem and waits for its reply.
+	//
+	// The reply will be received from the Broker's ReplyTopic and matched using the reply CorrelationId, which must
+	// be the given message Id (see Reply). Request waits until a reply is received or ctx is done, so a context
+	// deadline should be always specified.
+	//
+	// Returns ErrReplyTopicNotDefined if the Broker has no ReplyTopic
+	Request(ctx context.Context, msg *Message) (*Event, error)
+}
+
+// Request push the given message and waits for its reply (see Requester).
+//
+// Returns ErrRequestNotSupported if the EventWriter is not a Requester
+func Request(ctx context.Context, w EventWriter, msg *Message) (*Event, error) {
+	rw, ok := w.(Requester)
+	if !ok {
+		return nil, ErrRequestNotSupported
+	}
+	return rw.Request(ctx, msg)
+}
+
 // ErrMessageRedeliveredTooMuch the message has been published the number of times of the configuration limit
 var ErrMessageRedeliveredTooMuch = errors.New("message has been redelivered too much")
 
+var (
+	_ RetryErrorWriter = &defaultEventWriter{}
+	_ Requester        = &defaultEventWriter{}
+)
+
 type defaultEventWriter struct {
 	Supervisor *Supervisor
 	publisher  Publisher
@@ -53,6 +99,14 @@ type defaultEventWriter struct {
 	backoff    *backoff.Backoff
 }
 
+// NewEventWriter allocates and creates a default EventWriter using the given Broker global configuration and
+// Publisher.
+//
+// Useful to push Event(s) outside a Consumer handler (e.g. from an HTTP handler)
+func NewEventWriter(b *Broker) EventWriter {
+	return newEventWriter(newSupervisor(b, new(Consumer)), b.Publisher)
+}
+
 // newEventWriter allocates and creates a default EventWriter
 func newEventWriter(n *Supervisor, p Publisher) EventWriter {
 	return &defaultEventWriter{
@@ -130,15 +184,68 @@ func (d *defaultEventWriter) WriteRetry(ctx context.Context, msg *Message) error
 	return d.publish(ctx, msg)
 }
 
+func (d *defaultEventWriter) WriteRetryError(ctx context.Context, msg *Message, err error) error {
+	if msg != nil {
+		NewFailure(err, d.group()).Apply(msg)
+	}
+	return d.WriteRetry(ctx, msg)
+}
+
+// group returns the consumer group of the Supervisor this writer belongs to
+func (d *defaultEventWriter) group() string {
+	if d.Supervisor == nil || d.Supervisor.Consumer == nil {
+		return ""
+	}
+	return d.Supervisor.GetGroup()
+}
+
+func (d *defaultEventWriter) Request(ctx context.Context, msg *Message) (*Event, error) {
+	if d.publisher == nil {
+		return nil, ErrPublisherNotImplemented
+	} else if msg == nil {
+		return nil, ErrEmptyMessage
+	} else if d.Supervisor == nil || d.Supervisor.Broker == nil || d.Supervisor.Broker.ReplyTopic == "" {
+		return nil, ErrReplyTopicNotDefined
+	}
+
+	if msg.Metadata.ExternalData == nil {
+		msg.Metadata.ExternalData = map[string]string{}
+	}
+	msg.Metadata.ExternalData[HeaderMessageReplyTo] = d.Supervisor.Broker.ReplyTopic
+	replies := d.Supervisor.Broker.getReplyRouter()
+	reply := replies.register(msg.Id)
+	defer replies.unregister(msg.Id)
+	if err := d.publish(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case e := <-reply:
+		return e, nil
+	}
+}
+
 func (d *defaultEventWriter) publish(ctx context.Context, msg *Message) error {
 	d.marshalMessage(msg)
 
 	backoffFactor := msg.Metadata.RedeliveryCount
+	circuit, isCircuit := d.publisher.(CircuitAware)
 	if backoffFactor > d.Supervisor.setDefaultMaxRetries() {
 		return ErrMessageRedeliveredTooMuch
+	} else if isCircuit && circuit.Rejects() {
+		return ErrCircuitOpen // fail fast instead of waiting for the backoff
 	}
 
-	time.Sleep(d.backoff.ForAttempt(float64(backoffFactor)))
+	if !isCircuit || !circuit.Diverts() {
+		// the fallback Publisher does not have to wait for the underlying Publisher to recover
+		time.Sleep(d.backoff.ForAttempt(float64(backoffFactor)))
+	}
+	if async, ok := d.publisher.(AsyncPublisher); ok {
+		// Publish returns before delivery, handlers must not acknowledge their Event until the message was delivered
+		return async.PublishAsync(ctx, msg)[0].Wait(ctx)
+	}
 	return d.publisher.Publish(ctx, msg)
 }
 
@@ -3,6 +3,7 @@ package quark
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
 	"testing"
 	"time"
@@ -203,3 +204,49 @@ func TestDefaultEventWriter_WriteRetry(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultEventWriter_WriteRetryError(t *testing.T) {
+	t.Run("Event Writer write retry recording failure", func(t *testing.T) {
+		w := newEventWriter(&Supervisor{Consumer: NewConsumer("foo").Group("foo-group"), Broker: &Broker{
+			MaxRetries:   5,
+			RetryBackoff: time.Millisecond,
+		}}, stubPublisher{})
+		msg := NewMessage("123", "foo", nil)
+		w.Header().Set(HeaderMessageType, "foo")
+		err := WriteRetryError(context.Background(), w, msg, fmt.Errorf("saving: %w", errStubPublisher))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, msg.Metadata.RedeliveryCount)
+		f, ok := GetFailure(msg)
+		assert.True(t, ok)
+		assert.Equal(t, "saving: generic stub publisher error", f.Error)
+		assert.Equal(t, "*errors.errorString", f.ErrorType)
+		assert.Equal(t, "foo-group", f.Group)
+
+		assert.True(t, errors.Is(WriteRetryError(context.Background(), w, nil, errStubPublisher), ErrEmptyMessage))
+	})
+
+	t.Run("Event Writer write retry without failure support", func(t *testing.T) {
+		w := plainEventWriter{newEventWriter(&Supervisor{Consumer: NewConsumer("foo"), Broker: &Broker{
+			MaxRetries:   5,
+			RetryBackoff: time.Millisecond,
+		}}, stubPublisher{})}
+		msg := NewMessage("123", "foo", nil)
+		assert.NoError(t, WriteRetryError(context.Background(), w, msg, errStubPublisher))
+		assert.Equal(t, 1, msg.Metadata.RedeliveryCount)
+		_, ok := GetFailure(msg)
+		assert.False(t, ok)
+	})
+}
+
+// plainEventWriter hides every optional EventWriter extension (e.g. RetryErrorWriter, Requester)
+type plainEventWriter struct {
+	EventWriter
+}
+
+func TestRequest(t *testing.T) {
+	t.Run("Request without request-reply support", func(t *testing.T) {
+		w := plainEventWriter{NewEventWriter(NewBroker())}
+		_, err := Request(context.Background(), w, NewMessage("1", "math.double", nil))
+		assert.Equal(t, ErrRequestNotSupported, err)
+	})
+}
@@ -0,0 +1,80 @@
+package eventstore
+
+import (
+	"context"
+	"sync"
+
+	"github.com/neutrinocorp/quark"
+)
+
+// MemoryBackend in-memory Backend, intended for testing and local development
+type MemoryBackend struct {
+	streams   map[string][]Record
+	snapshots map[string]Snapshot
+	mu        sync.RWMutex
+}
+
+var _ Backend = &MemoryBackend{}
+
+// NewMemoryBackend allocates and returns a MemoryBackend
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		streams:   map[string][]Record{},
+		snapshots: map[string]Snapshot{},
+		mu:        sync.RWMutex{},
+	}
+}
+
+// Append stores the given messages at the end of the stream if its current version matches expectedVersion
+func (b *MemoryBackend) Append(_ context.Context, streamId string, expectedVersion int,
+	msgs ...*quark.Message) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stream := b.streams[streamId]
+	if expectedVersion != AnyVersion && len(stream) != expectedVersion {
+		return 0, ErrConcurrencyConflict
+	}
+	stampVersions(len(stream), msgs)
+	for _, msg := range msgs {
+		stream = append(stream, Record{
+			StreamId: streamId,
+			Version:  len(stream) + 1,
+			Message:  msg,
+		})
+	}
+	b.streams[streamId] = stream
+	return len(stream), nil
+}
+
+// Load returns every record of the stream starting from the given version (inclusive)
+func (b *MemoryBackend) Load(_ context.Context, streamId string, fromVersion int) ([]Record, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	stream := b.streams[streamId]
+	if fromVersion < 1 {
+		fromVersion = 1
+	}
+	if fromVersion > len(stream) {
+		return []Record{}, nil
+	}
+	return append([]Record(nil), stream[fromVersion-1:]...), nil
+}
+
+// SaveSnapshot stores the given snapshot, replacing the previous one
+func (b *MemoryBackend) SaveSnapshot(_ context.Context, s Snapshot) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.snapshots[s.StreamId] = s
+	return nil
+}
+
+// LoadSnapshot returns the latest snapshot of the stream or ErrSnapshotNotFound
+func (b *MemoryBackend) LoadSnapshot(_ context.Context, streamId string) (*Snapshot, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	s, ok := b.snapshots[streamId]
+	if !ok {
+		return nil, ErrSnapshotNotFound
+	}
+	return &s, nil
+}
@@ -0,0 +1,205 @@
+package eventstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/neutrinocorp/quark/dialect"
+)
+
+const (
+	// DefaultTable default event stream table name
+	DefaultTable = "quark_events"
+	// DefaultSnapshotTable default snapshot table name
+	DefaultSnapshotTable = "quark_snapshots"
+)
+
+var defaultMaxAppendAttempts = 5
+
+// SQLBackend Backend using a database/sql compatible database (e.g. Postgres, SQLite, MySQL)
+//
+// Messages are stored as JSON using the CNCF CloudEvents attribute names and snapshots are stored base64-encoded.
+// Concurrent appends are detected using
+// the (stream_id, version) primary key, appends using AnyVersion are retried up to MaxAppendAttempts times.
+type SQLBackend struct {
+	DB            *sql.DB
+	Dialect       dialect.Dialect
+	Table         string
+	SnapshotTable string
+	// MaxAppendAttempts attempts of appends using AnyVersion conflicting with concurrent appends (default 5)
+	MaxAppendAttempts int
+}
+
+var _ Backend = &SQLBackend{}
+
+// NewSQLBackend allocates and returns a SQLBackend using the default tables
+func NewSQLBackend(db *sql.DB, d dialect.Dialect) *SQLBackend {
+	return &SQLBackend{
+		DB:                db,
+		Dialect:           d,
+		Table:             DefaultTable,
+		SnapshotTable:     DefaultSnapshotTable,
+		MaxAppendAttempts: defaultMaxAppendAttempts,
+	}
+}
+
+// Migrate creates the event stream and snapshot tables if they do not exist
+func (b *SQLBackend) Migrate(ctx context.Context) error {
+	if _, err := b.DB.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+b.setDefaultTable()+` (
+	stream_id VARCHAR(255) NOT NULL,
+	version INTEGER NOT NULL,
+	message_id VARCHAR(255) NOT NULL,
+	type VARCHAR(255) NOT NULL,
+	payload TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (stream_id, version)
+)`); err != nil {
+		return err
+	}
+	_, err := b.DB.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+b.setDefaultSnapshotTable()+` (
+	stream_id VARCHAR(255) NOT NULL PRIMARY KEY,
+	version INTEGER NOT NULL,
+	data TEXT NOT NULL
+)`)
+	return err
+}
+
+// Append stores the given messages at the end of the stream if its current version matches expectedVersion
+func (b *SQLBackend) Append(ctx context.Context, streamId string, expectedVersion int,
+	msgs ...*quark.Message) (int, error) {
+	if expectedVersion != AnyVersion {
+		return b.append(ctx, streamId, expectedVersion, msgs)
+	}
+	for attempt := 1; ; attempt++ {
+		version, err := b.append(ctx, streamId, expectedVersion, msgs)
+		if !errors.Is(err, ErrConcurrencyConflict) || attempt >= b.setDefaultMaxAppendAttempts() || ctx.Err() != nil {
+			return version, err
+		}
+	}
+}
+
+// append stores the given messages inside a single transaction, stamping their versions after the current one
+func (b *SQLBackend) append(ctx context.Context, streamId string, expectedVersion int,
+	msgs []*quark.Message) (int, error) {
+	tx, err := b.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	current := 0
+	if err = tx.QueryRowContext(ctx, b.Dialect.Rebind(`SELECT COALESCE(MAX(version), 0) FROM `+
+		b.setDefaultTable()+` WHERE stream_id = ?`), streamId).Scan(&current); err != nil {
+		return 0, err
+	}
+	if expectedVersion != AnyVersion && current != expectedVersion {
+		return 0, ErrConcurrencyConflict
+	}
+	stampVersions(current, msgs)
+
+	stmt, err := tx.PrepareContext(ctx, b.Dialect.Rebind(`INSERT INTO `+b.setDefaultTable()+
+		` (stream_id, version, message_id, type, payload, created_at) VALUES (?, ?, ?, ?, ?, ?)`))
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	now := time.Now().UTC()
+	for _, msg := range msgs {
+		payload, err := json.Marshal(msg)
+		if err != nil {
+			return 0, err
+		}
+		current++
+		if _, err = stmt.ExecContext(ctx, streamId, current, msg.Id, msg.Type, string(payload),
+			now); b.Dialect.IsUniqueViolation(err) {
+			return 0, ErrConcurrencyConflict // another process appended first
+		} else if err != nil {
+			return 0, fmt.Errorf("eventstore: append %s version %d: %w", streamId, current, err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return current, nil
+}
+
+// Load returns every record of the stream starting from the given version (inclusive)
+func (b *SQLBackend) Load(ctx context.Context, streamId string, fromVersion int) ([]Record, error) {
+	rows, err := b.DB.QueryContext(ctx, b.Dialect.Rebind(`SELECT version, payload FROM `+b.setDefaultTable()+
+		` WHERE stream_id = ? AND version >= ? ORDER BY version ASC`), streamId, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]Record, 0)
+	for rows.Next() {
+		r := Record{StreamId: streamId, Message: new(quark.Message)}
+		payload := ""
+		if err = rows.Scan(&r.Version, &payload); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(payload), r.Message); err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// SaveSnapshot stores the given snapshot, replacing the previous one
+func (b *SQLBackend) SaveSnapshot(ctx context.Context, s Snapshot) error {
+	query := `INSERT INTO ` + b.setDefaultSnapshotTable() + ` (stream_id, version, data) VALUES (?, ?, ?) `
+	if b.Dialect == dialect.MySQL {
+		query += `ON DUPLICATE KEY UPDATE version = VALUES(version), data = VALUES(data)`
+	} else {
+		query += `ON CONFLICT (stream_id) DO UPDATE SET version = excluded.version, data = excluded.data`
+	}
+	_, err := b.DB.ExecContext(ctx, b.Dialect.Rebind(query), s.StreamId, s.Version,
+		base64.StdEncoding.EncodeToString(s.Data))
+	return err
+}
+
+// LoadSnapshot returns the latest snapshot of the stream or ErrSnapshotNotFound
+func (b *SQLBackend) LoadSnapshot(ctx context.Context, streamId string) (*Snapshot, error) {
+	s := &Snapshot{StreamId: streamId}
+	data := ""
+	err := b.DB.QueryRowContext(ctx, b.Dialect.Rebind(`SELECT version, data FROM `+b.setDefaultSnapshotTable()+
+		` WHERE stream_id = ?`), streamId).Scan(&s.Version, &data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSnapshotNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if s.Data, err = base64.StdEncoding.DecodeString(data); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (b *SQLBackend) setDefaultTable() string {
+	if b.Table != "" {
+		return b.Table
+	}
+	return DefaultTable
+}
+
+func (b *SQLBackend) setDefaultSnapshotTable() string {
+	if b.SnapshotTable != "" {
+		return b.SnapshotTable
+	}
+	return DefaultSnapshotTable
+}
+
+func (b *SQLBackend) setDefaultMaxAppendAttempts() int {
+	if b.MaxAppendAttempts > 0 {
+		return b.MaxAppendAttempts
+	}
+	return defaultMaxAppendAttempts
+}
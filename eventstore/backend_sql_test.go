@@ -0,0 +1,101 @@
+package eventstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/neutrinocorp/quark/dialect"
+	"github.com/neutrinocorp/quark/internal/sqltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLiteTestingBackend(t *testing.T) *SQLBackend {
+	b := NewSQLBackend(sqltest.Open(t, "eventstore.db"), dialect.SQLite)
+	require.Nil(t, b.Migrate(context.Background()))
+	return b
+}
+
+func TestSQLBackend(t *testing.T) {
+	ctx := context.Background()
+	t.Run("SQL backend append and load stream", func(t *testing.T) {
+		b := newSQLiteTestingBackend(t)
+		v, err := b.Append(ctx, "account-1", NoStream, newDeposit("1", "10"), newDeposit("2", "5"))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, v)
+		v, err = b.Append(ctx, "account-1", AnyVersion, newDeposit("3", "1"))
+		assert.Nil(t, err)
+		assert.Equal(t, 3, v)
+
+		records, err := b.Load(ctx, "account-1", 2)
+		assert.Nil(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, 2, records[0].Version)
+		assert.Equal(t, "2", records[0].Message.Id)
+		assert.Equal(t, "3", records[1].Message.Id)
+		// stored messages are stamped with their position, even if appended using AnyVersion
+		assert.Equal(t, "2", records[0].Message.Metadata.ExternalData[HeaderStreamVersion])
+		assert.Equal(t, "3", records[1].Message.Metadata.ExternalData[HeaderStreamVersion])
+	})
+
+	t.Run("SQL backend optimistic concurrency", func(t *testing.T) {
+		b := newSQLiteTestingBackend(t)
+		_, err := b.Append(ctx, "account-1", NoStream, newDeposit("1", "10"))
+		assert.Nil(t, err)
+		_, err = b.Append(ctx, "account-1", NoStream, newDeposit("2", "10"))
+		assert.Equal(t, ErrConcurrencyConflict, err)
+		records, _ := b.Load(ctx, "account-1", 0)
+		assert.Len(t, records, 1)
+	})
+
+	t.Run("SQL backend return insert errors", func(t *testing.T) {
+		b := newSQLiteTestingBackend(t)
+		b.Table = "guarded_events"
+		_, err := b.DB.ExecContext(ctx, `CREATE TABLE guarded_events (
+	stream_id VARCHAR(255) NOT NULL,
+	version INTEGER NOT NULL,
+	message_id VARCHAR(255) NOT NULL,
+	type VARCHAR(255) NOT NULL CHECK (type <> 'bank.1.event.account.frozen'),
+	payload TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (stream_id, version)
+)`)
+		require.Nil(t, err)
+		msg := newDeposit("1", "0")
+		msg.Type = "bank.1.event.account.frozen"
+		_, err = b.Append(ctx, "account-1", NoStream, msg)
+		assert.NotNil(t, err)
+		assert.False(t, errors.Is(err, ErrConcurrencyConflict))
+	})
+
+	t.Run("SQL backend retry conflicting appends of any version", func(t *testing.T) {
+		b := newSQLiteTestingBackend(t)
+		b.MaxAppendAttempts = 3
+		// every insert of a frozen account conflicts, as if another process always appended first
+		_, err := b.DB.ExecContext(ctx, `CREATE TRIGGER conflicting_append BEFORE INSERT ON `+DefaultTable+`
+WHEN NEW.type = 'bank.1.event.account.frozen'
+BEGIN SELECT RAISE(ABORT, 'UNIQUE constraint failed: concurrent append'); END`)
+		require.Nil(t, err)
+		msg := newDeposit("1", "0")
+		msg.Type = "bank.1.event.account.frozen"
+		_, err = b.Append(ctx, "account-1", AnyVersion, msg)
+		assert.Equal(t, ErrConcurrencyConflict, err)
+
+		v, err := b.Append(ctx, "account-1", AnyVersion, newDeposit("2", "10"))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, v)
+	})
+
+	t.Run("SQL backend replace snapshots", func(t *testing.T) {
+		b := newSQLiteTestingBackend(t)
+		_, err := b.LoadSnapshot(ctx, "account-1")
+		assert.Equal(t, ErrSnapshotNotFound, err)
+		assert.Nil(t, b.SaveSnapshot(ctx, Snapshot{StreamId: "account-1", Version: 2, Data: []byte(`{"balance":15}`)}))
+		assert.Nil(t, b.SaveSnapshot(ctx, Snapshot{StreamId: "account-1", Version: 3, Data: []byte(`{"balance":16}`)}))
+		s, err := b.LoadSnapshot(ctx, "account-1")
+		assert.Nil(t, err)
+		assert.Equal(t, 3, s.Version)
+		assert.Equal(t, `{"balance":16}`, string(s.Data))
+	})
+}
@@ -0,0 +1,196 @@
+// Package eventstore Event-sourced aggregate storage for Quark.
+//
+// Domain events (quark.DomainEvent kind) are appended to a per-aggregate stream using optimistic concurrency control
+// and published through a quark.Publisher once stored. Streams are loaded to rehydrate aggregates, optionally
+// starting from a snapshot.
+package eventstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/neutrinocorp/quark"
+)
+
+const (
+	// AnyVersion skips the optimistic concurrency verification when appending
+	AnyVersion = -1
+	// NoStream expected version of a stream without events
+	NoStream = 0
+)
+
+const (
+	// HeaderStreamId aggregate stream the Message was appended to
+	HeaderStreamId = "quark-eventstore-stream-id"
+	// HeaderStreamVersion Message position inside its aggregate stream, starting from 1
+	HeaderStreamVersion = "quark-eventstore-stream-version"
+)
+
+var (
+	// ErrConcurrencyConflict the stream version is different than the expected one
+	ErrConcurrencyConflict = errors.New("eventstore: concurrency conflict")
+	// ErrSnapshotNotFound the stream does not have a snapshot
+	ErrSnapshotNotFound = errors.New("eventstore: snapshot not found")
+	// ErrNotDomainEvent the Message kind is not quark.DomainEvent
+	ErrNotDomainEvent = errors.New("eventstore: message is not a domain event")
+	// ErrEmptyStream no stream id was given
+	ErrEmptyStream = errors.New("eventstore: stream id is empty")
+)
+
+// Record a domain event stored in an aggregate stream
+type Record struct {
+	StreamId string
+	// Version position inside the stream, starting from 1
+	Version int
+	Message *quark.Message
+}
+
+// Snapshot an aggregate state at a specific stream version
+type Snapshot struct {
+	StreamId string
+	Version  int
+	Data     []byte
+}
+
+// Backend event store storage
+type Backend interface {
+	// Append stores the given messages at the end of the stream if its current version matches expectedVersion.
+	//
+	// Messages must be stamped with their stream position (HeaderStreamVersion) before being stored. Appends using
+	// AnyVersion must not fail if another process appended first, the position is resolved again instead.
+	//
+	// Returns the new stream version or ErrConcurrencyConflict
+	Append(ctx context.Context, streamId string, expectedVersion int, msgs ...*quark.Message) (int, error)
+	// Load returns every record of the stream starting from the given version (inclusive)
+	Load(ctx context.Context, streamId string, fromVersion int) ([]Record, error)
+	// SaveSnapshot stores the given snapshot, replacing the previous one
+	SaveSnapshot(ctx context.Context, s Snapshot) error
+	// LoadSnapshot returns the latest snapshot of the stream or ErrSnapshotNotFound
+	LoadSnapshot(ctx context.Context, streamId string) (*Snapshot, error)
+}
+
+// Aggregate a domain entity rehydrated from its domain events
+type Aggregate interface {
+	// Apply mutates the aggregate state using the given domain event
+	Apply(*quark.Message) error
+}
+
+// SnapshotAggregate an Aggregate able to store and restore its state from a Snapshot
+type SnapshotAggregate interface {
+	Aggregate
+	// Snapshot encodes the current aggregate state
+	Snapshot() ([]byte, error)
+	// Restore decodes the given aggregate state
+	Restore([]byte) error
+}
+
+// Store appends and loads aggregate streams, publishing every appended domain event
+type Store struct {
+	Backend   Backend
+	Publisher quark.Publisher
+}
+
+// New allocates and returns a Store, publisher may be nil if appended events must not be published
+func New(b Backend, p quark.Publisher) *Store {
+	return &Store{
+		Backend:   b,
+		Publisher: p,
+	}
+}
+
+// Append stores the given domain events at the end of the stream and then publishes them.
+//
+// Messages are stamped with the quark.DomainEvent kind and their stream position. If publishing fails, events
+// remain stored and the error is returned with the new stream version; combine the Publisher with the outbox
+// package for atomic publishing.
+func (s *Store) Append(ctx context.Context, streamId string, expectedVersion int, msgs ...*quark.Message) (int,
+	error) {
+	if streamId == "" {
+		return 0, ErrEmptyStream
+	} else if len(msgs) == 0 {
+		return 0, quark.ErrEmptyMessage
+	}
+	for _, msg := range msgs {
+		if msg == nil {
+			return 0, quark.ErrEmptyMessage
+		} else if kind := msg.Metadata.ExternalData[quark.HeaderMessageKind]; kind != "" && kind != quark.DomainEvent {
+			return 0, fmt.Errorf("%w: %s", ErrNotDomainEvent, msg.Id)
+		}
+		if msg.Metadata.ExternalData == nil {
+			msg.Metadata.ExternalData = map[string]string{}
+		}
+		msg.Metadata.ExternalData[quark.HeaderMessageKind] = quark.DomainEvent
+		msg.Metadata.ExternalData[HeaderStreamId] = streamId
+	}
+
+	// backends stamp versions before storing so stored and published messages are the same
+	version, err := s.Backend.Append(ctx, streamId, expectedVersion, msgs...)
+	if err != nil {
+		return 0, err
+	}
+	if s.Publisher == nil {
+		return version, nil
+	}
+	return version, s.Publisher.Publish(ctx, msgs...)
+}
+
+// Load returns every record of the stream
+func (s *Store) Load(ctx context.Context, streamId string) ([]Record, error) {
+	return s.Backend.Load(ctx, streamId, 1)
+}
+
+// Rehydrate applies every domain event of the stream into the given aggregate, starting from its latest snapshot if
+// the aggregate implements SnapshotAggregate.
+//
+// Returns the current stream version, to be used as expected version on the next Append
+func (s *Store) Rehydrate(ctx context.Context, streamId string, agg Aggregate) (int, error) {
+	version := NoStream
+	if snapAgg, ok := agg.(SnapshotAggregate); ok {
+		snap, err := s.Backend.LoadSnapshot(ctx, streamId)
+		if err != nil && !errors.Is(err, ErrSnapshotNotFound) {
+			return 0, err
+		} else if err == nil {
+			if err = snapAgg.Restore(snap.Data); err != nil {
+				return 0, err
+			}
+			version = snap.Version
+		}
+	}
+
+	records, err := s.Backend.Load(ctx, streamId, version+1)
+	if err != nil {
+		return 0, err
+	}
+	for _, r := range records {
+		if err = agg.Apply(r.Message); err != nil {
+			return 0, err
+		}
+		version = r.Version
+	}
+	return version, nil
+}
+
+// Snapshot stores the given aggregate state at the given stream version
+func (s *Store) Snapshot(ctx context.Context, streamId string, version int, agg SnapshotAggregate) error {
+	data, err := agg.Snapshot()
+	if err != nil {
+		return err
+	}
+	return s.Backend.SaveSnapshot(ctx, Snapshot{
+		StreamId: streamId,
+		Version:  version,
+		Data:     data,
+	})
+}
+
+// stampVersions sets the stream position of the given messages, appended after the given stream version
+func stampVersions(from int, msgs []*quark.Message) {
+	for i, msg := range msgs {
+		if msg.Metadata.ExternalData == nil {
+			msg.Metadata.ExternalData = map[string]string{}
+		}
+		msg.Metadata.ExternalData[HeaderStreamVersion] = strconv.Itoa(from + i + 1)
+	}
+}
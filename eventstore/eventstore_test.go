@@ -0,0 +1,118 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubPublisher struct {
+	published []*quark.Message
+}
+
+func (p *stubPublisher) Publish(_ context.Context, msgs ...*quark.Message) error {
+	p.published = append(p.published, msgs...)
+	return nil
+}
+
+type stubAccount struct {
+	Balance int `json:"balance"`
+	applied int
+}
+
+func (a *stubAccount) Apply(msg *quark.Message) error {
+	n, err := strconv.Atoi(string(msg.Data))
+	if err != nil {
+		return err
+	}
+	a.Balance += n
+	a.applied++
+	return nil
+}
+
+func (a *stubAccount) Snapshot() ([]byte, error) {
+	return json.Marshal(a)
+}
+
+func (a *stubAccount) Restore(data []byte) error {
+	return json.Unmarshal(data, a)
+}
+
+func newDeposit(id, amount string) *quark.Message {
+	return quark.NewMessage(id, "bank.1.event.account.deposited", []byte(amount))
+}
+
+func TestStore_Append(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Event store append and publish", func(t *testing.T) {
+		p := &stubPublisher{}
+		s := New(NewMemoryBackend(), p)
+		v, err := s.Append(ctx, "account-1", NoStream, newDeposit("1", "10"), newDeposit("2", "5"))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, v)
+		assert.Len(t, p.published, 2)
+		assert.Equal(t, quark.DomainEvent, p.published[0].Metadata.ExternalData[quark.HeaderMessageKind])
+		assert.Equal(t, "account-1", p.published[1].Metadata.ExternalData[HeaderStreamId])
+		assert.Equal(t, "2", p.published[1].Metadata.ExternalData[HeaderStreamVersion])
+
+		v, err = s.Append(ctx, "account-1", AnyVersion, newDeposit("3", "1"))
+		assert.Nil(t, err)
+		assert.Equal(t, 3, v)
+		assert.Equal(t, "3", p.published[2].Metadata.ExternalData[HeaderStreamVersion])
+		records, err := s.Load(ctx, "account-1")
+		assert.Nil(t, err)
+		assert.Equal(t, "3", records[2].Message.Metadata.ExternalData[HeaderStreamVersion])
+	})
+
+	t.Run("Event store optimistic concurrency", func(t *testing.T) {
+		s := New(NewMemoryBackend(), nil)
+		_, err := s.Append(ctx, "account-1", NoStream, newDeposit("1", "10"))
+		assert.Nil(t, err)
+		_, err = s.Append(ctx, "account-1", NoStream, newDeposit("2", "10"))
+		assert.Equal(t, ErrConcurrencyConflict, err)
+	})
+
+	t.Run("Event store reject commands", func(t *testing.T) {
+		s := New(NewMemoryBackend(), nil)
+		msg := newDeposit("1", "10")
+		msg.Metadata.ExternalData[quark.HeaderMessageKind] = quark.Command
+		_, err := s.Append(ctx, "account-1", NoStream, msg)
+		assert.True(t, errors.Is(err, ErrNotDomainEvent))
+		_, err = s.Append(ctx, "", NoStream, newDeposit("1", "10"))
+		assert.Equal(t, ErrEmptyStream, err)
+	})
+}
+
+func TestStore_Rehydrate(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Event store rehydrate aggregate", func(t *testing.T) {
+		s := New(NewMemoryBackend(), nil)
+		_, _ = s.Append(ctx, "account-1", NoStream, newDeposit("1", "10"), newDeposit("2", "5"))
+		acc := &stubAccount{}
+		v, err := s.Rehydrate(ctx, "account-1", acc)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, v)
+		assert.Equal(t, 15, acc.Balance)
+	})
+
+	t.Run("Event store rehydrate aggregate from snapshot", func(t *testing.T) {
+		s := New(NewMemoryBackend(), nil)
+		v, _ := s.Append(ctx, "account-1", NoStream, newDeposit("1", "10"), newDeposit("2", "5"))
+		acc := &stubAccount{}
+		_, _ = s.Rehydrate(ctx, "account-1", acc)
+		assert.Nil(t, s.Snapshot(ctx, "account-1", v, acc))
+		_, _ = s.Append(ctx, "account-1", v, newDeposit("3", "1"))
+
+		acc = &stubAccount{}
+		v, err := s.Rehydrate(ctx, "account-1", acc)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, v)
+		assert.Equal(t, 16, acc.Balance)
+		assert.Equal(t, 1, acc.applied)
+	})
+}
@@ -0,0 +1,137 @@
+package quark
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Failure a Message processing failure, recorded into the Message headers when it is retried or sent into a Dead
+// Letter Queue (DLQ) so it can be triaged without correlating logs
+type Failure struct {
+	// Error processing error text (HeaderMessageError)
+	Error string
+	// ErrorType Go type of the error root cause (HeaderMessageErrorType)
+	ErrorType string
+	// Group consumer group which failed to process the Message (HeaderConsumerGroup)
+	Group string
+	// Host node which failed to process the Message (HeaderMessageHost)
+	Host string
+	// FirstTime when the Message processing failed for the first time (HeaderMessageFirstFailureTime)
+	FirstTime time.Time
+	// Time when the Message processing failed (HeaderMessageFailureTime)
+	Time time.Time
+}
+
+var (
+	hostOnce sync.Once
+	host     string
+)
+
+// hostname returns the current node hostname, empty if it could not be resolved
+func hostname() string {
+	hostOnce.Do(func() {
+		host, _ = os.Hostname()
+	})
+	return host
+}
+
+// NewFailure creates a Failure of the given error and consumer group, failed at the current time on this host
+func NewFailure(err error, group string) Failure {
+	f := Failure{
+		Group: group,
+		Host:  hostname(),
+		Time:  time.Now().UTC(),
+	}
+	if err != nil {
+		f.Error = err.Error()
+		f.ErrorType = errorType(err)
+	}
+	f.FirstTime = f.Time
+	return f
+}
+
+// errorType returns the Go type of the error root cause (e.g. *net.OpError instead of *fmt.wrapError)
+func errorType(err error) string {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return fmt.Sprintf("%T", err)
+		}
+		err = next
+	}
+}
+
+// Apply records the failure into the given Message headers, the first failure time of a previously failed Message
+// is kept. Empty values do not override existing headers
+func (f Failure) Apply(msg *Message) {
+	if msg.Metadata.ExternalData == nil {
+		msg.Metadata.ExternalData = map[string]string{}
+	}
+	if prev, ok := GetFailure(msg); ok && !prev.FirstTime.IsZero() {
+		f.FirstTime = prev.FirstTime
+	}
+	setNonEmpty(msg.Metadata.ExternalData, HeaderMessageError, f.Error)
+	setNonEmpty(msg.Metadata.ExternalData, HeaderMessageErrorType, f.ErrorType)
+	setNonEmpty(msg.Metadata.ExternalData, HeaderConsumerGroup, f.Group)
+	setNonEmpty(msg.Metadata.ExternalData, HeaderMessageFirstFailureTime, formatFailureTime(f.FirstTime))
+	setNonEmpty(msg.Metadata.ExternalData, HeaderMessageFailureTime, formatFailureTime(f.Time))
+	if f.Host != "" {
+		msg.Metadata.Host = f.Host
+	}
+}
+
+// GetFailure returns the Failure recorded into the given Message headers, false if no failure was recorded
+func GetFailure(msg *Message) (Failure, bool) {
+	data := msg.Metadata.ExternalData
+	if data[HeaderMessageError] == "" && data[HeaderMessageFailureTime] == "" {
+		return Failure{}, false
+	}
+	return Failure{
+		Error:     data[HeaderMessageError],
+		ErrorType: data[HeaderMessageErrorType],
+		Group:     data[HeaderConsumerGroup],
+		Host:      msg.Metadata.Host,
+		FirstTime: parseFailureTime(data[HeaderMessageFirstFailureTime]),
+		Time:      parseFailureTime(data[HeaderMessageFailureTime]),
+	}, true
+}
+
+// NewDeadLetterMessage builds the Dead Letter Queue (DLQ) message of the given failed message, recording the type it
+// was originally published to (HeaderMessageOriginalType) and its failure if err is not nil. The given message is not
+// modified
+func NewDeadLetterMessage(topic string, msg *Message, err error, group string) *Message {
+	m := *msg
+	m.Metadata.ExternalData = make(map[string]string, len(msg.Metadata.ExternalData)+1)
+	for k, v := range msg.Metadata.ExternalData {
+		m.Metadata.ExternalData[k] = v
+	}
+	if _, ok := m.Metadata.ExternalData[HeaderMessageOriginalType]; !ok {
+		m.Metadata.ExternalData[HeaderMessageOriginalType] = msg.Type
+	}
+	if err != nil {
+		NewFailure(err, group).Apply(&m)
+	}
+	m.Type = topic
+	return &m
+}
+
+func setNonEmpty(m map[string]string, k, v string) {
+	if v != "" {
+		m[k] = v
+	}
+}
+
+func formatFailureTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func parseFailureTime(v string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, v)
+	return t
+}
@@ -0,0 +1,62 @@
+package quark
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubFailureError struct{}
+
+func (stubFailureError) Error() string { return "stub failure" }
+
+var newFailureTestingSuite = []struct {
+	err      error
+	expError string
+	expType  string
+}{
+	{nil, "", ""},
+	{errors.New("cassandra: timeout"), "cassandra: timeout", "*errors.errorString"},
+	{fmt.Errorf("saving user: %w", stubFailureError{}), "saving user: stub failure", "quark.stubFailureError"},
+}
+
+func TestNewFailure(t *testing.T) {
+	for _, tt := range newFailureTestingSuite {
+		t.Run("New failure from error", func(t *testing.T) {
+			f := NewFailure(tt.err, "user-service")
+			assert.Equal(t, tt.expError, f.Error)
+			assert.Equal(t, tt.expType, f.ErrorType)
+			assert.Equal(t, "user-service", f.Group)
+			assert.Equal(t, hostname(), f.Host)
+			assert.False(t, f.Time.IsZero())
+			assert.Equal(t, f.Time, f.FirstTime)
+		})
+	}
+}
+
+func TestFailure_Apply(t *testing.T) {
+	t.Run("Keep first failure time", func(t *testing.T) {
+		msg := NewMessage("123", "foo", nil)
+		_, ok := GetFailure(msg)
+		assert.False(t, ok)
+
+		first := Failure{Error: "first", Group: "foo-group", Host: "10.0.0.1", FirstTime: time.Unix(100, 0).UTC(),
+			Time: time.Unix(100, 0).UTC()}
+		first.Apply(msg)
+		got, ok := GetFailure(msg)
+		assert.True(t, ok)
+		assert.Equal(t, first, got)
+
+		second := Failure{Error: "second", FirstTime: time.Unix(200, 0).UTC(), Time: time.Unix(200, 0).UTC()}
+		second.Apply(msg)
+		got, _ = GetFailure(msg)
+		assert.Equal(t, "second", got.Error)
+		assert.Equal(t, "foo-group", got.Group)
+		assert.Equal(t, "10.0.0.1", got.Host)
+		assert.Equal(t, time.Unix(100, 0).UTC(), got.FirstTime)
+		assert.Equal(t, time.Unix(200, 0).UTC(), got.Time)
+	})
+}
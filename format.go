@@ -2,6 +2,7 @@ package quark
 
 import (
 	"strconv"
+	"strings"
 )
 
 const (
@@ -20,6 +21,37 @@ func FormatTopicName(organization, service, kind, entity, action string, version
 	return organization + "." + service + "." + strconv.Itoa(version) + "." + kind + "." + entity + "." + action
 }
 
+// TopicName is an Async API topic name split into its segments (see FormatTopicName)
+type TopicName struct {
+	Organization string
+	Service      string
+	Version      int
+	Kind         string
+	Entity       string
+	Action       string
+}
+
+// ParseTopicName splits the given Async API topic name into its segments, returns false if the topic does not follow
+// the FormatTopicName format
+func ParseTopicName(topic string) (TopicName, bool) {
+	segments := strings.Split(topic, ".")
+	if len(segments) != 6 {
+		return TopicName{}, false
+	}
+	version, err := strconv.Atoi(segments[2])
+	if err != nil {
+		return TopicName{}, false
+	}
+	return TopicName{
+		Organization: segments[0],
+		Service:      segments[1],
+		Version:      version,
+		Kind:         segments[3],
+		Entity:       segments[4],
+		Action:       segments[5],
+	}, true
+}
+
 // FormatQueueName forms an Async API queue name
 //	format e.g. "service.entity.action_on_event
 func FormatQueueName(service, entity, action, event string) string {
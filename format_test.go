@@ -14,6 +14,26 @@ func TestFormatTopicName(t *testing.T) {
 	})
 }
 
+var parseTopicNameTestingSuite = []struct {
+	topic    string
+	expected TopicName
+	ok       bool
+}{
+	{"neutrino.payment.1.event.user.paid", TopicName{"neutrino", "payment", 1, DomainEvent, "user", "paid"}, true},
+	{"neutrino.payment.v1.event.user.paid", TopicName{}, false},
+	{"chat.0", TopicName{}, false},
+}
+
+func TestParseTopicName(t *testing.T) {
+	for _, tt := range parseTopicNameTestingSuite {
+		t.Run("Parse Async API topic name", func(t *testing.T) {
+			name, ok := ParseTopicName(tt.topic)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, name)
+		})
+	}
+}
+
 func BenchmarkFormatTopicName(b *testing.B) {
 	b.Run("Form a valid Async API topic name", func(b *testing.B) {
 		b.ReportAllocs()
@@ -31,6 +31,19 @@ const (
 	HeaderMessageRedeliveryCount = "quark-metadata-redelivery-count"
 	// HeaderMessageError Message error message from processing pipeline
 	HeaderMessageError = "quark-metadata-error"
+	// HeaderMessageErrorType Go type of the error (root cause) returned from processing pipeline
+	HeaderMessageErrorType = "quark-metadata-error-type"
+	// HeaderMessageFirstFailureTime Time when Message processing failed for the first time
+	HeaderMessageFirstFailureTime = "quark-metadata-first-failure-time"
+	// HeaderMessageFailureTime Time when Message processing failed for the last time
+	HeaderMessageFailureTime = "quark-metadata-failure-time"
+	// HeaderMessageOriginalType Message type (topic) a message was published to before being sent into a Dead Letter
+	// Queue (DLQ)
+	HeaderMessageOriginalType = "quark-metadata-original-type"
+	// HeaderMessageKind Message kind, either a Command or a DomainEvent
+	HeaderMessageKind = "quark-metadata-kind"
+	// HeaderMessageReplyTo Topic a reply must be published to, used by request-reply mechanisms
+	HeaderMessageReplyTo = "quark-metadata-reply-to"
 
 	// HeaderConsumerGroup Consumer group this message was received by
 	HeaderConsumerGroup = "quark-consumer-group"
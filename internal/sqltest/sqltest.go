@@ -0,0 +1,23 @@
+// Package sqltest SQLite fixtures shared by the tests of database/sql backed storages.
+package sqltest
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3" // registers the sqlite3 driver
+	"github.com/stretchr/testify/require"
+)
+
+// Open opens the given SQLite database file inside a temporary directory of the test, the database is closed once
+// the test finishes.
+//
+// Concurrent writers wait for the database lock instead of failing
+func Open(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), name)+"?_busy_timeout=5000")
+	require.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
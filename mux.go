@@ -21,6 +21,16 @@ type EventMux interface {
 	List() map[string][]*Consumer
 }
 
+// ConsumerRemover is an EventMux able to remove a single Consumer, required to remove Consumer(s) from a serving
+// Broker (see Broker.RemoveConsumer)
+type ConsumerRemover interface {
+	EventMux
+	// Remove removes an specific consumer from every topic of the local registry
+	Remove(c *Consumer)
+}
+
+var _ ConsumerRemover = &defaultMux{}
+
 type defaultMux struct {
 	consumers map[string][]*Consumer
 	mu        sync.RWMutex
@@ -90,6 +100,28 @@ func (b *defaultMux) Del(topic string) {
 	delete(b.consumers, topic)
 }
 
+func (b *defaultMux) Remove(c *Consumer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c == nil {
+		return
+	}
+	for _, t := range c.topics {
+		consumers := b.consumers[t]
+		for i, cs := range consumers {
+			if cs == c {
+				consumers = append(consumers[:i], consumers[i+1:]...)
+				break
+			}
+		}
+		if len(consumers) == 0 {
+			delete(b.consumers, t)
+			continue
+		}
+		b.consumers[t] = consumers
+	}
+}
+
 func (b *defaultMux) Contains(topic string) bool {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
@@ -100,5 +132,31 @@ func (b *defaultMux) Contains(topic string) bool {
 func (b *defaultMux) List() map[string][]*Consumer {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	return b.consumers
+	consumers := make(map[string][]*Consumer, len(b.consumers))
+	for t, cs := range b.consumers {
+		consumers[t] = append([]*Consumer(nil), cs...)
+	}
+	return consumers
+}
+
+// consumerHasTopic verifies if the given consumer is subscribed to the given topic
+func consumerHasTopic(c *Consumer, topic string) bool {
+	for _, t := range c.topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// muxContains verifies if the given consumer exists in the mux registry
+func muxContains(mux EventMux, c *Consumer) bool {
+	for _, t := range c.topics {
+		for _, cs := range mux.Get(t) {
+			if cs == c {
+				return true
+			}
+		}
+	}
+	return false
 }
@@ -94,6 +94,44 @@ func TestDefaultMux_Del(t *testing.T) {
 	}
 }
 
+var defaultMuxRemoveTestingSuite = []struct {
+	c        *Consumer
+	expected int
+}{
+	{c: &Consumer{topics: []string{"alex.trades", "bob.trades"}}, expected: 1},
+	{c: &Consumer{topics: []string{"bob.trades"}}, expected: 1},
+	{c: nil, expected: 1},
+}
+
+func TestDefaultMux_Remove(t *testing.T) {
+	for _, tt := range defaultMuxRemoveTestingSuite {
+		t.Run("Default mux remove consumer", func(t *testing.T) {
+			mux := NewMux()
+			mux.Topic("alex.trades")
+			mux.Add(tt.c)
+			mux.(ConsumerRemover).Remove(tt.c)
+			assert.Equal(t, tt.expected, len(mux.List()))
+			assert.Equal(t, 1, len(mux.Get("alex.trades")))
+		})
+	}
+}
+
+// plainMux hides the optional ConsumerRemover extension
+type plainMux struct {
+	EventMux
+}
+
+func TestBroker_RemoveConsumer(t *testing.T) {
+	t.Run("Broker remove consumer without mux support", func(t *testing.T) {
+		b := NewBroker()
+		b.EventMux = plainMux{NewMux()}
+		c := NewConsumer("alex.trades")
+		assert.Nil(t, b.AddConsumer(c))
+		assert.Equal(t, ErrRemoveNotSupported, b.RemoveConsumer(c))
+		assert.True(t, b.EventMux.Contains("alex.trades"))
+	})
+}
+
 func BenchmarkMux(b *testing.B) {
 	mux := NewMux()
 	mux.Topics("foo", "bar", "baz")
@@ -27,6 +27,11 @@ type options struct {
 	baseMessageSource      string
 	baseMessageContentType string
 	baseContext            context.Context
+	replyTopic             string
+	restartPolicy          *RestartPolicy
+	startupMode            StartupMode
+	panicPolicy            *PanicPolicy
+	handlerTimeout         time.Duration
 }
 
 type clusterOption []string
@@ -240,3 +245,62 @@ func (o baseContextOption) apply(opts *options) {
 func WithBaseContext(ctx context.Context) Option {
 	return baseContextOption{Ctx: ctx}
 }
+
+type replyTopicOption string
+
+func (o replyTopicOption) apply(opts *options) {
+	opts.replyTopic = string(o)
+}
+
+// WithReplyTopic defines the topic a Broker instance will receive replies from when using request-reply mechanisms.
+//
+// Must be unique per-instance (e.g. "replies.<hostname>")
+func WithReplyTopic(topic string) Option {
+	return replyTopicOption(topic)
+}
+
+type restartPolicyOption RestartPolicy
+
+func (o restartPolicyOption) apply(opts *options) {
+	p := RestartPolicy(o)
+	opts.restartPolicy = &p
+}
+
+// WithRestartPolicy defines how Supervisor(s) restart their crashed workers (default DefaultRestartPolicy)
+func WithRestartPolicy(p RestartPolicy) Option {
+	return restartPolicyOption(p)
+}
+
+type startupModeOption StartupMode
+
+func (o startupModeOption) apply(opts *options) {
+	opts.startupMode = StartupMode(o)
+}
+
+// WithStartupMode defines how a Broker handles supervisors failing to start (default StartupAtomic)
+func WithStartupMode(m StartupMode) Option {
+	return startupModeOption(m)
+}
+
+type panicPolicyOption PanicPolicy
+
+func (o panicPolicyOption) apply(opts *options) {
+	p := PanicPolicy(o)
+	opts.panicPolicy = &p
+}
+
+// WithPanicPolicy defines how Events whose handler panicked are handled (default DefaultPanicPolicy)
+func WithPanicPolicy(p PanicPolicy) Option {
+	return panicPolicyOption(p)
+}
+
+type handlerTimeoutOption time.Duration
+
+func (o handlerTimeoutOption) apply(opts *options) {
+	opts.handlerTimeout = time.Duration(o)
+}
+
+// WithHandlerTimeout maximum time a handler may take to serve an Event (default no timeout)
+func WithHandlerTimeout(d time.Duration) Option {
+	return handlerTimeoutOption(d)
+}
@@ -0,0 +1,85 @@
+// Package outbox Transactional outbox pattern implementation for Quark.
+//
+// A Publisher stores every Message inside the caller's database transaction (*sql.Tx), so messages are only
+// persisted if the business transaction commits. Then, a Relay forwards stored messages to the actual
+// Event-Driven ecosystem publisher (e.g. Apache Kafka) with at-least-once delivery semantics, keeping the publishing
+// order per-aggregate.
+//
+// The Relay only keeps the publishing order, the order messages are stored by the actual Event-Driven ecosystem
+// depends on its publisher. For instance, Apache Kafka publishers key messages by their id by default, so records of
+// the same aggregate might land in different partitions; use an aggregate-keyed strategy matching the
+// AggregateKeyFunc (e.g. kafka.KeyBySubject along with DefaultAggregateKey) to keep them ordered.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/neutrinocorp/quark"
+)
+
+var (
+	// ErrTxNotFound no database transaction was found in the given context
+	ErrTxNotFound = errors.New("outbox: transaction not found in context")
+	// ErrNilStore the given outbox store is nil
+	ErrNilStore = errors.New("outbox: store is nil")
+	// ErrRecordDeadLettered the record failed to be forwarded too many times and will not be forwarded anymore
+	ErrRecordDeadLettered = errors.New("outbox: record dead-lettered")
+)
+
+// Record a Message stored in the outbox waiting to be forwarded
+type Record struct {
+	// Sequence outbox insertion order, used to forward messages in order
+	Sequence int64
+	// AggregateId ordering key, messages sharing the same aggregate are forwarded in insertion order
+	AggregateId string
+	// Message actual Message to forward
+	Message *quark.Message
+	// Attempts number of failed forwarding attempts
+	Attempts int
+}
+
+// Store outbox storage used by a Relay to forward pending records
+type Store interface {
+	// Fetch returns up to limit pending records ordered by Sequence, dead-lettered records are not pending
+	Fetch(ctx context.Context, limit int) ([]Record, error)
+	// MarkSent flags the given record as forwarded
+	MarkSent(ctx context.Context, sequence int64) error
+	// MarkFailed increments the failed forwarding attempts of the given record
+	MarkFailed(ctx context.Context, sequence int64) error
+	// MarkDead flags the given record as dead-lettered, it is kept in the store for inspection but not forwarded
+	MarkDead(ctx context.Context, sequence int64) error
+}
+
+// TxStore outbox storage used by a Publisher to write records inside the caller's database transaction
+type TxStore interface {
+	// Insert stores the given records inside the given transaction
+	Insert(ctx context.Context, tx *sql.Tx, records ...Record) error
+}
+
+// AggregateKeyFunc returns the ordering key of the given Message
+type AggregateKeyFunc func(*quark.Message) string
+
+// DefaultAggregateKey uses the Message Subject as ordering key. Falls back to CorrelationId and then to the Message Id
+var DefaultAggregateKey AggregateKeyFunc = func(msg *quark.Message) string {
+	if msg.Subject != "" {
+		return msg.Subject
+	} else if msg.Metadata.CorrelationId != "" {
+		return msg.Metadata.CorrelationId
+	}
+	return msg.Id
+}
+
+type txContextKey struct{}
+
+// WithTx returns a copy of ctx carrying the given database transaction, a Publisher will write into it
+func WithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext returns the database transaction stored in ctx, if any
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*sql.Tx)
+	return tx, ok && tx != nil
+}
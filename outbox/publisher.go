@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/neutrinocorp/quark"
+)
+
+// Publisher quark.Publisher writing messages into the outbox table instead of the actual Event-Driven ecosystem.
+//
+// The database transaction must be attached into the given context using WithTx, so messages are persisted
+// atomically with the caller's business data.
+//
+//	e.g. w.WriteMessage(outbox.WithTx(ctx, tx), msg)
+type Publisher struct {
+	Store        TxStore
+	AggregateKey AggregateKeyFunc
+}
+
+var _ quark.Publisher = &Publisher{}
+
+// NewPublisher allocates and returns an outbox Publisher
+func NewPublisher(s TxStore) *Publisher {
+	return &Publisher{
+		Store:        s,
+		AggregateKey: DefaultAggregateKey,
+	}
+}
+
+// Publish stores the given messages into the transaction found in ctx
+func (p *Publisher) Publish(ctx context.Context, msgs ...*quark.Message) error {
+	tx, ok := TxFromContext(ctx)
+	if !ok {
+		return ErrTxNotFound
+	}
+	return p.PublishTx(ctx, tx, msgs...)
+}
+
+// PublishTx stores the given messages into the given transaction
+func (p *Publisher) PublishTx(ctx context.Context, tx *sql.Tx, msgs ...*quark.Message) error {
+	if p.Store == nil {
+		return ErrNilStore
+	}
+	records := make([]Record, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg == nil {
+			return quark.ErrEmptyMessage
+		}
+		records = append(records, Record{
+			AggregateId: p.setDefaultAggregateKey()(msg),
+			Message:     msg,
+		})
+	}
+	return p.Store.Insert(ctx, tx, records...)
+}
+
+func (p *Publisher) setDefaultAggregateKey() AggregateKeyFunc {
+	if p.AggregateKey != nil {
+		return p.AggregateKey
+	}
+	return DefaultAggregateKey
+}
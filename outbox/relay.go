@@ -0,0 +1,146 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/neutrinocorp/quark"
+)
+
+var (
+	defaultBatchSize    = 100
+	defaultPollInterval = time.Second
+	defaultMaxAttempts  = 10
+)
+
+// Relay forwards outbox records to the actual Event-Driven ecosystem publisher.
+//
+// Delivery is at-least-once, a record is marked as sent only after the Publisher acknowledged it. If forwarding
+// a record fails, every following record of the same aggregate is held back until the next poll to keep ordering.
+//
+// Records failing MaxAttempts times are dead-lettered (see Store.MarkDead) so a poison record does not hold its
+// aggregate, nor fill every batch, forever. Following records of its aggregate are forwarded from the next poll on.
+//
+// Only one Relay should be running per outbox table, otherwise per-aggregate ordering is not guaranteed.
+type Relay struct {
+	Store        Store
+	Publisher    quark.Publisher
+	ErrorHandler quark.ErrorHandler
+	BatchSize    int
+	PollInterval time.Duration
+	// MaxAttempts failed forwarding attempts before a record is dead-lettered (default 10)
+	MaxAttempts int
+}
+
+// NewRelay allocates and returns a Relay
+func NewRelay(s Store, p quark.Publisher) *Relay {
+	return &Relay{
+		Store:        s,
+		Publisher:    p,
+		BatchSize:    defaultBatchSize,
+		PollInterval: defaultPollInterval,
+		MaxAttempts:  defaultMaxAttempts,
+	}
+}
+
+// Run forwards pending records every PollInterval until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) error {
+	if r.Store == nil {
+		return ErrNilStore
+	} else if r.Publisher == nil {
+		return quark.ErrPublisherNotImplemented
+	}
+
+	ticker := time.NewTicker(r.setDefaultPollInterval())
+	defer ticker.Stop()
+	for {
+		n, err := r.Forward(ctx)
+		if err != nil && r.ErrorHandler != nil {
+			r.ErrorHandler(ctx, err)
+		}
+		if n == r.setDefaultBatchSize() && err == nil {
+			// backlog is not empty yet, do not wait for the next tick
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Forward publishes a single batch of pending records.
+//
+// Returns the number of records forwarded
+func (r *Relay) Forward(ctx context.Context) (int, error) {
+	records, err := r.Store.Fetch(ctx, r.setDefaultBatchSize())
+	if err != nil {
+		return 0, err
+	}
+
+	errs := new(multierror.Error)
+	held := map[string]struct{}{}
+	forwarded := 0
+	for _, rec := range records {
+		if _, ok := held[rec.AggregateId]; ok {
+			continue
+		}
+		if err = r.Publisher.Publish(ctx, rec.Message); err != nil {
+			held[rec.AggregateId] = struct{}{}
+			errs = multierror.Append(errs, fmt.Errorf("outbox: record %d: %w", rec.Sequence, err))
+			if err = r.fail(ctx, rec); err != nil {
+				errs = multierror.Append(errs, err)
+			}
+			continue
+		}
+		if err = r.Store.MarkSent(ctx, rec.Sequence); err != nil {
+			// record will be forwarded again, hold the aggregate back to avoid re-ordering
+			held[rec.AggregateId] = struct{}{}
+			errs = multierror.Append(errs, fmt.Errorf("outbox: record %d: %w", rec.Sequence, err))
+			continue
+		}
+		forwarded++
+	}
+	return forwarded, errs.ErrorOrNil()
+}
+
+// fail records a failed forwarding attempt of the given record, dead-lettering it once it reaches MaxAttempts
+func (r *Relay) fail(ctx context.Context, rec Record) error {
+	if rec.Attempts+1 < r.setDefaultMaxAttempts() {
+		if err := r.Store.MarkFailed(ctx, rec.Sequence); err != nil {
+			return fmt.Errorf("outbox: record %d: %w", rec.Sequence, err)
+		}
+		return nil
+	}
+	if err := r.Store.MarkDead(ctx, rec.Sequence); err != nil {
+		return fmt.Errorf("outbox: record %d: %w", rec.Sequence, err)
+	}
+	return fmt.Errorf("outbox: record %d: %w", rec.Sequence, ErrRecordDeadLettered)
+}
+
+func (r *Relay) setDefaultBatchSize() int {
+	if r.BatchSize > 0 {
+		return r.BatchSize
+	}
+	return defaultBatchSize
+}
+
+func (r *Relay) setDefaultPollInterval() time.Duration {
+	if r.PollInterval > 0 {
+		return r.PollInterval
+	}
+	return defaultPollInterval
+}
+
+func (r *Relay) setDefaultMaxAttempts() int {
+	if r.MaxAttempts > 0 {
+		return r.MaxAttempts
+	}
+	return defaultMaxAttempts
+}
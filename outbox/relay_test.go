@@ -0,0 +1,176 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubStore struct {
+	mu      sync.Mutex
+	records []Record
+	sent    map[int64]bool
+	dead    map[int64]bool
+}
+
+func newStubStore(records ...Record) *stubStore {
+	return &stubStore{records: records, sent: map[int64]bool{}, dead: map[int64]bool{}}
+}
+
+func (s *stubStore) Fetch(_ context.Context, limit int) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := make([]Record, 0)
+	for _, r := range s.records {
+		if !s.sent[r.Sequence] && !s.dead[r.Sequence] && len(pending) < limit {
+			pending = append(pending, r)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Sequence < pending[j].Sequence })
+	return pending, nil
+}
+
+func (s *stubStore) MarkSent(_ context.Context, sequence int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent[sequence] = true
+	return nil
+}
+
+func (s *stubStore) MarkFailed(_ context.Context, sequence int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.records {
+		if s.records[i].Sequence == sequence {
+			s.records[i].Attempts++
+		}
+	}
+	return nil
+}
+
+func (s *stubStore) MarkDead(ctx context.Context, sequence int64) error {
+	_ = s.MarkFailed(ctx, sequence)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dead[sequence] = true
+	return nil
+}
+
+var errStubPublisher = errors.New("generic stub publisher error")
+
+type stubPublisher struct {
+	failIds   map[string]bool
+	published []string
+}
+
+func (p *stubPublisher) Publish(_ context.Context, msgs ...*quark.Message) error {
+	for _, m := range msgs {
+		if p.failIds[m.Id] {
+			return errStubPublisher
+		}
+		p.published = append(p.published, m.Id)
+	}
+	return nil
+}
+
+func newRecord(seq int64, aggregate, id string) Record {
+	return Record{
+		Sequence:    seq,
+		AggregateId: aggregate,
+		Message:     quark.NewMessage(id, "foo.created", nil),
+	}
+}
+
+func TestRelay_Forward(t *testing.T) {
+	t.Run("Relay forward every pending record in order", func(t *testing.T) {
+		s := newStubStore(newRecord(1, "a", "1"), newRecord(2, "b", "2"), newRecord(3, "a", "3"))
+		p := &stubPublisher{}
+		r := NewRelay(s, p)
+		n, err := r.Forward(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, []string{"1", "2", "3"}, p.published)
+
+		n, err = r.Forward(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("Relay hold back failed aggregate", func(t *testing.T) {
+		s := newStubStore(newRecord(1, "a", "1"), newRecord(2, "b", "2"), newRecord(3, "a", "3"))
+		p := &stubPublisher{failIds: map[string]bool{"1": true}}
+		r := NewRelay(s, p)
+		n, err := r.Forward(context.Background())
+		assert.True(t, errors.Is(err, errStubPublisher))
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []string{"2"}, p.published)
+
+		p.failIds = nil
+		n, err = r.Forward(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []string{"2", "1", "3"}, p.published)
+	})
+
+	t.Run("Relay dead-letter poison record", func(t *testing.T) {
+		s := newStubStore(newRecord(1, "a", "1"), newRecord(2, "a", "2"), newRecord(3, "b", "3"))
+		p := &stubPublisher{failIds: map[string]bool{"1": true}}
+		r := NewRelay(s, p)
+		r.MaxAttempts = 2
+		r.BatchSize = 2 // the poison aggregate fills the whole batch
+		n, err := r.Forward(context.Background())
+		assert.True(t, errors.Is(err, errStubPublisher))
+		assert.False(t, errors.Is(err, ErrRecordDeadLettered))
+		assert.Equal(t, 0, n)
+		assert.Equal(t, 1, s.records[0].Attempts)
+
+		n, err = r.Forward(context.Background())
+		assert.True(t, errors.Is(err, ErrRecordDeadLettered))
+		assert.Equal(t, 0, n)
+		assert.True(t, s.dead[1])
+
+		n, err = r.Forward(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []string{"2", "3"}, p.published)
+	})
+
+	t.Run("Relay respect batch size", func(t *testing.T) {
+		s := newStubStore(newRecord(1, "a", "1"), newRecord(2, "b", "2"), newRecord(3, "c", "3"))
+		r := NewRelay(s, &stubPublisher{})
+		r.BatchSize = 2
+		n, err := r.Forward(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 2, n)
+	})
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	t.Run("Outbox publisher without transaction", func(t *testing.T) {
+		p := NewPublisher(NewSQLStore(nil, 0))
+		err := p.Publish(context.Background(), quark.NewMessage("1", "foo.created", nil))
+		assert.Equal(t, ErrTxNotFound, err)
+	})
+}
+
+var defaultAggregateKeyTestingSuite = []struct {
+	msg *quark.Message
+	exp string
+}{
+	{&quark.Message{Id: "1", Subject: "order-1", Metadata: quark.MessageMetadata{CorrelationId: "2"}}, "order-1"},
+	{&quark.Message{Id: "1", Metadata: quark.MessageMetadata{CorrelationId: "2"}}, "2"},
+	{&quark.Message{Id: "1"}, "1"},
+}
+
+func TestDefaultAggregateKey(t *testing.T) {
+	for _, tt := range defaultAggregateKeyTestingSuite {
+		t.Run("Outbox default aggregate key", func(t *testing.T) {
+			assert.Equal(t, tt.exp, DefaultAggregateKey(tt.msg))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/neutrinocorp/quark/dialect"
+)
+
+// DefaultTable default outbox table name
+const DefaultTable = "quark_outbox"
+
+// SQLStore outbox Store backed by a database/sql compatible database (e.g. Postgres, SQLite)
+//
+// Messages are stored as JSON using the CNCF CloudEvents attribute names.
+type SQLStore struct {
+	DB      *sql.DB
+	Dialect dialect.Dialect
+	Table   string
+}
+
+var (
+	_ Store   = &SQLStore{}
+	_ TxStore = &SQLStore{}
+)
+
+// NewSQLStore allocates and returns a SQLStore using the DefaultTable
+func NewSQLStore(db *sql.DB, d dialect.Dialect) *SQLStore {
+	return &SQLStore{
+		DB:      db,
+		Dialect: d,
+		Table:   DefaultTable,
+	}
+}
+
+// Migrate creates the outbox table if it does not exist
+func (s *SQLStore) Migrate(ctx context.Context) error {
+	_, err := s.DB.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+s.setDefaultTable()+` (
+	sequence `+s.Dialect.SerialPrimaryKey()+`,
+	aggregate_id VARCHAR(255) NOT NULL,
+	topic VARCHAR(255) NOT NULL,
+	message_id VARCHAR(255) NOT NULL,
+	payload TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	sent_at TIMESTAMP NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	dead_at TIMESTAMP NULL
+)`)
+	return err
+}
+
+// Insert stores the given records inside the given transaction
+func (s *SQLStore) Insert(ctx context.Context, tx *sql.Tx, records ...Record) error {
+	query := s.Dialect.Rebind(`INSERT INTO ` + s.setDefaultTable() +
+		` (aggregate_id, topic, message_id, payload, created_at) VALUES (?, ?, ?, ?, ?)`)
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+	for _, rec := range records {
+		payload, err := json.Marshal(rec.Message)
+		if err != nil {
+			return err
+		}
+		if _, err = stmt.ExecContext(ctx, rec.AggregateId, rec.Message.Type, rec.Message.Id, string(payload),
+			now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fetch returns up to limit pending records ordered by Sequence, dead-lettered records are not pending
+func (s *SQLStore) Fetch(ctx context.Context, limit int) ([]Record, error) {
+	query := s.Dialect.Rebind(`SELECT sequence, aggregate_id, payload, attempts FROM ` + s.setDefaultTable() +
+		` WHERE sent_at IS NULL AND dead_at IS NULL ORDER BY sequence ASC LIMIT ?`)
+	rows, err := s.DB.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]Record, 0, limit)
+	for rows.Next() {
+		rec := Record{Message: new(quark.Message)}
+		payload := ""
+		if err = rows.Scan(&rec.Sequence, &rec.AggregateId, &payload, &rec.Attempts); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(payload), rec.Message); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// MarkSent flags the given record as forwarded
+func (s *SQLStore) MarkSent(ctx context.Context, sequence int64) error {
+	query := s.Dialect.Rebind(`UPDATE ` + s.setDefaultTable() + ` SET sent_at = ? WHERE sequence = ?`)
+	_, err := s.DB.ExecContext(ctx, query, time.Now().UTC(), sequence)
+	return err
+}
+
+// MarkFailed increments the failed forwarding attempts of the given record
+func (s *SQLStore) MarkFailed(ctx context.Context, sequence int64) error {
+	query := s.Dialect.Rebind(`UPDATE ` + s.setDefaultTable() + ` SET attempts = attempts + 1 WHERE sequence = ?`)
+	_, err := s.DB.ExecContext(ctx, query, sequence)
+	return err
+}
+
+// MarkDead flags the given record as dead-lettered, it is kept in the table for inspection but not forwarded
+func (s *SQLStore) MarkDead(ctx context.Context, sequence int64) error {
+	query := s.Dialect.Rebind(`UPDATE ` + s.setDefaultTable() +
+		` SET attempts = attempts + 1, dead_at = ? WHERE sequence = ?`)
+	_, err := s.DB.ExecContext(ctx, query, time.Now().UTC(), sequence)
+	return err
+}
+
+// Purge removes every forwarded record older than the given time
+func (s *SQLStore) Purge(ctx context.Context, before time.Time) (int64, error) {
+	query := s.Dialect.Rebind(`DELETE FROM ` + s.setDefaultTable() + ` WHERE sent_at IS NOT NULL AND sent_at < ?`)
+	res, err := s.DB.ExecContext(ctx, query, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *SQLStore) setDefaultTable() string {
+	if s.Table != "" {
+		return s.Table
+	}
+	return DefaultTable
+}
@@ -0,0 +1,94 @@
+package outbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/neutrinocorp/quark/dialect"
+	"github.com/neutrinocorp/quark/internal/sqltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLiteTestingStore(t *testing.T) *SQLStore {
+	s := NewSQLStore(sqltest.Open(t, "outbox.db"), dialect.SQLite)
+	require.Nil(t, s.Migrate(context.Background()))
+	return s
+}
+
+func TestSQLStore(t *testing.T) {
+	ctx := context.Background()
+	t.Run("SQL store insert in caller transaction", func(t *testing.T) {
+		s := newSQLiteTestingStore(t)
+		p := NewPublisher(s)
+
+		tx, err := s.DB.BeginTx(ctx, nil)
+		require.Nil(t, err)
+		assert.Nil(t, p.Publish(WithTx(ctx, tx), quark.NewMessage("1", "orders.placed", nil)))
+		require.Nil(t, tx.Rollback())
+		records, err := s.Fetch(ctx, 10)
+		assert.Nil(t, err)
+		assert.Empty(t, records)
+
+		tx, err = s.DB.BeginTx(ctx, nil)
+		require.Nil(t, err)
+		assert.Nil(t, p.Publish(WithTx(ctx, tx), quark.NewMessage("2", "orders.placed", nil)))
+		require.Nil(t, tx.Commit())
+		records, err = s.Fetch(ctx, 10)
+		assert.Nil(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "2", records[0].Message.Id)
+		assert.Equal(t, "orders.placed", records[0].Message.Type)
+	})
+
+	t.Run("SQL store fetch pending records in order and mark sent", func(t *testing.T) {
+		s := newSQLiteTestingStore(t)
+		tx, err := s.DB.BeginTx(ctx, nil)
+		require.Nil(t, err)
+		assert.Nil(t, NewPublisher(s).PublishTx(ctx, tx,
+			quark.NewMessage("1", "orders.placed", nil),
+			quark.NewMessage("2", "orders.paid", nil),
+			quark.NewMessage("3", "orders.shipped", nil)))
+		require.Nil(t, tx.Commit())
+
+		records, err := s.Fetch(ctx, 2)
+		assert.Nil(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, "1", records[0].Message.Id)
+		assert.Equal(t, "2", records[1].Message.Id)
+		assert.True(t, records[0].Sequence < records[1].Sequence)
+
+		assert.Nil(t, s.MarkSent(ctx, records[0].Sequence))
+		records, err = s.Fetch(ctx, 10)
+		assert.Nil(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, "2", records[0].Message.Id)
+		assert.Equal(t, "3", records[1].Message.Id)
+	})
+	t.Run("SQL store record failed attempts and dead-letter", func(t *testing.T) {
+		s := newSQLiteTestingStore(t)
+		tx, err := s.DB.BeginTx(ctx, nil)
+		require.Nil(t, err)
+		assert.Nil(t, NewPublisher(s).PublishTx(ctx, tx,
+			quark.NewMessage("1", "orders.placed", nil),
+			quark.NewMessage("2", "orders.paid", nil)))
+		require.Nil(t, tx.Commit())
+
+		records, err := s.Fetch(ctx, 10)
+		require.Nil(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, 0, records[0].Attempts)
+		assert.Nil(t, s.MarkFailed(ctx, records[0].Sequence))
+		records, err = s.Fetch(ctx, 10)
+		require.Nil(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, 1, records[0].Attempts)
+
+		assert.Nil(t, s.MarkDead(ctx, records[0].Sequence))
+		records, err = s.Fetch(ctx, 10)
+		assert.Nil(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "2", records[0].Message.Id)
+	})
+}
@@ -0,0 +1,109 @@
+package quark
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// PanicAction defines what happens to an Event after its handler panicked
+type PanicAction int
+
+const (
+	// PanicNack the Event is not acknowledged, so the provider may deliver it again
+	PanicNack PanicAction = iota
+	// PanicRetry the Event is published again into its topic (see WriteRetryError), it is not
+	// acknowledged if it was redelivered too much
+	PanicRetry
+	// PanicDeadLetter the Event is published into the PanicPolicy DeadLetterTopic
+	PanicDeadLetter
+	// PanicSkip the Event is acknowledged and ignored
+	PanicSkip
+	// PanicCrash the panic is propagated as a *PanicError holding the original stack trace, crashing the process
+	PanicCrash
+)
+
+// PanicPolicy defines how handler panics are handled once recovered
+type PanicPolicy struct {
+	Action PanicAction
+	// DeadLetterTopic topic recovered Events are published to when using PanicDeadLetter, Events are not
+	// acknowledged if empty
+	DeadLetterTopic string
+}
+
+// DefaultPanicPolicy does not acknowledge Events whose handler panicked
+var DefaultPanicPolicy = PanicPolicy{Action: PanicNack}
+
+// PanicError is a recovered handler panic, matches ErrHandlerPanic
+type PanicError struct {
+	Topic string
+	// Value passed to panic
+	Value interface{}
+	// Stack trace of the panicking goroutine
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("topic %s: %s: %v\n%s", e.Topic, ErrHandlerPanic, e.Value, e.Stack)
+}
+
+// Is reports PanicError matches ErrHandlerPanic
+func (e *PanicError) Is(target error) bool {
+	return target == ErrHandlerPanic
+}
+
+// Unwrap returns the panic value if it is an error
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+func (n *Supervisor) recoverPanic(r interface{}, w EventWriter, e *Event) bool {
+	atomic.AddInt64(&n.panics, 1)
+	errPanic := &PanicError{Topic: e.Topic, Value: r, Stack: debug.Stack()}
+	n.reportEventError(e, errPanic)
+	policy := n.setDefaultPanicPolicy()
+	switch policy.Action {
+	case PanicCrash:
+		panic(errPanic) // re-panicking with r would lose the stack trace of the handler
+	case PanicSkip:
+		return true
+	case PanicRetry:
+		return n.retry(w, e, errPanic)
+	case PanicDeadLetter:
+		if err := n.deadLetter(policy.DeadLetterTopic, w, e, errPanic); err != nil {
+			n.reportEventError(e, fmt.Errorf("topic %s: dead-letter recovered event: %w", e.Topic, err))
+			return false
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// deadLetter publishes the Event body into the given DLQ topic bypassing the EventWriter redelivery cap
+func (n *Supervisor) deadLetter(topic string, w EventWriter, e *Event, cause error) error {
+	if topic == "" {
+		return ErrNotEnoughTopics
+	} else if w.Publisher() == nil {
+		return ErrPublisherNotImplemented
+	} else if e.Body == nil {
+		return ErrEmptyMessage
+	}
+	body := e.Body
+	if body.Type == "" {
+		cp := *body
+		cp.Type = e.Topic
+		body = &cp
+	}
+	return w.Publisher().Publish(e.Context, NewDeadLetterMessage(topic, body, cause, n.GetGroup()))
+}
+
+func (n *Supervisor) setDefaultPanicPolicy() PanicPolicy {
+	if p := n.Consumer.panicPolicy; p != nil {
+		return *p
+	}
+	return n.Broker.setDefaultPanicPolicy() // use global
+}
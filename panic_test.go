@@ -0,0 +1,109 @@
+package quark
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	mu        sync.Mutex
+	published []*Message
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, msgs ...*Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.published = append(p.published, msgs...)
+	return nil
+}
+
+var errPanicStub = errors.New("nil map assignment")
+
+var dispatchPanicTestingSuite = []struct {
+	description string
+	policy      *PanicPolicy
+	redelivery  int
+	expAck      bool
+	expType     string
+}{
+	{"Nack recovered event by default", nil, 0, false, ""},
+	{"Skip recovered event", &PanicPolicy{Action: PanicSkip}, 0, true, ""},
+	{"Retry recovered event", &PanicPolicy{Action: PanicRetry}, 0, true, "foo"},
+	{"Nack recovered event redelivered too much", &PanicPolicy{Action: PanicRetry}, 5, false, ""},
+	{"Dead-letter recovered event", &PanicPolicy{Action: PanicDeadLetter, DeadLetterTopic: "dlq.foo"}, 0, true,
+		"dlq.foo"},
+	{"Nack recovered event without dead-letter topic", &PanicPolicy{Action: PanicDeadLetter}, 0, false, ""},
+}
+
+func TestSupervisor_Dispatch(t *testing.T) {
+	for _, tt := range dispatchPanicTestingSuite {
+		t.Run(tt.description, func(t *testing.T) {
+			var reported []error
+			b := NewBroker(WithMaxRetries(1), WithRetryBackoff(time.Millisecond),
+				WithErrorHandler(func(_ context.Context, err error) {
+					reported = append(reported, err)
+				}))
+			c := NewConsumer("foo").Group("foo-group")
+			if tt.policy != nil {
+				c.PanicPolicy(*tt.policy)
+			}
+			p := &recordingPublisher{}
+			s := newSupervisor(b, c)
+			w := newEventWriter(s, p)
+			body := NewMessage("123", "foo", []byte("bar"))
+			body.Metadata.RedeliveryCount = tt.redelivery
+
+			ack := s.Dispatch(func(EventWriter, *Event) bool {
+				panic(errPanicStub)
+			}, w, &Event{Context: context.Background(), Topic: "foo", Body: body})
+			assert.Equal(t, tt.expAck, ack)
+			assert.Equal(t, int64(1), s.Status().Panics)
+			require.NotEmpty(t, reported)
+			assert.True(t, errors.Is(reported[0], ErrHandlerPanic))
+			assert.True(t, errors.Is(reported[0], errPanicStub))
+			var errPanic *PanicError
+			require.True(t, errors.As(reported[0], &errPanic))
+			assert.Contains(t, string(errPanic.Stack), "panic_test.go")
+
+			if tt.expType == "" {
+				assert.Empty(t, p.published)
+				return
+			}
+			require.Len(t, p.published, 1)
+			assert.Equal(t, tt.expType, p.published[0].Type)
+			f, ok := GetFailure(p.published[0])
+			assert.True(t, ok)
+			assert.Equal(t, "foo-group", f.Group)
+		})
+	}
+
+	t.Run("Propagate panic using crash policy", func(t *testing.T) {
+		s := newSupervisor(NewBroker(WithPanicPolicy(PanicPolicy{Action: PanicCrash})), NewConsumer("foo"))
+		defer func() {
+			errPanic, ok := recover().(*PanicError)
+			require.True(t, ok)
+			assert.Equal(t, "boom", errPanic.Value)
+			assert.True(t, errors.Is(errPanic, ErrHandlerPanic))
+			assert.Contains(t, string(errPanic.Stack), "panic_test.go") // stack of the panicking handler
+		}()
+		s.Dispatch(func(EventWriter, *Event) bool {
+			panic("boom")
+		}, newEventWriter(s, nil), &Event{Context: context.Background(), Topic: "foo"})
+		t.Fatal("panic was not propagated")
+	})
+
+	t.Run("Serve event without panics", func(t *testing.T) {
+		s := newSupervisor(NewBroker(), NewConsumer("foo"))
+		ack := s.Dispatch(func(EventWriter, *Event) bool {
+			return true
+		}, newEventWriter(s, nil), &Event{Context: context.Background(), Topic: "foo"})
+		assert.True(t, ack)
+		assert.Equal(t, int64(0), s.Status().Panics)
+	})
+}
@@ -0,0 +1,103 @@
+package projection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/neutrinocorp/quark/dialect"
+)
+
+// DefaultCheckpointTable default checkpoint table name
+const DefaultCheckpointTable = "quark_projection_checkpoints"
+
+// CheckpointStore stores projection positions inside the read model transaction
+type CheckpointStore struct {
+	Dialect dialect.Dialect
+	Table   string
+}
+
+// NewCheckpointStore allocates and returns a CheckpointStore using the DefaultCheckpointTable
+func NewCheckpointStore(d dialect.Dialect) *CheckpointStore {
+	return &CheckpointStore{
+		Dialect: d,
+		Table:   DefaultCheckpointTable,
+	}
+}
+
+// Migrate creates the checkpoint table if it does not exist
+func (s *CheckpointStore) Migrate(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+s.setDefaultTable()+` (
+	projection VARCHAR(255) NOT NULL,
+	topic VARCHAR(255) NOT NULL,
+	partition_id INTEGER NOT NULL,
+	position BIGINT NOT NULL,
+	PRIMARY KEY (projection, topic, partition_id)
+)`)
+	return err
+}
+
+// Load returns the last applied offset of the given projection topic and partition, -1 if none
+func (s *CheckpointStore) Load(ctx context.Context, tx *sql.Tx, projection string, p Position) (int64, error) {
+	offset := int64(-1)
+	err := tx.QueryRowContext(ctx, s.Dialect.Rebind(`SELECT position FROM `+s.setDefaultTable()+
+		` WHERE projection = ? AND topic = ? AND partition_id = ?`), projection, p.Topic, p.Partition).Scan(&offset)
+	if errors.Is(err, sql.ErrNoRows) {
+		return -1, nil
+	}
+	return offset, err
+}
+
+// Save stores the given position as the last applied of the projection
+func (s *CheckpointStore) Save(ctx context.Context, tx *sql.Tx, projection string, p Position) error {
+	query := `INSERT INTO ` + s.setDefaultTable() + ` (projection, topic, partition_id, position) VALUES (?, ?, ?, ?) `
+	if s.Dialect == dialect.MySQL {
+		query += `ON DUPLICATE KEY UPDATE position = VALUES(position)`
+	} else {
+		query += `ON CONFLICT (projection, topic, partition_id) DO UPDATE SET position = excluded.position`
+	}
+	_, err := tx.ExecContext(ctx, s.Dialect.Rebind(query), projection, p.Topic, p.Partition, p.Offset)
+	return err
+}
+
+// Queryer queries rows from a database (e.g. *sql.DB, *sql.Tx)
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// List returns every stored position of the given projection
+func (s *CheckpointStore) List(ctx context.Context, db Queryer, projection string) ([]Position, error) {
+	rows, err := db.QueryContext(ctx, s.Dialect.Rebind(`SELECT topic, partition_id, position FROM `+
+		s.setDefaultTable()+` WHERE projection = ?`), projection)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	positions := make([]Position, 0)
+	for rows.Next() {
+		p := Position{}
+		if err = rows.Scan(&p.Topic, &p.Partition, &p.Offset); err != nil {
+			return nil, err
+		}
+		positions = append(positions, p)
+	}
+	return positions, rows.Err()
+}
+
+// Move replaces every position of the projection dst with the positions of src
+func (s *CheckpointStore) Move(ctx context.Context, tx *sql.Tx, src, dst string) error {
+	if _, err := tx.ExecContext(ctx, s.Dialect.Rebind(`DELETE FROM `+s.setDefaultTable()+
+		` WHERE projection = ?`), dst); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, s.Dialect.Rebind(`UPDATE `+s.setDefaultTable()+
+		` SET projection = ? WHERE projection = ?`), dst, src)
+	return err
+}
+
+func (s *CheckpointStore) setDefaultTable() string {
+	if s.Table != "" {
+		return s.Table
+	}
+	return DefaultCheckpointTable
+}
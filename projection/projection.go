@@ -0,0 +1,85 @@
+// Package projection Read model projections for Quark.
+//
+// A Runner consumes one or more topics through a quark.Broker and applies every Event to a read model inside a
+// database transaction. The projection checkpoint (the last applied position per topic and partition) is stored in
+// the same transaction, so events are applied exactly once to the read model even if the provider redelivers them.
+//
+// Read models are rebuilt from the beginning into a shadow table which atomically replaces the live table once
+// the rebuild catches up.
+package projection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/neutrinocorp/quark"
+)
+
+var (
+	// ErrPositionNotFound the Event does not contain a valid position
+	ErrPositionNotFound = errors.New("projection: event position not found")
+	// ErrProjectionNotFound the projection was not registered
+	ErrProjectionNotFound = errors.New("projection: projection not found")
+	// ErrNotRebuildable the projection does not implement Rebuildable
+	ErrNotRebuildable = errors.New("projection: projection is not rebuildable")
+	// ErrEmptyProjection the projection does not have topics or name
+	ErrEmptyProjection = errors.New("projection: projection is empty")
+	// ErrNonTransactionalDDL the database does not support transactional DDL, required to rebuild read models
+	ErrNonTransactionalDDL = errors.New("projection: transactional ddl not supported")
+	// ErrShadowBehind the shadow read model did not reach every live position, it cannot replace the live one yet
+	ErrShadowBehind = errors.New("projection: shadow read model is behind")
+)
+
+// Projection applies Event(s) to a read model
+type Projection interface {
+	// Name projection unique name, used to store checkpoints and as consumer group
+	Name() string
+	// Topics topics the projection is built from
+	Topics() []string
+	// Apply applies the given Event to the read model table using the given transaction
+	Apply(ctx context.Context, tx *sql.Tx, table string, e *quark.Event) error
+}
+
+// Rebuildable a Projection able to rebuild its read model into a shadow table
+type Rebuildable interface {
+	Projection
+	// Table live read model table name
+	Table() string
+	// CreateTable creates an empty read model table with the given name
+	CreateTable(ctx context.Context, tx *sql.Tx, table string) error
+}
+
+// Position an Event location inside its topic
+type Position struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+}
+
+// PositionFunc returns the given Event Position
+type PositionFunc func(*quark.Event) (Position, error)
+
+// HeaderPosition returns a PositionFunc reading the partition and offset from the given Event header keys.
+//
+//	e.g. projection.HeaderPosition(kafka.HeaderPartition, kafka.HeaderOffset)
+func HeaderPosition(partitionKey, offsetKey string) PositionFunc {
+	return func(e *quark.Event) (Position, error) {
+		offset, err := strconv.ParseInt(e.Header.Get(offsetKey), 10, 64)
+		if err != nil {
+			return Position{}, ErrPositionNotFound
+		}
+		partition := int64(0)
+		if partitionKey != "" {
+			if partition, err = strconv.ParseInt(e.Header.Get(partitionKey), 10, 32); err != nil {
+				return Position{}, ErrPositionNotFound
+			}
+		}
+		return Position{
+			Topic:     e.Topic,
+			Partition: int32(partition),
+			Offset:    offset,
+		}, nil
+	}
+}
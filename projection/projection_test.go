@@ -0,0 +1,75 @@
+package projection
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/neutrinocorp/quark/dialect"
+	"github.com/stretchr/testify/assert"
+)
+
+var headerPositionTestingSuite = []struct {
+	h   quark.Header
+	exp Position
+	err error
+}{
+	{quark.Header{"partition": "2", "offset": "30"}, Position{Topic: "chat.0", Partition: 2, Offset: 30}, nil},
+	{quark.Header{"partition": "2"}, Position{}, ErrPositionNotFound},
+	{quark.Header{"partition": "foo", "offset": "30"}, Position{}, ErrPositionNotFound},
+}
+
+func TestHeaderPosition(t *testing.T) {
+	for _, tt := range headerPositionTestingSuite {
+		t.Run("Projection header position", func(t *testing.T) {
+			pos, err := HeaderPosition("partition", "offset")(&quark.Event{Topic: "chat.0", Header: tt.h})
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.exp, pos)
+		})
+	}
+	t.Run("Projection header position without partition", func(t *testing.T) {
+		pos, err := HeaderPosition("", "offset")(&quark.Event{Topic: "chat.0", Header: quark.Header{"offset": "5"}})
+		assert.Nil(t, err)
+		assert.Equal(t, Position{Topic: "chat.0", Offset: 5}, pos)
+	})
+}
+
+type stubProjection struct {
+	topics []string
+}
+
+func (p stubProjection) Name() string {
+	return "chat-stats"
+}
+
+func (p stubProjection) Topics() []string {
+	return p.topics
+}
+
+func (p stubProjection) Apply(context.Context, *sql.Tx, string, *quark.Event) error {
+	return nil
+}
+
+func TestRunner_Register(t *testing.T) {
+	t.Run("Projection register", func(t *testing.T) {
+		b := quark.NewBroker()
+		r := NewRunner(b, nil, dialect.SQLite, HeaderPosition("", "offset"))
+		c, err := r.Register(stubProjection{topics: []string{"chat.0", "chat.1"}})
+		assert.Nil(t, err)
+		assert.Equal(t, "chat-stats", c.GetGroup())
+		assert.True(t, b.EventMux.Contains("chat.1"))
+
+		_, err = r.Register(stubProjection{})
+		assert.Equal(t, ErrEmptyProjection, err)
+	})
+
+	t.Run("Projection rebuild non-rebuildable", func(t *testing.T) {
+		r := NewRunner(quark.NewBroker(), nil, dialect.SQLite, HeaderPosition("", "offset"))
+		_, _ = r.Register(stubProjection{topics: []string{"chat.0"}})
+		_, err := r.Rebuild(context.Background(), "chat-stats")
+		assert.Equal(t, ErrNotRebuildable, err)
+		_, err = r.Rebuild(context.Background(), "foo")
+		assert.Equal(t, ErrProjectionNotFound, err)
+	})
+}
@@ -0,0 +1,282 @@
+package projection
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/neutrinocorp/quark/dialect"
+)
+
+const shadowSuffix = "_shadow"
+
+// Runner registers projections as Consumer(s) of a Broker and applies their Event(s) transactionally
+type Runner struct {
+	Broker       *quark.Broker
+	DB           *sql.DB
+	Checkpoints  *CheckpointStore
+	Position     PositionFunc
+	ErrorHandler quark.ErrorHandler
+
+	projections map[string]*registration
+	mu          sync.RWMutex
+}
+
+type registration struct {
+	projection Projection
+	// shadow Consumer applying events into the shadow read model, nil if no rebuild is running
+	shadow *quark.Consumer
+	// swapped the shadow read model replaced the live one, shadow consumer must stop applying events
+	swapped bool
+}
+
+// NewRunner allocates and returns a Runner
+func NewRunner(b *quark.Broker, db *sql.DB, d dialect.Dialect, pos PositionFunc) *Runner {
+	return &Runner{
+		Broker:      b,
+		DB:          db,
+		Checkpoints: NewCheckpointStore(d),
+		Position:    pos,
+		projections: map[string]*registration{},
+		mu:          sync.RWMutex{},
+	}
+}
+
+// Register adds the given Projection, a Consumer is registered into the Broker using the projection name as group
+func (r *Runner) Register(p Projection) (*quark.Consumer, error) {
+	if p == nil || p.Name() == "" || len(p.Topics()) == 0 {
+		return nil, ErrEmptyProjection
+	}
+	r.mu.Lock()
+	r.projections[p.Name()] = &registration{projection: p}
+	r.mu.Unlock()
+
+	table := ""
+	if rp, ok := p.(Rebuildable); ok {
+		table = rp.Table()
+	}
+	c := quark.NewConsumer(p.Topics()...).Group(p.Name()).HandleFunc(func(w quark.EventWriter,
+		e *quark.Event) bool {
+		return r.apply(p.Name(), p, table, e)
+	})
+	if err := r.Broker.AddConsumer(c); err != nil {
+		r.mu.Lock()
+		delete(r.projections, p.Name())
+		r.mu.Unlock()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Rebuild creates an empty shadow read model and registers a Consumer applying every Event into it.
+//
+// The returned Consumer uses a brand new group starting from the oldest available position. Call Swap once
+// CaughtUp reports the shadow read model reached the live one; a running rebuild of the projection is replaced.
+//
+// Requires a database supporting transactional DDL (e.g. Postgres, SQLite), returns ErrNonTransactionalDDL
+// otherwise (e.g. MySQL).
+func (r *Runner) Rebuild(ctx context.Context, name string) (*quark.Consumer, error) {
+	p, err := r.getRebuildable(name)
+	if err != nil {
+		return nil, err
+	} else if !r.Checkpoints.Dialect.TransactionalDDL() {
+		return nil, ErrNonTransactionalDDL
+	}
+	if err = r.removeShadow(name); err != nil {
+		return nil, err
+	}
+
+	shadowTable, shadowName := p.Table()+shadowSuffix, name+shadowSuffix
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if _, err = tx.ExecContext(ctx, `DROP TABLE IF EXISTS `+shadowTable); err != nil {
+		return nil, err
+	} else if err = p.CreateTable(ctx, tx, shadowTable); err != nil {
+		return nil, err
+	} else if _, err = tx.ExecContext(ctx, r.Checkpoints.Dialect.Rebind(`DELETE FROM `+
+		r.Checkpoints.setDefaultTable()+` WHERE projection = ?`), shadowName); err != nil {
+		return nil, err
+	} else if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	group := shadowName + "." + strconv.FormatInt(time.Now().Unix(), 10)
+	c := quark.NewConsumer(p.Topics()...).Group(group).StartFrom(quark.Oldest).HandleFunc(func(w quark.EventWriter,
+		e *quark.Event) bool {
+		if r.isSwapped(name) {
+			return true // live consumer is now applying into the rebuilt read model
+		}
+		return r.apply(shadowName, p, shadowTable, e)
+	})
+	r.mu.Lock()
+	r.projections[name].swapped = false
+	r.projections[name].shadow = c
+	r.mu.Unlock()
+	if err = r.Broker.AddConsumer(c); err != nil {
+		r.mu.Lock()
+		r.projections[name].shadow = nil
+		r.mu.Unlock()
+		return nil, err
+	}
+	return c, nil
+}
+
+// CaughtUp verifies if the shadow read model of the given projection reached every live position
+func (r *Runner) CaughtUp(ctx context.Context, name string) (bool, error) {
+	return r.caughtUp(ctx, r.DB, name)
+}
+
+func (r *Runner) caughtUp(ctx context.Context, db Queryer, name string) (bool, error) {
+	live, err := r.Checkpoints.List(ctx, db, name)
+	if err != nil {
+		return false, err
+	}
+	shadow, err := r.Checkpoints.List(ctx, db, name+shadowSuffix)
+	if err != nil {
+		return false, err
+	}
+	shadowOffsets := make(map[string]int64, len(shadow))
+	for _, p := range shadow {
+		shadowOffsets[p.Topic+"/"+strconv.Itoa(int(p.Partition))] = p.Offset
+	}
+	for _, p := range live {
+		offset, ok := shadowOffsets[p.Topic+"/"+strconv.Itoa(int(p.Partition))]
+		if !ok || offset < p.Offset {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Swap atomically replaces the live read model and its checkpoints with the shadow ones, then removes the shadow
+// Consumer from the Broker.
+//
+// The live read model is dropped first, waiting for in-flight live transactions, and the checkpoints are compared
+// again within the same transaction. Returns ErrShadowBehind if the live consumer moved ahead of the shadow one
+// since CaughtUp, keeping both read models; call Swap again once the shadow read model catches up.
+//
+// Requires a database supporting transactional DDL (e.g. Postgres, SQLite), returns ErrNonTransactionalDDL
+// otherwise (e.g. MySQL).
+func (r *Runner) Swap(ctx context.Context, name string) error {
+	p, err := r.getRebuildable(name)
+	if err != nil {
+		return err
+	} else if !r.Checkpoints.Dialect.TransactionalDDL() {
+		return ErrNonTransactionalDDL
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err = tx.ExecContext(ctx, `DROP TABLE IF EXISTS `+p.Table()); err != nil {
+		return err
+	}
+	if caughtUp, err := r.caughtUp(ctx, tx, name); err != nil {
+		return err
+	} else if !caughtUp {
+		return ErrShadowBehind
+	}
+	if _, err = tx.ExecContext(ctx, `ALTER TABLE `+p.Table()+shadowSuffix+` RENAME TO `+
+		p.Table()); err != nil {
+		return err
+	} else if err = r.Checkpoints.Move(ctx, tx, name+shadowSuffix, name); err != nil {
+		return err
+	} else if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.projections[name].swapped = true
+	r.mu.Unlock()
+	return r.removeShadow(name)
+}
+
+// removeShadow removes the shadow Consumer of the given projection from the Broker, if any
+func (r *Runner) removeShadow(name string) error {
+	r.mu.Lock()
+	c := r.projections[name].shadow
+	r.projections[name].shadow = nil
+	r.mu.Unlock()
+	if c == nil {
+		return nil
+	}
+	return r.Broker.RemoveConsumer(c)
+}
+
+// apply applies the given Event into the read model table if it was not applied before.
+//
+// Returns false (NAck) if the Event could not be applied
+func (r *Runner) apply(checkpoint string, p Projection, table string, e *quark.Event) bool {
+	ctx := e.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	pos, err := r.Position(e)
+	if err != nil {
+		r.handleError(ctx, fmt.Errorf("projection %s: %w", checkpoint, err))
+		return false
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		r.handleError(ctx, fmt.Errorf("projection %s: %w", checkpoint, err))
+		return false
+	}
+	defer tx.Rollback()
+	last, err := r.Checkpoints.Load(ctx, tx, checkpoint, pos)
+	if err != nil {
+		r.handleError(ctx, fmt.Errorf("projection %s: %w", checkpoint, err))
+		return false
+	} else if pos.Offset <= last {
+		return true // already applied, provider redelivered it
+	}
+
+	if err = p.Apply(ctx, tx, table, e); err != nil {
+		r.handleError(ctx, fmt.Errorf("projection %s: %w", checkpoint, err))
+		return false
+	} else if err = r.Checkpoints.Save(ctx, tx, checkpoint, pos); err != nil {
+		r.handleError(ctx, fmt.Errorf("projection %s: %w", checkpoint, err))
+		return false
+	} else if err = tx.Commit(); err != nil {
+		r.handleError(ctx, fmt.Errorf("projection %s: %w", checkpoint, err))
+		return false
+	}
+	return true
+}
+
+func (r *Runner) getRebuildable(name string) (Rebuildable, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	reg, ok := r.projections[name]
+	if !ok {
+		return nil, ErrProjectionNotFound
+	}
+	p, ok := reg.projection.(Rebuildable)
+	if !ok {
+		return nil, ErrNotRebuildable
+	}
+	return p, nil
+}
+
+func (r *Runner) isSwapped(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.projections[name].swapped
+}
+
+func (r *Runner) handleError(ctx context.Context, err error) {
+	if r.ErrorHandler != nil {
+		r.ErrorHandler(ctx, err)
+	} else if r.Broker != nil && r.Broker.ErrorHandler != nil {
+		r.Broker.ErrorHandler(ctx, err)
+	}
+}
@@ -0,0 +1,141 @@
+package projection
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/neutrinocorp/quark/dialect"
+	"github.com/neutrinocorp/quark/internal/sqltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// messageCounter Rebuildable projection counting messages per topic
+type messageCounter struct{}
+
+func (messageCounter) Name() string {
+	return "chat-counter"
+}
+
+func (messageCounter) Topics() []string {
+	return []string{"chat.0"}
+}
+
+func (messageCounter) Table() string {
+	return "chat_counter"
+}
+
+func (messageCounter) CreateTable(ctx context.Context, tx *sql.Tx, table string) error {
+	_, err := tx.ExecContext(ctx, `CREATE TABLE `+table+` (topic VARCHAR(255) NOT NULL PRIMARY KEY, total INTEGER)`)
+	return err
+}
+
+func (messageCounter) Apply(ctx context.Context, tx *sql.Tx, table string, e *quark.Event) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO `+table+` (topic, total) VALUES (?, 1) `+
+		`ON CONFLICT (topic) DO UPDATE SET total = total + 1`, e.Topic)
+	return err
+}
+
+func newSQLiteTestingRunner(t *testing.T) *Runner {
+	db := sqltest.Open(t, "projection.db")
+	r := NewRunner(quark.NewBroker(), db, dialect.SQLite, HeaderPosition("", "offset"))
+	ctx := context.Background()
+	require.Nil(t, r.Checkpoints.Migrate(ctx, db))
+	tx, err := db.BeginTx(ctx, nil)
+	require.Nil(t, err)
+	require.Nil(t, messageCounter{}.CreateTable(ctx, tx, messageCounter{}.Table()))
+	require.Nil(t, tx.Commit())
+	return r
+}
+
+func newCounterEvent(offset int) *quark.Event {
+	return &quark.Event{
+		Context: context.Background(),
+		Topic:   "chat.0",
+		Header:  quark.Header{"offset": strconv.Itoa(offset)},
+	}
+}
+
+func countMessages(t *testing.T, db *sql.DB, table string) int {
+	total := 0
+	require.Nil(t, db.QueryRow(`SELECT total FROM `+table+` WHERE topic = 'chat.0'`).Scan(&total))
+	return total
+}
+
+func TestRunner_Apply(t *testing.T) {
+	t.Run("Projection apply events once", func(t *testing.T) {
+		r := newSQLiteTestingRunner(t)
+		c, err := r.Register(messageCounter{})
+		require.Nil(t, err)
+		for _, offset := range []int{0, 1, 1, 2} { // offset 1 redelivered
+			assert.True(t, c.GetHandleFunc()(nil, newCounterEvent(offset)))
+		}
+		assert.Equal(t, 3, countMessages(t, r.DB, "chat_counter"))
+	})
+}
+
+func TestRunner_Rebuild(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Projection rebuild and swap read model", func(t *testing.T) {
+		r := newSQLiteTestingRunner(t)
+		live, err := r.Register(messageCounter{})
+		require.Nil(t, err)
+		for i := 0; i < 3; i++ {
+			live.GetHandleFunc()(nil, newCounterEvent(i))
+		}
+
+		shadow, err := r.Rebuild(ctx, "chat-counter")
+		require.Nil(t, err)
+		assert.Equal(t, quark.Oldest, shadow.GetStartFrom())
+		assert.Len(t, r.Broker.EventMux.Get("chat.0"), 2)
+		shadow.GetHandleFunc()(nil, newCounterEvent(0))
+		caughtUp, err := r.CaughtUp(ctx, "chat-counter")
+		assert.Nil(t, err)
+		assert.False(t, caughtUp)
+		shadow.GetHandleFunc()(nil, newCounterEvent(1))
+		shadow.GetHandleFunc()(nil, newCounterEvent(2))
+		caughtUp, err = r.CaughtUp(ctx, "chat-counter")
+		assert.Nil(t, err)
+		assert.True(t, caughtUp)
+
+		assert.Nil(t, r.Swap(ctx, "chat-counter"))
+		assert.Equal(t, 3, countMessages(t, r.DB, "chat_counter"))
+		assert.Equal(t, []*quark.Consumer{live}, r.Broker.EventMux.Get("chat.0"))
+		assert.True(t, live.GetHandleFunc()(nil, newCounterEvent(2))) // applied by the shadow read model
+		assert.True(t, live.GetHandleFunc()(nil, newCounterEvent(3)))
+		assert.Equal(t, 4, countMessages(t, r.DB, "chat_counter"))
+	})
+
+	t.Run("Projection reject swap of shadow read model behind", func(t *testing.T) {
+		r := newSQLiteTestingRunner(t)
+		live, err := r.Register(messageCounter{})
+		require.Nil(t, err)
+		live.GetHandleFunc()(nil, newCounterEvent(0))
+		shadow, err := r.Rebuild(ctx, "chat-counter")
+		require.Nil(t, err)
+		shadow.GetHandleFunc()(nil, newCounterEvent(0))
+		caughtUp, err := r.CaughtUp(ctx, "chat-counter")
+		require.Nil(t, err)
+		require.True(t, caughtUp)
+
+		live.GetHandleFunc()(nil, newCounterEvent(1)) // live consumer moved ahead
+		assert.Equal(t, ErrShadowBehind, r.Swap(ctx, "chat-counter"))
+		assert.Equal(t, 2, countMessages(t, r.DB, "chat_counter"))
+		assert.Len(t, r.Broker.EventMux.Get("chat.0"), 2)
+		shadow.GetHandleFunc()(nil, newCounterEvent(1))
+		assert.Nil(t, r.Swap(ctx, "chat-counter"))
+		assert.Equal(t, 2, countMessages(t, r.DB, "chat_counter"))
+	})
+
+	t.Run("Projection reject non-transactional ddl", func(t *testing.T) {
+		r := NewRunner(quark.NewBroker(), nil, dialect.MySQL, HeaderPosition("", "offset"))
+		_, err := r.Register(messageCounter{})
+		require.Nil(t, err)
+		_, err = r.Rebuild(ctx, "chat-counter")
+		assert.Equal(t, ErrNonTransactionalDDL, err)
+		assert.Equal(t, ErrNonTransactionalDDL, r.Swap(ctx, "chat-counter"))
+	})
+}
@@ -2,6 +2,7 @@ package quark
 
 import (
 	"context"
+	"sync"
 )
 
 // Publisher pushes the given Message into the Event-Driven ecosystem.
@@ -12,3 +13,78 @@ type Publisher interface {
 // PublisherFactory is a crucial Broker and/or Consumer component which generates the concrete publishers
 // Quark will use to produce data
 type PublisherFactory func(providerCfg interface{}, cluster []string) Publisher
+
+// Flusher is a Publisher buffering messages, a Broker flushes its publishers implementing this interface when
+// shutting down
+type Flusher interface {
+	// Flush blocks until every buffered message was delivered or ctx is done
+	Flush(ctx context.Context) error
+}
+
+// AsyncPublisher is a Publisher sending messages in the background.
+//
+// Publish enqueues the messages and returns right away, before they are delivered; delivery errors are only reported
+// through the Delivery futures returned by PublishAsync. EventWriter(s) wait for the Delivery futures instead, so
+// handlers acknowledge their Event only once the messages they wrote were delivered.
+//
+// A Broker flushes and closes its asynchronous publishers when shutting down
+type AsyncPublisher interface {
+	Publisher
+	Flusher
+	// PublishAsync enqueues the given messages returning a Delivery future per message
+	PublishAsync(ctx context.Context, msgs ...*Message) []*Delivery
+	// Close flushes every enqueued message and releases the publisher resources (e.g. producers, goroutines),
+	// returns ErrPublisherClosed if it was already closed
+	Close(ctx context.Context) error
+}
+
+// Delivery is the future result of a Message published asynchronously
+type Delivery struct {
+	Message *Message
+	once    sync.Once
+	done    chan struct{}
+	err     error
+}
+
+// NewDelivery allocates and returns a pending Delivery of the given Message
+func NewDelivery(msg *Message) *Delivery {
+	return &Delivery{
+		Message: msg,
+		done:    make(chan struct{}),
+	}
+}
+
+// Complete resolves the Delivery with the given error, only the first call has effect.
+//
+// Called by AsyncPublisher implementations once the Message was acknowledged or failed
+func (d *Delivery) Complete(err error) {
+	d.once.Do(func() {
+		d.err = err
+		close(d.done)
+	})
+}
+
+// Done returns a channel closed once the Delivery was resolved
+func (d *Delivery) Done() <-chan struct{} {
+	return d.done
+}
+
+// Err returns the Delivery error, nil if the Message was delivered or the Delivery is still pending
+func (d *Delivery) Err() error {
+	select {
+	case <-d.done:
+		return d.err
+	default:
+		return nil
+	}
+}
+
+// Wait blocks until the Delivery is resolved returning its error, or until ctx is done
+func (d *Delivery) Wait(ctx context.Context) error {
+	select {
+	case <-d.done:
+		return d.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,92 @@
+package quark
+
+import (
+	"context"
+	"sync"
+)
+
+// replyRouter routes reply Event(s) to their awaiting requests using the reply Message CorrelationId
+type replyRouter struct {
+	pending map[string]chan *Event
+	mu      sync.Mutex
+}
+
+func newReplyRouter() *replyRouter {
+	return &replyRouter{
+		pending: map[string]chan *Event{},
+		mu:      sync.Mutex{},
+	}
+}
+
+// register starts awaiting a reply for the given request Message id
+func (r *replyRouter) register(id string) <-chan *Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ch := make(chan *Event, 1)
+	r.pending[id] = ch
+	return ch
+}
+
+// unregister stops awaiting a reply for the given request Message id
+func (r *replyRouter) unregister(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, id)
+}
+
+// ServeEvent delivers the given reply to its awaiting request.
+//
+// Replies without an awaiting request (e.g. request timed out) are acknowledged and dropped
+func (r *replyRouter) ServeEvent(_ EventWriter, e *Event) bool {
+	if e.Body == nil {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ch, ok := r.pending[e.Body.Metadata.CorrelationId]; ok {
+		ch <- e // buffered, a request receives one reply at most
+		delete(r.pending, e.Body.Metadata.CorrelationId)
+	}
+	return true
+}
+
+// Reply publishes the given data as a reply of the given request Event.
+//
+// The reply Message is published into the request's reply topic (HeaderMessageReplyTo) and its CorrelationId is set
+// to the request Message id, so the requester is able to match it.
+func Reply(ctx context.Context, w EventWriter, e *Event, data []byte) error {
+	if e == nil || e.Body == nil {
+		return ErrEmptyMessage
+	}
+	replyTo := e.Body.Metadata.ExternalData[HeaderMessageReplyTo]
+	if replyTo == "" {
+		replyTo = e.Header.Get(HeaderMessageReplyTo)
+	}
+	if replyTo == "" {
+		return ErrReplyToNotFound
+	}
+
+	idFactory := defaultIDFactory
+	if dw, ok := w.(*defaultEventWriter); ok && dw.Supervisor != nil && dw.Supervisor.Broker != nil {
+		idFactory = dw.Supervisor.Broker.setDefaultMessageIDFactory()
+	}
+	msg := NewMessageFromParent(e.Body.Id, idFactory(), replyTo, data) // correlated to the request to be matched
+	_, err := replyWriter(w).WriteMessage(ctx, msg)
+	return err
+}
+
+// replyWriter returns a copy of the given writer without the propagated correlation id header, which would replace
+// the reply CorrelationId. The given writer header is kept untouched as writers might be shared between Event(s)
+func replyWriter(w EventWriter) EventWriter {
+	dw, ok := w.(*defaultEventWriter)
+	if !ok || !dw.header.Contains(HeaderMessageCorrelationId) {
+		return w
+	}
+	rw := *dw
+	rw.header = make(Header, len(dw.header))
+	for k, v := range dw.header {
+		rw.header[k] = v
+	}
+	rw.header.Del(HeaderMessageCorrelationId)
+	return &rw
+}
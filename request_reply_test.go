@@ -0,0 +1,32 @@
+package quark
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReply(t *testing.T) {
+	t.Run("Reply correlated to the request keeping the writer header", func(t *testing.T) {
+		p := &recordingPublisher{}
+		w := newEventWriter(newSupervisor(NewBroker(WithRetryBackoff(time.Millisecond)), new(Consumer)), p)
+		w.Header().Set(HeaderMessageCorrelationId, "order-1") // propagated from the consumed Event
+		req := NewMessage("request-1", "orders.quote", nil)
+		req.Metadata.ExternalData[HeaderMessageReplyTo] = "replies.0"
+
+		assert.Nil(t, Reply(context.Background(), w, &Event{Body: req, Header: Header{}}, []byte("42")))
+		require.Len(t, p.published, 1)
+		assert.Equal(t, "request-1", p.published[0].Metadata.CorrelationId)
+		assert.Equal(t, "order-1", w.Header().Get(HeaderMessageCorrelationId))
+	})
+
+	t.Run("Reply without reply topic", func(t *testing.T) {
+		w := newEventWriter(newSupervisor(NewBroker(), new(Consumer)), &recordingPublisher{})
+		err := Reply(context.Background(), w, &Event{Body: NewMessage("request-1", "orders.quote", nil),
+			Header: Header{}}, nil)
+		assert.Equal(t, ErrReplyToNotFound, err)
+	})
+}
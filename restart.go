@@ -0,0 +1,49 @@
+package quark
+
+import "time"
+
+// RestartStrategy defines how a Supervisor reacts when one of its workers exits unexpectedly
+type RestartStrategy int
+
+const (
+	// OneForOne only the crashed worker is restarted
+	OneForOne RestartStrategy = iota
+	// OneForAll every worker of the Supervisor is restarted when one of them crashes
+	OneForAll
+	// NeverRestart crashed workers are left closed
+	NeverRestart
+)
+
+var (
+	defaultMaxRestarts   = 3
+	defaultRestartWindow = time.Minute
+)
+
+// RestartPolicy defines how and how often a Supervisor restarts its crashed workers
+type RestartPolicy struct {
+	Strategy RestartStrategy
+	// MaxRestarts is the maximum number of restarts allowed within Window
+	MaxRestarts int
+	// Window is the sliding period of time MaxRestarts applies to
+	Window time.Duration
+	// Escalate shuts the whole Broker down when MaxRestarts is exceeded; otherwise, crashed workers are left closed
+	Escalate bool
+}
+
+// DefaultRestartPolicy restarts crashed workers one by one, up to 3 times per minute without escalating
+var DefaultRestartPolicy = RestartPolicy{
+	Strategy:    OneForOne,
+	MaxRestarts: defaultMaxRestarts,
+	Window:      defaultRestartWindow,
+}
+
+// withDefaults fills unset policy fields with default values
+func (p RestartPolicy) withDefaults() RestartPolicy {
+	if p.MaxRestarts <= 0 {
+		p.MaxRestarts = defaultMaxRestarts
+	}
+	if p.Window <= 0 {
+		p.Window = defaultRestartWindow
+	}
+	return p
+}
@@ -0,0 +1,47 @@
+package saga
+
+import "time"
+
+// Status a process instance lifecycle stage
+type Status string
+
+const (
+	// StatusRunning the instance is waiting for its next step
+	StatusRunning Status = "running"
+	// StatusCompleted the instance executed its final step
+	StatusCompleted Status = "completed"
+	// StatusCompensating the instance step timed out and its compensations are being executed
+	StatusCompensating Status = "compensating"
+	// StatusCompensated the instance failed and every compensation was executed
+	StatusCompensated Status = "compensated"
+	// StatusFailed the instance failed and one or more compensations failed, manual intervention is required
+	StatusFailed Status = "failed"
+)
+
+// Instance a running Process correlated by a Message CorrelationId
+type Instance struct {
+	Process       string            `json:"process"`
+	CorrelationId string            `json:"correlation_id"`
+	Status        Status            `json:"status"`
+	Steps         []string          `json:"steps"`
+	Data          map[string]string `json:"data,omitempty"`
+	Deadline      time.Time         `json:"deadline,omitempty"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	// Version optimistic concurrency control, incremented by a Store on every save
+	Version int `json:"-"`
+}
+
+func newInstance(process, correlationId string) *Instance {
+	return &Instance{
+		Process:       process,
+		CorrelationId: correlationId,
+		Status:        StatusRunning,
+		Steps:         make([]string, 0),
+		Data:          map[string]string{},
+	}
+}
+
+// Done verifies if the instance reached a final stage
+func (i *Instance) Done() bool {
+	return i.Status != StatusRunning
+}
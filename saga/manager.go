@@ -0,0 +1,234 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/neutrinocorp/quark"
+)
+
+var defaultTimeoutInterval = time.Second * 5
+
+// Manager process manager, registers every Process as a Consumer of a Broker and drives its instances
+type Manager struct {
+	Broker *quark.Broker
+	Store  Store
+	// Writer EventWriter used to issue compensating commands when a step times out. Default is a writer using the
+	// Broker Publisher
+	Writer quark.EventWriter
+	// ErrorHandler default is the Broker ErrorHandler
+	ErrorHandler quark.ErrorHandler
+	// TimeoutInterval time to wait between each stuck step verification
+	TimeoutInterval time.Duration
+
+	processes map[string]*Process
+	mu        sync.RWMutex
+}
+
+// NewManager allocates and returns a Manager
+func NewManager(b *quark.Broker, s Store) *Manager {
+	return &Manager{
+		Broker:          b,
+		Store:           s,
+		TimeoutInterval: defaultTimeoutInterval,
+		processes:       map[string]*Process{},
+		mu:              sync.RWMutex{},
+	}
+}
+
+// Register adds the given Process, a Consumer is registered into the Broker to receive every step Message Type
+func (m *Manager) Register(p *Process) error {
+	if p == nil || p.name == "" || len(p.order) == 0 {
+		return ErrEmptyProcess
+	}
+	m.mu.Lock()
+	m.processes[p.name] = p
+	m.mu.Unlock()
+
+	c := quark.NewConsumer(p.GetTopics()...).Group(p.setDefaultGroup()).HandleFunc(func(w quark.EventWriter,
+		e *quark.Event) bool {
+		return m.handle(p, w, e)
+	})
+	if err := m.Broker.AddConsumer(c); err != nil {
+		m.mu.Lock()
+		delete(m.processes, p.name)
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run verifies stuck steps every TimeoutInterval until ctx is done.
+//
+// Instances with an expired step are compensated
+func (m *Manager) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.setDefaultTimeoutInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := m.CheckTimeouts(ctx); err != nil {
+				m.handleError(ctx, err)
+			}
+		}
+	}
+}
+
+// CheckTimeouts compensates every instance with an expired step.
+//
+// Each instance is claimed first by storing its StatusCompensating state, compensations are only executed if the
+// claim wins over concurrent step handlers and managers (optimistic concurrency control)
+func (m *Manager) CheckTimeouts(ctx context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	errs := new(multierror.Error)
+	for _, p := range m.processes {
+		instances, err := m.Store.Expired(ctx, p.name, time.Now())
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		for _, inst := range instances {
+			inst.Status, inst.Deadline = StatusCompensating, time.Time{}
+			if err = m.Store.Save(ctx, inst); errors.Is(err, ErrConcurrentUpdate) {
+				continue // the instance moved on (or another manager claimed it)
+			} else if err != nil {
+				errs = multierror.Append(errs, err)
+				continue
+			}
+			m.handleError(ctx, fmt.Errorf("%w: process %s, correlation id %s, last step %s", ErrStepTimeout,
+				p.name, inst.CorrelationId, lastStep(inst)))
+			m.compensate(ctx, p, m.setDefaultWriter(), inst, nil)
+			if err = m.Store.Save(ctx, inst); err != nil {
+				errs = multierror.Append(errs, err)
+			}
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// handle executes the step triggered by the given Event.
+//
+// Returns false (NAck) only when the instance state could not be stored
+func (m *Manager) handle(p *Process, w quark.EventWriter, e *quark.Event) bool {
+	ctx := e.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var step *Step
+	ok, correlationId := false, ""
+	if e.Body != nil {
+		step, ok = p.steps[e.Body.Type]
+		correlationId = e.Body.Metadata.CorrelationId
+	}
+	if !ok {
+		step, ok = p.steps[e.Topic] // messages without a process Message Type are routed by their topic
+	}
+	if correlationId == "" {
+		correlationId = e.Header.Get(quark.HeaderMessageCorrelationId)
+	}
+	if !ok || correlationId == "" {
+		return true
+	}
+
+	inst, err := m.Store.Load(ctx, p.name, correlationId)
+	if errors.Is(err, ErrInstanceNotFound) && step.starts {
+		inst = newInstance(p.name, correlationId)
+	} else if errors.Is(err, ErrInstanceNotFound) {
+		return true // step without a running instance, ignore
+	} else if err != nil {
+		m.handleError(ctx, err)
+		return false
+	}
+	if inst.Done() {
+		return true
+	}
+
+	switch {
+	case step.fails:
+		m.handleError(ctx, fmt.Errorf("%w: process %s, correlation id %s, step %s", ErrStepFailed, p.name,
+			correlationId, step.msgType))
+		m.compensate(ctx, p, w, inst, e)
+	default:
+		m.execute(ctx, p, step, w, inst, e)
+	}
+
+	if err = m.Store.Save(ctx, inst); err != nil {
+		m.handleError(ctx, err)
+		return false
+	}
+	return true
+}
+
+func (m *Manager) execute(ctx context.Context, p *Process, s *Step, w quark.EventWriter, inst *Instance,
+	e *quark.Event) {
+	if s.action != nil {
+		if err := s.action(ctx, w, inst, e); err != nil {
+			m.handleError(ctx, fmt.Errorf("saga: process %s, correlation id %s, step %s: %w", p.name,
+				inst.CorrelationId, s.msgType, err))
+			m.compensate(ctx, p, w, inst, e)
+			return
+		}
+	}
+	inst.Steps = append(inst.Steps, s.msgType)
+	inst.Deadline = time.Time{}
+	if s.completes {
+		inst.Status = StatusCompleted
+	} else if s.timeout > 0 {
+		inst.Deadline = time.Now().Add(s.timeout)
+	}
+}
+
+// compensate executes every executed step compensation in reverse order
+func (m *Manager) compensate(ctx context.Context, p *Process, w quark.EventWriter, inst *Instance, e *quark.Event) {
+	inst.Status = StatusCompensated
+	inst.Deadline = time.Time{}
+	for i := len(inst.Steps) - 1; i >= 0; i-- {
+		s, ok := p.steps[inst.Steps[i]]
+		if !ok || s.compensate == nil {
+			continue
+		}
+		if err := s.compensate(ctx, w, inst, e); err != nil {
+			inst.Status = StatusFailed
+			m.handleError(ctx, fmt.Errorf("saga: process %s, correlation id %s, compensate %s: %w", p.name,
+				inst.CorrelationId, s.msgType, err))
+		}
+	}
+}
+
+func (m *Manager) handleError(ctx context.Context, err error) {
+	if m.ErrorHandler != nil {
+		m.ErrorHandler(ctx, err)
+	} else if m.Broker != nil && m.Broker.ErrorHandler != nil {
+		m.Broker.ErrorHandler(ctx, err)
+	}
+}
+
+func (m *Manager) setDefaultWriter() quark.EventWriter {
+	if m.Writer != nil {
+		return m.Writer
+	} else if m.Broker != nil && m.Broker.EventWriter != nil {
+		return m.Broker.EventWriter
+	}
+	return quark.NewEventWriter(m.Broker)
+}
+
+func (m *Manager) setDefaultTimeoutInterval() time.Duration {
+	if m.TimeoutInterval > 0 {
+		return m.TimeoutInterval
+	}
+	return defaultTimeoutInterval
+}
+
+func lastStep(inst *Instance) string {
+	if len(inst.Steps) == 0 {
+		return ""
+	}
+	return inst.Steps[len(inst.Steps)-1]
+}
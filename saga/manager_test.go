@@ -0,0 +1,184 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStubEvent(msgType, correlationId string) *quark.Event {
+	msg := quark.NewMessageFromParent(correlationId, "1", msgType, nil)
+	return &quark.Event{
+		Context: context.Background(),
+		Topic:   msgType,
+		Header:  quark.Header{},
+		Body:    msg,
+	}
+}
+
+type stubJournal struct {
+	calls []string
+}
+
+func (j *stubJournal) action(name string, err error) ActionFunc {
+	return func(context.Context, quark.EventWriter, *Instance, *quark.Event) error {
+		j.calls = append(j.calls, name)
+		return err
+	}
+}
+
+var errStubAction = errors.New("generic stub action error")
+
+func newStubProcess(j *stubJournal, chargeErr error) *Process {
+	p := NewProcess("order-fulfillment")
+	p.On("orders.placed").Start().Do(j.action("reserve", nil)).Compensate(j.action("release", nil)).
+		Timeout(time.Minute)
+	p.On("stock.reserved").Do(j.action("charge", chargeErr)).Compensate(j.action("refund", nil))
+	p.On("payment.charged").Do(j.action("ship", nil)).Complete()
+	p.On("payment.rejected").Fail()
+	return p
+}
+
+func TestManager_Handle(t *testing.T) {
+	t.Run("Saga complete process", func(t *testing.T) {
+		j := &stubJournal{}
+		s := NewMemoryStore()
+		m := NewManager(quark.NewBroker(), s)
+		p := newStubProcess(j, nil)
+		assert.Nil(t, m.Register(p))
+		assert.True(t, m.handle(p, nil, newStubEvent("orders.placed", "order-1")))
+		inst, _ := s.Load(context.Background(), p.GetName(), "order-1")
+		assert.False(t, inst.Deadline.IsZero())
+		assert.True(t, m.handle(p, nil, newStubEvent("stock.reserved", "order-1")))
+		assert.True(t, m.handle(p, nil, newStubEvent("payment.charged", "order-1")))
+		assert.True(t, m.handle(p, nil, newStubEvent("payment.charged", "order-1"))) // duplicated
+
+		inst, err := s.Load(context.Background(), p.GetName(), "order-1")
+		assert.Nil(t, err)
+		assert.Equal(t, StatusCompleted, inst.Status)
+		assert.Equal(t, []string{"reserve", "charge", "ship"}, j.calls)
+	})
+
+	t.Run("Saga ignore steps without instance", func(t *testing.T) {
+		j := &stubJournal{}
+		s := NewMemoryStore()
+		m := NewManager(quark.NewBroker(), s)
+		p := newStubProcess(j, nil)
+		assert.True(t, m.handle(p, nil, newStubEvent("stock.reserved", "order-1")))
+		_, err := s.Load(context.Background(), p.GetName(), "order-1")
+		assert.Equal(t, ErrInstanceNotFound, err)
+		assert.Empty(t, j.calls)
+	})
+
+	t.Run("Saga compensate failed action", func(t *testing.T) {
+		j := &stubJournal{}
+		s := NewMemoryStore()
+		m := NewManager(quark.NewBroker(), s)
+		p := newStubProcess(j, errStubAction)
+		m.handle(p, nil, newStubEvent("orders.placed", "order-1"))
+		m.handle(p, nil, newStubEvent("stock.reserved", "order-1"))
+		inst, _ := s.Load(context.Background(), p.GetName(), "order-1")
+		assert.Equal(t, StatusCompensated, inst.Status)
+		assert.Equal(t, []string{"reserve", "charge", "release"}, j.calls)
+	})
+
+	t.Run("Saga compensate failure step", func(t *testing.T) {
+		j := &stubJournal{}
+		s := NewMemoryStore()
+		m := NewManager(quark.NewBroker(), s)
+		p := newStubProcess(j, nil)
+		m.handle(p, nil, newStubEvent("orders.placed", "order-1"))
+		m.handle(p, nil, newStubEvent("stock.reserved", "order-1"))
+		m.handle(p, nil, newStubEvent("payment.rejected", "order-1"))
+		inst, _ := s.Load(context.Background(), p.GetName(), "order-1")
+		assert.Equal(t, StatusCompensated, inst.Status)
+		assert.Equal(t, []string{"reserve", "charge", "refund", "release"}, j.calls)
+	})
+
+	t.Run("Saga route events without message type by topic", func(t *testing.T) {
+		j := &stubJournal{}
+		s := NewMemoryStore()
+		m := NewManager(quark.NewBroker(), s)
+		p := newStubProcess(j, nil)
+		e := newStubEvent("orders.placed", "order-1")
+		e.Body.Type = ""
+		assert.True(t, m.handle(p, nil, e))
+		inst, err := s.Load(context.Background(), p.GetName(), "order-1")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"orders.placed"}, inst.Steps)
+	})
+}
+
+// staleStore returns the instances captured before they were updated as expired
+type staleStore struct {
+	*MemoryStore
+	expired []*Instance
+}
+
+func (s staleStore) Expired(context.Context, string, time.Time) ([]*Instance, error) {
+	return s.expired, nil
+}
+
+func TestManager_CheckTimeouts(t *testing.T) {
+	t.Run("Saga compensate stuck instances", func(t *testing.T) {
+		j := &stubJournal{}
+		s := NewMemoryStore()
+		errs := make([]error, 0)
+		m := NewManager(quark.NewBroker(quark.WithErrorHandler(func(_ context.Context, err error) {
+			errs = append(errs, err)
+		})), s)
+		p := NewProcess("order-fulfillment")
+		p.On("orders.placed").Start().Do(j.action("reserve", nil)).Compensate(j.action("release", nil)).
+			Timeout(time.Millisecond)
+		assert.Nil(t, m.Register(p))
+		m.handle(p, nil, newStubEvent("orders.placed", "order-1"))
+		time.Sleep(time.Millisecond * 5)
+
+		assert.Nil(t, m.CheckTimeouts(context.Background()))
+		inst, _ := s.Load(context.Background(), p.GetName(), "order-1")
+		assert.Equal(t, StatusCompensated, inst.Status)
+		assert.Equal(t, []string{"reserve", "release"}, j.calls)
+		assert.Len(t, errs, 1)
+		assert.True(t, errors.Is(errs[0], ErrStepTimeout))
+	})
+
+	t.Run("Saga do not compensate instances updated concurrently", func(t *testing.T) {
+		j := &stubJournal{}
+		s := staleStore{MemoryStore: NewMemoryStore()}
+		m := NewManager(quark.NewBroker(), s)
+		p := newStubProcess(j, nil)
+		m.handle(p, nil, newStubEvent("orders.placed", "order-1"))
+		stale, _ := s.Load(context.Background(), p.GetName(), "order-1")
+		s.expired = append(s.expired, stale)
+		m.handle(p, nil, newStubEvent("stock.reserved", "order-1")) // step executed while checking timeouts
+
+		assert.Nil(t, m.CheckTimeouts(context.Background()))
+		inst, _ := s.Load(context.Background(), p.GetName(), "order-1")
+		assert.Equal(t, StatusRunning, inst.Status)
+		assert.Equal(t, []string{"reserve", "charge"}, j.calls)
+	})
+}
+
+func TestManager_Register(t *testing.T) {
+	t.Run("Saga register empty process", func(t *testing.T) {
+		m := NewManager(quark.NewBroker(), NewMemoryStore())
+		assert.Equal(t, ErrEmptyProcess, m.Register(NewProcess("foo")))
+		assert.Equal(t, ErrEmptyProcess, m.Register(nil))
+	})
+
+	t.Run("Saga register process consumer", func(t *testing.T) {
+		b := quark.NewBroker()
+		m := NewManager(b, NewMemoryStore())
+		assert.Nil(t, m.Register(newStubProcess(&stubJournal{}, nil)))
+		assert.True(t, b.EventMux.Contains("payment.rejected"))
+
+		assert.Nil(t, b.Shutdown(context.Background()))
+		p := NewProcess("billing")
+		p.On("orders.placed").Start()
+		assert.Equal(t, quark.ErrBrokerClosed, m.Register(p))
+	})
+}
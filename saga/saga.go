@@ -0,0 +1,133 @@
+// Package saga Process manager (Saga) mechanisms for Quark.
+//
+// A Process is declared as a set of steps keyed by a Message Type. Each step executes an action (e.g. issues a
+// command through the EventWriter) and may define a compensating action, used to undo its side-effects if the
+// process fails or gets stuck.
+//
+// Process instances are correlated using the Message CorrelationId and their state is kept in a pluggable Store.
+package saga
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+)
+
+var (
+	// ErrInstanceNotFound the process instance does not exist
+	ErrInstanceNotFound = errors.New("saga: instance not found")
+	// ErrConcurrentUpdate the process instance was modified by another process since it was loaded
+	ErrConcurrentUpdate = errors.New("saga: instance was concurrently updated")
+	// ErrStepTimeout the process instance did not receive its next step on time
+	ErrStepTimeout = errors.New("saga: step timed out")
+	// ErrStepFailed the process instance received a failure step
+	ErrStepFailed = errors.New("saga: step failed")
+	// ErrEmptyProcess the process does not have steps or name
+	ErrEmptyProcess = errors.New("saga: process is empty")
+)
+
+// ActionFunc executes a process step action or its compensation.
+//
+// The given Event is nil when a compensation is triggered by a step timeout
+type ActionFunc func(ctx context.Context, w quark.EventWriter, inst *Instance, e *quark.Event) error
+
+// Process a long-running business transaction declared as a set of steps
+type Process struct {
+	name  string
+	group string
+	steps map[string]*Step
+	order []string
+}
+
+// NewProcess allocates and returns a Process
+func NewProcess(name string) *Process {
+	return &Process{
+		name:  name,
+		steps: map[string]*Step{},
+		order: make([]string, 0),
+	}
+}
+
+// On adds a step triggered by the given Message Type
+func (p *Process) On(msgType string) *Step {
+	if s, ok := p.steps[msgType]; ok {
+		return s
+	}
+	s := &Step{msgType: msgType}
+	p.steps[msgType] = s
+	p.order = append(p.order, msgType)
+	return s
+}
+
+// Group consumer group the process will use to receive messages, default is the process name
+func (p *Process) Group(g string) *Process {
+	p.group = g
+	return p
+}
+
+// GetName returns the process name
+func (p *Process) GetName() string {
+	return p.name
+}
+
+// GetTopics returns every Message Type the process is triggered by
+func (p *Process) GetTopics() []string {
+	return p.order
+}
+
+func (p *Process) setDefaultGroup() string {
+	if p.group != "" {
+		return p.group
+	}
+	return p.name
+}
+
+// Step a unit of work of a Process triggered by a specific Message Type
+type Step struct {
+	msgType    string
+	action     ActionFunc
+	compensate ActionFunc
+	timeout    time.Duration
+	starts     bool
+	completes  bool
+	fails      bool
+}
+
+// Do action executed when the step is triggered
+func (s *Step) Do(f ActionFunc) *Step {
+	s.action = f
+	return s
+}
+
+// Compensate action executed to undo the step side-effects when the process fails
+func (s *Step) Compensate(f ActionFunc) *Step {
+	s.compensate = f
+	return s
+}
+
+// Timeout maximum time to wait for the next step after this one was executed. If exceeded, the process is
+// compensated
+func (s *Step) Timeout(t time.Duration) *Step {
+	s.timeout = t
+	return s
+}
+
+// Start a new process instance is created when this step is triggered
+func (s *Step) Start() *Step {
+	s.starts = true
+	return s
+}
+
+// Complete the process instance is completed after this step is executed
+func (s *Step) Complete() *Step {
+	s.completes = true
+	return s
+}
+
+// Fail the process instance is compensated when this step is triggered (e.g. payment.failed)
+func (s *Step) Fail() *Step {
+	s.fails = true
+	return s
+}
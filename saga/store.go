@@ -0,0 +1,19 @@
+package saga
+
+import (
+	"context"
+	"time"
+)
+
+// Store process instance state storage
+type Store interface {
+	// Load returns the instance of the given process and correlation id or ErrInstanceNotFound
+	Load(ctx context.Context, process, correlationId string) (*Instance, error)
+	// Save stores the given instance.
+	//
+	// Returns ErrConcurrentUpdate if the stored version is different than the instance's, increments the instance
+	// version otherwise
+	Save(ctx context.Context, inst *Instance) error
+	// Expired returns every running instance of the given process with a deadline before now
+	Expired(ctx context.Context, process string, now time.Time) ([]*Instance, error)
+}
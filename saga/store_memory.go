@@ -0,0 +1,72 @@
+package saga
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore in-memory Store, intended for testing and local development
+type MemoryStore struct {
+	instances map[string]Instance
+	mu        sync.RWMutex
+}
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore allocates and returns a MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		instances: map[string]Instance{},
+		mu:        sync.RWMutex{},
+	}
+}
+
+// Load returns the instance of the given process and correlation id or ErrInstanceNotFound
+func (s *MemoryStore) Load(_ context.Context, process, correlationId string) (*Instance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	inst, ok := s.instances[process+"/"+correlationId]
+	if !ok {
+		return nil, ErrInstanceNotFound
+	}
+	return copyInstance(inst), nil
+}
+
+// Save stores the given instance
+func (s *MemoryStore) Save(_ context.Context, inst *Instance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := inst.Process + "/" + inst.CorrelationId
+	if prev, ok := s.instances[key]; ok && prev.Version != inst.Version {
+		return ErrConcurrentUpdate
+	} else if !ok && inst.Version != 0 {
+		return ErrConcurrentUpdate
+	}
+	inst.Version++
+	s.instances[key] = *copyInstance(*inst)
+	return nil
+}
+
+// Expired returns every running instance of the given process with a deadline before now
+func (s *MemoryStore) Expired(_ context.Context, process string, now time.Time) ([]*Instance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	expired := make([]*Instance, 0)
+	for _, inst := range s.instances {
+		if inst.Process == process && !inst.Done() && !inst.Deadline.IsZero() && inst.Deadline.Before(now) {
+			expired = append(expired, copyInstance(inst))
+		}
+	}
+	return expired, nil
+}
+
+func copyInstance(inst Instance) *Instance {
+	inst.Steps = append([]string(nil), inst.Steps...)
+	data := make(map[string]string, len(inst.Data))
+	for k, v := range inst.Data {
+		data[k] = v
+	}
+	inst.Data = data
+	return &inst
+}
@@ -0,0 +1,25 @@
+package saga
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_Save(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Memory store optimistic concurrency", func(t *testing.T) {
+		s := NewMemoryStore()
+		inst := newInstance("foo", "1")
+		assert.Nil(t, s.Save(ctx, inst))
+		assert.Equal(t, 1, inst.Version)
+
+		instA, _ := s.Load(ctx, "foo", "1")
+		instB, _ := s.Load(ctx, "foo", "1")
+		instA.Steps = append(instA.Steps, "bar")
+		assert.Nil(t, s.Save(ctx, instA))
+		assert.Equal(t, ErrConcurrentUpdate, s.Save(ctx, instB))
+		assert.Equal(t, ErrConcurrentUpdate, s.Save(ctx, newInstance("foo", "1")))
+	})
+}
@@ -0,0 +1,138 @@
+package saga
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/neutrinocorp/quark/dialect"
+)
+
+// DefaultTable default process instance table name
+const DefaultTable = "quark_saga_instances"
+
+// SQLStore Store backed by a database/sql compatible database (e.g. Postgres, SQLite, MySQL)
+type SQLStore struct {
+	DB      *sql.DB
+	Dialect dialect.Dialect
+	Table   string
+}
+
+var _ Store = &SQLStore{}
+
+// NewSQLStore allocates and returns a SQLStore using the DefaultTable
+func NewSQLStore(db *sql.DB, d dialect.Dialect) *SQLStore {
+	return &SQLStore{
+		DB:      db,
+		Dialect: d,
+		Table:   DefaultTable,
+	}
+}
+
+// Migrate creates the process instance table if it does not exist
+func (s *SQLStore) Migrate(ctx context.Context) error {
+	_, err := s.DB.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+s.setDefaultTable()+` (
+	process VARCHAR(255) NOT NULL,
+	correlation_id VARCHAR(255) NOT NULL,
+	status VARCHAR(32) NOT NULL,
+	state TEXT NOT NULL,
+	deadline TIMESTAMP NULL,
+	version INTEGER NOT NULL,
+	PRIMARY KEY (process, correlation_id)
+)`)
+	return err
+}
+
+// Load returns the instance of the given process and correlation id or ErrInstanceNotFound
+func (s *SQLStore) Load(ctx context.Context, process, correlationId string) (*Instance, error) {
+	query := s.Dialect.Rebind(`SELECT state, version FROM ` + s.setDefaultTable() +
+		` WHERE process = ? AND correlation_id = ?`)
+	state, version := "", 0
+	err := s.DB.QueryRowContext(ctx, query, process, correlationId).Scan(&state, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInstanceNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	inst := new(Instance)
+	if err = json.Unmarshal([]byte(state), inst); err != nil {
+		return nil, err
+	}
+	inst.Version = version
+	return inst, nil
+}
+
+// Save stores the given instance
+func (s *SQLStore) Save(ctx context.Context, inst *Instance) error {
+	inst.UpdatedAt = time.Now().UTC()
+	state, err := json.Marshal(inst)
+	if err != nil {
+		return err
+	}
+	var deadline interface{}
+	if !inst.Deadline.IsZero() {
+		deadline = inst.Deadline.UTC()
+	}
+
+	var res sql.Result
+	if inst.Version == 0 {
+		res, err = s.DB.ExecContext(ctx, s.Dialect.Rebind(`INSERT INTO `+s.setDefaultTable()+
+			` (process, correlation_id, status, state, deadline, version) VALUES (?, ?, ?, ?, ?, 1)`),
+			inst.Process, inst.CorrelationId, string(inst.Status), string(state), deadline)
+		if s.Dialect.IsUniqueViolation(err) {
+			return ErrConcurrentUpdate // another process created the instance first
+		} else if err != nil {
+			return err
+		}
+	} else {
+		res, err = s.DB.ExecContext(ctx, s.Dialect.Rebind(`UPDATE `+s.setDefaultTable()+
+			` SET status = ?, state = ?, deadline = ?, version = version + 1 `+
+			`WHERE process = ? AND correlation_id = ? AND version = ?`),
+			string(inst.Status), string(state), deadline, inst.Process, inst.CorrelationId, inst.Version)
+		if err != nil {
+			return err
+		}
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrConcurrentUpdate
+	}
+	inst.Version++
+	return nil
+}
+
+// Expired returns every running instance of the given process with a deadline before now
+func (s *SQLStore) Expired(ctx context.Context, process string, now time.Time) ([]*Instance, error) {
+	query := s.Dialect.Rebind(`SELECT state, version FROM ` + s.setDefaultTable() +
+		` WHERE process = ? AND status = ? AND deadline IS NOT NULL AND deadline < ?`)
+	rows, err := s.DB.QueryContext(ctx, query, process, string(StatusRunning), now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	expired := make([]*Instance, 0)
+	for rows.Next() {
+		state, version := "", 0
+		if err = rows.Scan(&state, &version); err != nil {
+			return nil, err
+		}
+		inst := new(Instance)
+		if err = json.Unmarshal([]byte(state), inst); err != nil {
+			return nil, err
+		}
+		inst.Version = version
+		expired = append(expired, inst)
+	}
+	return expired, rows.Err()
+}
+
+func (s *SQLStore) setDefaultTable() string {
+	if s.Table != "" {
+		return s.Table
+	}
+	return DefaultTable
+}
@@ -0,0 +1,68 @@
+package saga
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/quark/dialect"
+	"github.com/neutrinocorp/quark/internal/sqltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLiteTestingStore(t *testing.T) *SQLStore {
+	s := NewSQLStore(sqltest.Open(t, "saga.db"), dialect.SQLite)
+	require.Nil(t, s.Migrate(context.Background()))
+	return s
+}
+
+func TestSQLStore(t *testing.T) {
+	ctx := context.Background()
+	t.Run("SQL store save and load instance", func(t *testing.T) {
+		s := newSQLiteTestingStore(t)
+		inst := newInstance("order-fulfillment", "order-1")
+		inst.Steps = append(inst.Steps, "orders.placed")
+		assert.Nil(t, s.Save(ctx, inst))
+		assert.Equal(t, 1, inst.Version)
+		inst.Status = StatusCompleted
+		assert.Nil(t, s.Save(ctx, inst))
+		assert.Equal(t, 2, inst.Version)
+
+		loaded, err := s.Load(ctx, "order-fulfillment", "order-1")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, loaded.Version)
+		assert.Equal(t, StatusCompleted, loaded.Status)
+		assert.Equal(t, []string{"orders.placed"}, loaded.Steps)
+		_, err = s.Load(ctx, "order-fulfillment", "order-2")
+		assert.Equal(t, ErrInstanceNotFound, err)
+	})
+
+	t.Run("SQL store optimistic concurrency", func(t *testing.T) {
+		s := newSQLiteTestingStore(t)
+		assert.Nil(t, s.Save(ctx, newInstance("order-fulfillment", "order-1")))
+		assert.Equal(t, ErrConcurrentUpdate, s.Save(ctx, newInstance("order-fulfillment", "order-1")))
+
+		a, _ := s.Load(ctx, "order-fulfillment", "order-1")
+		b, _ := s.Load(ctx, "order-fulfillment", "order-1")
+		assert.Nil(t, s.Save(ctx, a))
+		assert.Equal(t, ErrConcurrentUpdate, s.Save(ctx, b))
+	})
+
+	t.Run("SQL store expired instances", func(t *testing.T) {
+		s := newSQLiteTestingStore(t)
+		stuck := newInstance("order-fulfillment", "order-1")
+		stuck.Deadline = time.Now().Add(-time.Minute)
+		assert.Nil(t, s.Save(ctx, stuck))
+		waiting := newInstance("order-fulfillment", "order-2")
+		waiting.Deadline = time.Now().Add(time.Minute)
+		assert.Nil(t, s.Save(ctx, waiting))
+		assert.Nil(t, s.Save(ctx, newInstance("order-fulfillment", "order-3")))
+
+		expired, err := s.Expired(ctx, "order-fulfillment", time.Now())
+		assert.Nil(t, err)
+		require.Len(t, expired, 1)
+		assert.Equal(t, "order-1", expired[0].CorrelationId)
+		assert.Equal(t, 1, expired[0].Version)
+	})
+}
@@ -0,0 +1,55 @@
+package spool
+
+// Option is a unit of configuration of a Spool
+type Option interface {
+	apply(*options)
+}
+
+type options struct {
+	segmentSize int64
+	maxBytes    int64
+	sync        bool
+}
+
+type segmentSizeOption int64
+
+func (o segmentSizeOption) apply(opts *options) {
+	opts.segmentSize = int64(o)
+}
+
+// WithSegmentSize defines the size a segment file may reach before a new one is created (default 64 MiB).
+//
+// Segments are removed once every message they contain was replayed
+func WithSegmentSize(n int64) Option {
+	if n <= 0 {
+		return segmentSizeOption(defaultSegmentSize)
+	}
+	return segmentSizeOption(n)
+}
+
+type maxBytesOption int64
+
+func (o maxBytesOption) apply(opts *options) {
+	opts.maxBytes = int64(o)
+}
+
+// WithMaxBytes defines the maximum disk usage of the spool (default 1 GiB), messages are rejected with ErrFull
+// once reached
+func WithMaxBytes(n int64) Option {
+	if n <= 0 {
+		return maxBytesOption(defaultMaxBytes)
+	}
+	return maxBytesOption(n)
+}
+
+type syncOption bool
+
+func (o syncOption) apply(opts *options) {
+	opts.sync = bool(o)
+}
+
+// WithSync flushes every append to stable storage (default true). Disabling it improves throughput but messages
+// may be lost if the host crashes
+func WithSync(sync bool) Option {
+	return syncOption(sync)
+}
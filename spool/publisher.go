@@ -0,0 +1,87 @@
+package spool
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/neutrinocorp/quark"
+)
+
+var defaultReplayInterval = time.Second * 5
+
+// Publisher is a quark.Publisher decorator storing messages into a Spool when the underlying Publisher fails.
+//
+// While the Spool has pending messages, new messages are spooled as well to keep the publishing order. Run must be
+// running to replay spooled messages once the underlying Publisher recovers
+type Publisher struct {
+	Publisher      quark.Publisher
+	Spool          *Spool
+	ErrorHandler   quark.ErrorHandler
+	ReplayInterval time.Duration
+}
+
+var _ quark.Publisher = &Publisher{}
+
+// NewPublisher allocates and returns a Publisher
+func NewPublisher(p quark.Publisher, s *Spool) *Publisher {
+	return &Publisher{
+		Publisher:      p,
+		Spool:          s,
+		ReplayInterval: defaultReplayInterval,
+	}
+}
+
+// Publish pushes the given messages using the underlying Publisher, messages are spooled if it fails.
+//
+// Returns an error only if the messages could not be spooled (e.g. ErrFull)
+func (p *Publisher) Publish(ctx context.Context, msgs ...*quark.Message) error {
+	if p.Publisher == nil {
+		return quark.ErrPublisherNotImplemented
+	} else if p.Spool == nil {
+		return ErrNilSpool
+	}
+	if p.Spool.Pending() == 0 {
+		err := p.Publisher.Publish(ctx, msgs...)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		if errSpool := p.Spool.Append(msgs...); errSpool != nil {
+			return multierror.Append(err, errSpool)
+		}
+		return nil
+	}
+	return p.Spool.Append(msgs...)
+}
+
+// Run replays the spooled messages every ReplayInterval until ctx is cancelled
+func (p *Publisher) Run(ctx context.Context) error {
+	if p.Publisher == nil {
+		return quark.ErrPublisherNotImplemented
+	} else if p.Spool == nil {
+		return ErrNilSpool
+	}
+
+	ticker := time.NewTicker(p.setDefaultReplayInterval())
+	defer ticker.Stop()
+	for {
+		if p.Spool.Pending() > 0 {
+			if _, err := p.Spool.Replay(ctx, p.Publisher); err != nil && ctx.Err() == nil && p.ErrorHandler != nil {
+				p.ErrorHandler(ctx, fmt.Errorf("spool: replay: %w", err))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Publisher) setDefaultReplayInterval() time.Duration {
+	if p.ReplayInterval > 0 {
+		return p.ReplayInterval
+	}
+	return defaultReplayInterval
+}
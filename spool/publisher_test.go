@@ -0,0 +1,87 @@
+package spool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+)
+
+type switchPublisher struct {
+	mu        sync.Mutex
+	down      bool
+	published []string
+}
+
+func (p *switchPublisher) setDown(down bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.down = down
+}
+
+func (p *switchPublisher) Publish(_ context.Context, msgs ...*quark.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.down {
+		return errStubPublisher
+	}
+	for _, m := range msgs {
+		p.published = append(p.published, m.Id)
+	}
+	return nil
+}
+
+func (p *switchPublisher) getPublished() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.published...)
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	msgs := newTestingMessages(4)
+	ctx := context.Background()
+
+	t.Run("Spool messages while publisher is down", func(t *testing.T) {
+		p := &switchPublisher{}
+		s := openTestingSpool(t, t.TempDir())
+		pub := NewPublisher(p, s)
+
+		assert.Nil(t, pub.Publish(ctx, msgs[0]))
+		p.setDown(true)
+		assert.Nil(t, pub.Publish(ctx, msgs[1]))
+		p.setDown(false)
+		// keeps ordering while the spool has pending messages
+		assert.Nil(t, pub.Publish(ctx, msgs[2], msgs[3]))
+		assert.Equal(t, []string{"0"}, p.getPublished())
+		assert.Equal(t, 3, s.Pending())
+
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		pub.ReplayInterval = time.Millisecond * 5
+		go func() {
+			_ = pub.Run(runCtx)
+		}()
+		assert.Eventually(t, func() bool {
+			return s.Pending() == 0
+		}, time.Second, time.Millisecond*5)
+		assert.Equal(t, []string{"0", "1", "2", "3"}, p.getPublished())
+	})
+
+	t.Run("Return error if spool is full", func(t *testing.T) {
+		p := &switchPublisher{down: true}
+		pub := NewPublisher(p, openTestingSpool(t, t.TempDir(), WithMaxBytes(10)))
+		err := pub.Publish(ctx, msgs[0])
+		assert.True(t, errors.Is(err, errStubPublisher))
+		assert.True(t, errors.Is(err, ErrFull))
+	})
+
+	t.Run("Publish without spool", func(t *testing.T) {
+		pub := NewPublisher(&switchPublisher{}, nil)
+		assert.True(t, errors.Is(pub.Publish(ctx, msgs[0]), ErrNilSpool))
+		assert.True(t, errors.Is(pub.Run(ctx), ErrNilSpool))
+	})
+}
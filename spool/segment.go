@@ -0,0 +1,111 @@
+package spool
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	segmentExt = ".seg"
+	// recordHeaderSize payload length and its CRC-32C checksum, both big-endian uint32
+	recordHeaderSize = 8
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// segment an append-only file of records, named after its sequential id
+type segment struct {
+	id   uint64
+	size int64
+}
+
+func segmentPath(dir string, id uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", id, segmentExt))
+}
+
+// listSegments returns the segments stored in dir ordered by id
+func listSegments(dir string) ([]segment, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	segments := make([]segment, 0)
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), segmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(f.Name(), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, segment{id: id, size: f.Size()})
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].id < segments[j].id
+	})
+	return segments, nil
+}
+
+// encodeRecord prefixes the payload with its length and checksum
+func encodeRecord(payload []byte) []byte {
+	rec := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(rec[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(rec[4:8], crc32.Checksum(payload, crcTable))
+	copy(rec[recordHeaderSize:], payload)
+	return rec
+}
+
+// readRecord reads the record stored at the given offset, returns ErrCorruptRecord if the record is truncated or
+// its checksum does not match
+func readRecord(r io.ReaderAt, offset, size int64) ([]byte, error) {
+	if size-offset < recordHeaderSize {
+		return nil, ErrCorruptRecord
+	}
+	header := make([]byte, recordHeaderSize)
+	if _, err := r.ReadAt(header, offset); err != nil {
+		return nil, err
+	}
+	n := int64(binary.BigEndian.Uint32(header[0:4]))
+	if size-offset-recordHeaderSize < n {
+		return nil, ErrCorruptRecord
+	}
+	payload := make([]byte, n)
+	if _, err := r.ReadAt(payload, offset+recordHeaderSize); err != nil {
+		return nil, err
+	}
+	if crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, ErrCorruptRecord
+	}
+	return payload, nil
+}
+
+// scanSegment counts the valid records of the given segment starting at offset.
+//
+// Returns the offset following the last valid record, it is lower than the segment size if a corrupt record was
+// found
+func scanSegment(dir string, seg segment, offset int64) (records int, end int64, err error) {
+	f, err := os.Open(segmentPath(dir, seg.id))
+	if err != nil {
+		return 0, offset, err
+	}
+	defer f.Close()
+	for offset < seg.size {
+		payload, err := readRecord(f, offset, seg.size)
+		if err == ErrCorruptRecord {
+			return records, offset, nil
+		} else if err != nil {
+			return records, offset, err
+		}
+		offset += recordHeaderSize + int64(len(payload))
+		records++
+	}
+	return records, offset, nil
+}
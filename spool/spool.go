@@ -0,0 +1,422 @@
+// Package spool Durable local spool for Quark publishers.
+//
+// A Spool is an on-disk append-only log storing messages which could not be published (e.g. the cluster is not
+// reachable from an edge service). Messages are stored into segment files as checksummed records, so torn writes and
+// corrupted records are detected and skipped. A Publisher spools messages whenever the underlying publisher fails and
+// replays them in order once it recovers, with at-least-once delivery semantics.
+package spool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/neutrinocorp/quark"
+)
+
+const checkpointFile = "checkpoint"
+
+var (
+	defaultSegmentSize int64 = 64 << 20
+	defaultMaxBytes    int64 = 1 << 30
+)
+
+var (
+	// ErrFull the spool reached its maximum disk usage
+	ErrFull = errors.New("spool: maximum size exceeded")
+	// ErrClosed the spool was already closed
+	ErrClosed = errors.New("spool: closed")
+	// ErrNilSpool the given spool is nil
+	ErrNilSpool = errors.New("spool: spool is nil")
+	// ErrCorruptRecord a record is truncated or its checksum does not match
+	ErrCorruptRecord = errors.New("spool: corrupt record")
+)
+
+// Stats is a snapshot of a Spool usage and counters
+type Stats struct {
+	// Pending messages waiting to be replayed
+	Pending int `json:"pending"`
+	// Segments files currently stored
+	Segments int `json:"segments"`
+	// Bytes current disk usage
+	Bytes int64 `json:"bytes"`
+	// Appended messages stored since the spool was opened
+	Appended int64 `json:"appended"`
+	// Replayed messages published since the spool was opened
+	Replayed int64 `json:"replayed"`
+	// Rejected messages not stored because the spool was full since it was opened
+	Rejected int64 `json:"rejected"`
+	// Corrupted records skipped since the spool was opened
+	Corrupted int64 `json:"corrupted"`
+}
+
+// position of the next record to replay
+type position struct {
+	segment uint64
+	offset  int64
+}
+
+// Spool an on-disk append-only message log, safe for concurrent use.
+//
+// Messages are replayed in the order they were appended, the replay position is stored into a checkpoint file so
+// replayed messages are not published again after a restart
+type Spool struct {
+	dir  string
+	opts options
+
+	mu       sync.Mutex
+	segments []segment // ordered by id, the last one is the active segment
+	active   *os.File
+	read     position
+	stats    Stats
+	closed   bool
+}
+
+var _ quark.Publisher = &Spool{}
+
+// Open opens the spool stored in dir, creating it if required.
+//
+// Truncated records at the end of the spool (e.g. the process crashed while appending) are removed
+func Open(dir string, opts ...Option) (*Spool, error) {
+	options := options{
+		segmentSize: defaultSegmentSize,
+		maxBytes:    defaultMaxBytes,
+		sync:        true,
+	}
+	for _, o := range opts {
+		o.apply(&options)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	s := &Spool{dir: dir, opts: options}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Spool) load() (err error) {
+	if s.segments, err = listSegments(s.dir); err != nil {
+		return err
+	}
+	if s.read, err = s.readCheckpoint(); err != nil {
+		return err
+	}
+	if len(s.segments) == 0 {
+		s.segments = append(s.segments, segment{id: s.read.segment + 1})
+	}
+	if s.read.segment < s.segments[0].id {
+		s.read = position{segment: s.segments[0].id}
+	}
+	for i, seg := range s.segments {
+		s.stats.Bytes += seg.size
+		if seg.id < s.read.segment {
+			continue
+		}
+		offset := int64(0)
+		if seg.id == s.read.segment {
+			offset = s.read.offset
+		}
+		records, end, err := scanSegment(s.dir, seg, offset)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		s.stats.Pending += records
+		if i == len(s.segments)-1 && end < seg.size {
+			// torn write, records appended after this offset were never acknowledged
+			if err = os.Truncate(segmentPath(s.dir, seg.id), end); err != nil {
+				return err
+			}
+			s.stats.Bytes -= seg.size - end
+			s.segments[i].size = end
+		}
+	}
+	last := s.segments[len(s.segments)-1]
+	s.active, err = os.OpenFile(segmentPath(s.dir, last.id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	return err
+}
+
+// Publish appends the given messages into the spool, so it may be used as fallback Publisher
+// (e.g. quark.CircuitBreakerConfig)
+func (s *Spool) Publish(_ context.Context, msgs ...*quark.Message) error {
+	return s.Append(msgs...)
+}
+
+// Append stores the given messages at the end of the spool.
+//
+// Messages are either all stored or none of them, ErrFull is returned if they would exceed the maximum size
+func (s *Spool) Append(msgs ...*quark.Message) error {
+	buf := make([]byte, 0)
+	records := 0
+	for _, msg := range msgs {
+		if msg == nil {
+			continue
+		}
+		payload, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		buf = append(buf, encodeRecord(payload)...)
+		records++
+	}
+	if records == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	} else if s.stats.Bytes+int64(len(buf)) > s.opts.maxBytes {
+		s.stats.Rejected += int64(records)
+		return ErrFull
+	}
+	if s.activeLocked().size > 0 && s.activeLocked().size+int64(len(buf)) > s.opts.segmentSize {
+		if err := s.rotateLocked(); err != nil {
+			return err
+		}
+	}
+	_, err := s.active.Write(buf)
+	if err == nil && s.opts.sync {
+		err = s.active.Sync()
+	}
+	if err != nil {
+		// remove the partial write, so following records are not appended after a corrupt one
+		_ = s.active.Truncate(s.activeLocked().size)
+		return err
+	}
+	s.activeLocked().size += int64(len(buf))
+	s.stats.Bytes += int64(len(buf))
+	s.stats.Pending += records
+	s.stats.Appended += int64(records)
+	return nil
+}
+
+// Replay publishes the spooled messages in order using the given Publisher, stopping at the first failure.
+//
+// Returns the number of messages published
+func (s *Spool) Replay(ctx context.Context, p quark.Publisher) (int, error) {
+	if p == nil {
+		return 0, quark.ErrPublisherNotImplemented
+	}
+	replayed := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+		msg, next, err := s.next()
+		if err == io.EOF {
+			return replayed, nil
+		} else if err != nil {
+			return replayed, err
+		}
+		if err = p.Publish(ctx, msg); err != nil {
+			return replayed, err
+		}
+		if err = s.commit(next); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+}
+
+// Pending returns the number of messages waiting to be replayed
+func (s *Spool) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats.Pending
+}
+
+// Stats returns a snapshot of the spool usage and counters
+func (s *Spool) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.stats
+	st.Segments = len(s.segments)
+	return st
+}
+
+// Close closes the active segment, pending messages are kept on disk
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	s.closed = true
+	return s.active.Close()
+}
+
+// next returns the message at the replay position and the position following it, io.EOF if there are no pending
+// messages. Corrupted records are skipped
+func (s *Spool) next() (*quark.Message, position, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, position{}, ErrClosed
+	}
+	for s.stats.Pending > 0 {
+		i := s.segmentIndexLocked(s.read.segment)
+		if i < 0 {
+			s.stats.Pending = 0 // segments removed externally
+			break
+		}
+		seg := s.segments[i]
+		if s.read.offset >= seg.size {
+			if !s.advanceLocked(i) {
+				break
+			}
+			continue
+		}
+		payload, err := readSegmentRecord(s.dir, seg, s.read.offset)
+		if err == ErrCorruptRecord {
+			// the remaining records of the segment cannot be located
+			s.stats.Corrupted++
+			s.read.offset = seg.size
+			continue
+		} else if err != nil {
+			return nil, position{}, err
+		}
+		next := position{segment: seg.id, offset: s.read.offset + recordHeaderSize + int64(len(payload))}
+		msg := new(quark.Message)
+		if err = json.Unmarshal(payload, msg); err != nil {
+			s.stats.Corrupted++
+			s.stats.Pending--
+			s.read = next
+			continue
+		}
+		return msg, next, nil
+	}
+	if err := s.resetLocked(); err != nil {
+		return nil, position{}, err
+	}
+	return nil, position{}, io.EOF
+}
+
+// commit moves the replay position once the message before it was published
+func (s *Spool) commit(next position) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.read = next
+	s.stats.Pending--
+	s.stats.Replayed++
+	if s.stats.Pending == 0 {
+		return s.resetLocked()
+	}
+	return s.writeCheckpointLocked()
+}
+
+// advanceLocked moves the replay position into the segment following i removing the replayed segment, returns false
+// if i is the active segment
+func (s *Spool) advanceLocked(i int) bool {
+	if i == len(s.segments)-1 {
+		s.stats.Pending = 0
+		return false
+	}
+	s.read = position{segment: s.segments[i+1].id}
+	s.removeSegmentLocked(i)
+	return true
+}
+
+// resetLocked removes every replayed segment once there are no pending messages, truncating the active one
+func (s *Spool) resetLocked() error {
+	if len(s.segments) == 1 && s.segments[0].size == 0 && s.read == (position{segment: s.segments[0].id}) {
+		return nil
+	}
+	for len(s.segments) > 1 {
+		s.removeSegmentLocked(0)
+	}
+	if s.segments[0].size > 0 {
+		if err := s.active.Truncate(0); err != nil {
+			return err
+		}
+		s.stats.Bytes -= s.segments[0].size
+		s.segments[0].size = 0
+	}
+	s.read = position{segment: s.segments[0].id}
+	return s.writeCheckpointLocked()
+}
+
+func (s *Spool) removeSegmentLocked(i int) {
+	if err := os.Remove(segmentPath(s.dir, s.segments[i].id)); err == nil || os.IsNotExist(err) {
+		s.stats.Bytes -= s.segments[i].size
+	}
+	s.segments = append(s.segments[:i], s.segments[i+1:]...)
+}
+
+func (s *Spool) rotateLocked() error {
+	id := s.activeLocked().id + 1
+	f, err := os.OpenFile(segmentPath(s.dir, id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if err = s.active.Close(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	s.active = f
+	s.segments = append(s.segments, segment{id: id})
+	return nil
+}
+
+func (s *Spool) activeLocked() *segment {
+	return &s.segments[len(s.segments)-1]
+}
+
+func (s *Spool) segmentIndexLocked(id uint64) int {
+	for i, seg := range s.segments {
+		if seg.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Spool) readCheckpoint() (position, error) {
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, checkpointFile))
+	if os.IsNotExist(err) {
+		return position{}, nil
+	} else if err != nil {
+		return position{}, err
+	}
+	var p position
+	if _, err = fmt.Sscanf(string(data), "%d %d", &p.segment, &p.offset); err != nil {
+		return position{}, fmt.Errorf("spool: checkpoint: %w", err)
+	}
+	return p, nil
+}
+
+// writeCheckpointLocked stores the replay position replacing the checkpoint file atomically
+func (s *Spool) writeCheckpointLocked() error {
+	path := filepath.Join(s.dir, checkpointFile)
+	f, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(f, "%d %d\n", s.read.segment, s.read.offset); err == nil && s.opts.sync {
+		err = f.Sync()
+	}
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func readSegmentRecord(dir string, seg segment, offset int64) ([]byte, error) {
+	f, err := os.Open(segmentPath(dir, seg.id))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readRecord(f, offset, seg.size)
+}
@@ -0,0 +1,168 @@
+package spool
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/neutrinocorp/quark"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errStubPublisher = errors.New("generic stub publisher error")
+
+type stubPublisher struct {
+	err       error
+	failAfter int
+	published []string
+}
+
+func (p *stubPublisher) Publish(_ context.Context, msgs ...*quark.Message) error {
+	if p.err != nil && len(p.published) >= p.failAfter {
+		return p.err
+	}
+	for _, m := range msgs {
+		p.published = append(p.published, m.Id)
+	}
+	return nil
+}
+
+func newTestingMessages(n int) []*quark.Message {
+	msgs := make([]*quark.Message, 0, n)
+	for i := 0; i < n; i++ {
+		msgs = append(msgs, quark.NewMessage(strconv.Itoa(i), "chat.1", []byte("hello")))
+	}
+	return msgs
+}
+
+func openTestingSpool(t *testing.T, dir string, opts ...Option) *Spool {
+	s, err := Open(dir, opts...)
+	require.Nil(t, err)
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	return s
+}
+
+func TestSpool_Replay(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Replay messages in order across segments", func(t *testing.T) {
+		s := openTestingSpool(t, t.TempDir(), WithSegmentSize(512))
+		for _, msg := range newTestingMessages(10) {
+			require.Nil(t, s.Append(msg))
+		}
+		st := s.Stats()
+		assert.Equal(t, 10, st.Pending)
+		assert.True(t, st.Segments > 1)
+
+		p := &stubPublisher{}
+		n, err := s.Replay(ctx, p)
+		assert.Nil(t, err)
+		assert.Equal(t, 10, n)
+		assert.Equal(t, []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}, p.published)
+
+		st = s.Stats()
+		assert.Equal(t, 0, st.Pending)
+		assert.Equal(t, 1, st.Segments)
+		assert.Equal(t, int64(0), st.Bytes)
+		assert.Equal(t, int64(10), st.Replayed)
+	})
+
+	t.Run("Resume replay after failure and restart", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := Open(dir, WithSegmentSize(512))
+		require.Nil(t, err)
+		require.Nil(t, s.Append(newTestingMessages(6)...))
+
+		p := &stubPublisher{err: errStubPublisher, failAfter: 2}
+		n, err := s.Replay(ctx, p)
+		assert.True(t, errors.Is(err, errStubPublisher))
+		assert.Equal(t, 2, n)
+		assert.Equal(t, 4, s.Pending())
+		require.Nil(t, s.Close())
+
+		s = openTestingSpool(t, dir, WithSegmentSize(512))
+		assert.Equal(t, 4, s.Pending())
+		p.err = nil
+		n, err = s.Replay(ctx, p)
+		assert.Nil(t, err)
+		assert.Equal(t, 4, n)
+		assert.Equal(t, []string{"0", "1", "2", "3", "4", "5"}, p.published)
+	})
+
+	t.Run("Truncate torn write on open", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := Open(dir)
+		require.Nil(t, err)
+		require.Nil(t, s.Append(newTestingMessages(2)...))
+		size := s.Stats().Bytes
+		require.Nil(t, s.Close())
+
+		f, err := os.OpenFile(segmentPath(dir, 1), os.O_WRONLY|os.O_APPEND, 0600)
+		require.Nil(t, err)
+		_, err = f.Write(encodeRecord([]byte(`{"id":"2"}`))[:10])
+		require.Nil(t, err)
+		require.Nil(t, f.Close())
+
+		s = openTestingSpool(t, dir)
+		assert.Equal(t, 2, s.Pending())
+		assert.Equal(t, size, s.Stats().Bytes)
+		require.Nil(t, s.Append(newTestingMessages(1)...))
+		p := &stubPublisher{}
+		_, err = s.Replay(ctx, p)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"0", "1", "0"}, p.published)
+	})
+
+	t.Run("Skip corrupted segment", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := Open(dir, WithSegmentSize(256))
+		require.Nil(t, err)
+		for _, msg := range newTestingMessages(4) {
+			require.Nil(t, s.Append(msg))
+		}
+		require.Nil(t, s.Close())
+
+		data, err := ioutil.ReadFile(segmentPath(dir, 1))
+		require.Nil(t, err)
+		data[len(data)-2] ^= 0xff
+		require.Nil(t, ioutil.WriteFile(segmentPath(dir, 1), data, 0600))
+
+		s = openTestingSpool(t, dir, WithSegmentSize(256))
+		p := &stubPublisher{}
+		_, err = s.Replay(ctx, p)
+		assert.Nil(t, err)
+		assert.NotContains(t, p.published, "0")
+		assert.Contains(t, p.published, "3")
+		assert.Equal(t, int64(1), s.Stats().Corrupted)
+	})
+}
+
+func TestSpool_Append(t *testing.T) {
+	t.Run("Reject messages exceeding maximum size", func(t *testing.T) {
+		s := openTestingSpool(t, t.TempDir(), WithMaxBytes(300))
+		assert.Nil(t, s.Append(newTestingMessages(1)...))
+		assert.True(t, errors.Is(s.Append(newTestingMessages(2)...), ErrFull))
+		st := s.Stats()
+		assert.Equal(t, 1, st.Pending)
+		assert.Equal(t, int64(2), st.Rejected)
+	})
+
+	t.Run("Append into closed spool", func(t *testing.T) {
+		s, err := Open(t.TempDir())
+		require.Nil(t, err)
+		require.Nil(t, s.Close())
+		assert.True(t, errors.Is(s.Append(newTestingMessages(1)...), ErrClosed))
+	})
+
+	t.Run("Append without messages", func(t *testing.T) {
+		s := openTestingSpool(t, t.TempDir())
+		assert.Nil(t, s.Append(nil))
+		assert.Equal(t, 0, s.Pending())
+	})
+}
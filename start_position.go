@@ -0,0 +1,74 @@
+package quark
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// StartKind kind of position a Consumer starts consuming from
+type StartKind int
+
+const (
+	// StartDefault the provider default position is used
+	StartDefault StartKind = iota
+	// StartOffset an specific offset
+	StartOffset
+	// StartNewest the next message published after the Consumer starts
+	StartNewest
+	// StartOldest the oldest message available
+	StartOldest
+	// StartTime the first message published at or after an specific time
+	StartTime
+)
+
+// StartPosition position a Consumer starts consuming from (e.g. to replay a topic from an specific time)
+type StartPosition struct {
+	Kind   StartKind
+	Offset int64
+	Time   time.Time
+}
+
+var (
+	// Newest starts consuming from the next message published after the Consumer starts
+	Newest = StartPosition{Kind: StartNewest}
+	// Oldest starts consuming from the oldest message available
+	Oldest = StartPosition{Kind: StartOldest}
+)
+
+// Offset starts consuming from the given offset
+func Offset(n int64) StartPosition {
+	return StartPosition{Kind: StartOffset, Offset: n}
+}
+
+// Timestamp starts consuming from the first message published at or after the given time
+func Timestamp(t time.Time) StartPosition {
+	return StartPosition{Kind: StartTime, Time: t}
+}
+
+// IsDefault verifies if the provider default position must be used
+func (p StartPosition) IsDefault() bool {
+	return p.Kind == StartDefault
+}
+
+// ParseStartPosition parses the given text into a StartPosition.
+//
+// Accepts "newest", "oldest", an offset (e.g. "42") or an RFC 3339 timestamp; empty text returns the default position
+func ParseStartPosition(text string) (StartPosition, error) {
+	switch text {
+	case "":
+		return StartPosition{}, nil
+	case "newest":
+		return Newest, nil
+	case "oldest":
+		return Oldest, nil
+	}
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return Offset(n), nil
+	}
+	t, err := time.Parse(time.RFC3339, text)
+	if err != nil {
+		return StartPosition{}, fmt.Errorf("start position %q: %w", text, ErrInvalidStartPosition)
+	}
+	return Timestamp(t), nil
+}
@@ -0,0 +1,13 @@
+package quark
+
+// StartupMode defines how a Broker handles supervisors and workers failing to start
+type StartupMode int
+
+const (
+	// StartupAtomic all-or-nothing startup, if any Supervisor fails to start, every Supervisor and worker already
+	// started is closed and the error is returned
+	StartupAtomic StartupMode = iota
+	// StartupBestEffort starts every Supervisor and worker it can. Failing supervisors are kept as degraded
+	// (see SupervisorStatus) and their errors are sent to the ErrorHandler
+	StartupBestEffort
+)
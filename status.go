@@ -0,0 +1,185 @@
+package quark
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// WorkerState is the current lifecycle phase of a Worker
+type WorkerState int
+
+const (
+	// WorkerStarting the worker is connecting to the cluster
+	WorkerStarting WorkerState = iota
+	// WorkerRunning the worker is fetching data
+	WorkerRunning
+	// WorkerReconnecting the worker lost its connection to the cluster and is retrying
+	WorkerReconnecting
+	// WorkerClosed the worker stopped fetching data
+	WorkerClosed
+)
+
+var workerStateNames = map[WorkerState]string{
+	WorkerStarting:     "starting",
+	WorkerRunning:      "running",
+	WorkerReconnecting: "reconnecting",
+	WorkerClosed:       "closed",
+}
+
+func (s WorkerState) String() string {
+	if name, ok := workerStateNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// MarshalText encodes the WorkerState using its name
+func (s WorkerState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// BrokerStatus is a snapshot of a Broker and its running supervisors
+type BrokerStatus struct {
+	Serving      bool               `json:"serving"`
+	ShuttingDown bool               `json:"shutting_down"`
+	Supervisors  []SupervisorStatus `json:"supervisors"`
+	// Unscheduled consumers registered into the EventMux without a running Supervisor (e.g. drained or failed to
+	// restart)
+	Unscheduled []ConsumerStatus `json:"unscheduled,omitempty"`
+	// CircuitBreakers state of the circuit breakers used as Broker or Consumer Publisher
+	CircuitBreakers []CircuitBreakerStatus `json:"circuit_breakers,omitempty"`
+}
+
+// ConsumerStatus identifies a Consumer registered into the EventMux
+type ConsumerStatus struct {
+	Topics []string `json:"topics"`
+	Group  string   `json:"group"`
+}
+
+func (s ConsumerStatus) key() string {
+	return strings.Join(s.Topics, ",") + "/" + s.Group
+}
+
+// SupervisorStatus is a snapshot of a Supervisor effective configuration and its running workers
+type SupervisorStatus struct {
+	Topics       []string      `json:"topics"`
+	Group        string        `json:"group"`
+	Cluster      []string      `json:"cluster"`
+	PoolSize     int           `json:"pool_size"`
+	MaxRetries   int           `json:"max_retries"`
+	RetryBackoff time.Duration `json:"retry_backoff"`
+	// HandlerTimeout maximum time a handler may take to serve an Event, zero if handlers have no deadline
+	HandlerTimeout time.Duration `json:"handler_timeout"`
+	Paused         bool          `json:"paused"`
+	// Restarts is the number of worker restarts within the current restart policy window
+	Restarts int `json:"restarts"`
+	// Panics is the number of handler panics recovered since the Supervisor was created
+	Panics int64 `json:"panics"`
+	// Timeouts is the number of Events which exceeded the handler timeout since the Supervisor was created
+	Timeouts int64 `json:"timeouts"`
+	// Degraded indicates the Supervisor could not start every worker (StartupBestEffort mode), see Error
+	Degraded bool           `json:"degraded"`
+	Error    string         `json:"error,omitempty"`
+	Workers  []WorkerStatus `json:"workers"`
+}
+
+func (s SupervisorStatus) key() string {
+	return strings.Join(s.Topics, ",") + "/" + s.Group
+}
+
+// WorkerStatus is a snapshot of a Worker state
+type WorkerStatus struct {
+	Id        int         `json:"id"`
+	State     WorkerState `json:"state"`
+	LastError string      `json:"last_error,omitempty"`
+	// Partitions assigned to the worker by topic, only available on partitioned providers (e.g. Apache Kafka)
+	Partitions map[string][]int32 `json:"partitions,omitempty"`
+	// Offsets is the offset of the last processed message by topic and partition, providers without partitions
+	// (e.g. in-memory bus) use partition zero
+	Offsets map[string]map[int32]int64 `json:"offsets,omitempty"`
+}
+
+// StatusReporter is a Worker able to report its own state.
+//
+// Workers not implementing this interface are reported as running while they are scheduled
+type StatusReporter interface {
+	Status() WorkerStatus
+}
+
+// WorkerTracker is a thread-safe WorkerStatus recorder. Providers may embed it into their workers to implement
+// StatusReporter
+type WorkerTracker struct {
+	mu     sync.RWMutex
+	status WorkerStatus
+}
+
+// Reset sets the tracker to its initial state, useful when pooled workers get started again
+func (t *WorkerTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status = WorkerStatus{State: WorkerStarting}
+}
+
+// SetState sets the current worker state
+func (t *WorkerTracker) SetState(s WorkerState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.State = s
+}
+
+// SetError records the last error the worker got, nil errors are ignored
+func (t *WorkerTracker) SetError(err error) {
+	if err == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.LastError = err.Error()
+}
+
+// SetPartitions replaces the partitions currently assigned to the worker
+func (t *WorkerTracker) SetPartitions(partitions map[string][]int32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.Partitions = make(map[string][]int32, len(partitions))
+	for topic, ps := range partitions {
+		t.status.Partitions[topic] = append([]int32(nil), ps...)
+	}
+}
+
+// SetOffset records the offset of the last processed message of the given topic and partition
+func (t *WorkerTracker) SetOffset(topic string, partition int32, offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.status.Offsets == nil {
+		t.status.Offsets = map[string]map[int32]int64{}
+	}
+	if t.status.Offsets[topic] == nil {
+		t.status.Offsets[topic] = map[int32]int64{}
+	}
+	t.status.Offsets[topic][partition] = offset
+}
+
+// Status returns a copy of the current worker state
+func (t *WorkerTracker) Status() WorkerStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	st := t.status
+	if len(t.status.Partitions) > 0 {
+		st.Partitions = make(map[string][]int32, len(t.status.Partitions))
+		for topic, ps := range t.status.Partitions {
+			st.Partitions[topic] = append([]int32(nil), ps...)
+		}
+	}
+	if len(t.status.Offsets) > 0 {
+		st.Offsets = make(map[string]map[int32]int64, len(t.status.Offsets))
+		for topic, offsets := range t.status.Offsets {
+			st.Offsets[topic] = make(map[int32]int64, len(offsets))
+			for p, o := range offsets {
+				st.Offsets[topic][p] = o
+			}
+		}
+	}
+	return st
+}
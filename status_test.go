@@ -0,0 +1,54 @@
+package quark
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var workerStateTestingSuite = []struct {
+	s        WorkerState
+	expected string
+}{
+	{WorkerStarting, "starting"},
+	{WorkerRunning, "running"},
+	{WorkerReconnecting, "reconnecting"},
+	{WorkerClosed, "closed"},
+	{WorkerState(99), "unknown"},
+}
+
+func TestWorkerState_String(t *testing.T) {
+	for _, tt := range workerStateTestingSuite {
+		t.Run("Worker state name", func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.s.String())
+		})
+	}
+}
+
+func TestWorkerTracker(t *testing.T) {
+	t.Run("Worker tracker records state", func(t *testing.T) {
+		tracker := new(WorkerTracker)
+		tracker.Reset()
+		assert.Equal(t, WorkerStarting, tracker.Status().State)
+		assert.Empty(t, tracker.Status().Offsets)
+
+		partitions := map[string][]int32{"chat.0": {0, 1}}
+		tracker.SetState(WorkerRunning)
+		tracker.SetError(errors.New("broker not available"))
+		tracker.SetError(nil)
+		tracker.SetPartitions(partitions)
+		tracker.SetOffset("chat.0", 0, 12)
+		tracker.SetOffset("chat.0", 1, 7)
+		tracker.SetOffset("chat.0", 0, 13)
+		partitions["chat.0"][0] = 5
+
+		st := tracker.Status()
+		assert.Equal(t, WorkerRunning, st.State)
+		assert.Equal(t, "broker not available", st.LastError)
+		assert.Equal(t, []int32{0, 1}, st.Partitions["chat.0"])
+		assert.Equal(t, map[string]map[int32]int64{"chat.0": {0: 13, 1: 7}}, st.Offsets)
+		st.Offsets["chat.0"][1] = 0
+		assert.Equal(t, int64(7), tracker.Status().Offsets["chat.0"][1])
+	})
+}
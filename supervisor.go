@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/eapache/queue"
@@ -14,11 +15,20 @@ import (
 //
 // Distributes blocking I/O operations into different goroutines to enable parallelism with fan-out mechanisms.
 type Supervisor struct {
+	panics   int64 // accessed atomically, kept first for 64-bit alignment
+	timeouts int64 // accessed atomically
+
 	Broker   *Broker
 	Consumer *Consumer
 
 	workers        sync.Pool
 	runningWorkers *queue.Queue
+	paused         atomicBool
+	closed         atomicBool
+	ctx            context.Context
+	restarts       []time.Time
+	startErr       error
+	mu             sync.Mutex
 }
 
 func newSupervisor(b *Broker, c *Consumer) *Supervisor {
@@ -37,11 +47,31 @@ func newSupervisor(b *Broker, c *Consumer) *Supervisor {
 	return s
 }
 
-// ScheduleJobs starts the blocking I/O consuming operations from the current Consumer parent
+// ScheduleJobs starts the blocking I/O consuming operations from the current Consumer parent.
+//
+// Using StartupAtomic mode, workers already started are closed if any of them fails to start; otherwise
+// (StartupBestEffort), they keep running and the Supervisor is reported as degraded
 func (n *Supervisor) ScheduleJobs(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.ctx = ctx
+	n.closed.setFalse()
 	if err := n.ensureValidParams(); err != nil {
+		n.startErr = err
 		return err
 	}
+	err := n.scheduleJobsLocked(ctx)
+	n.startErr = err
+	if err != nil && n.Broker.setDefaultStartupMode() == StartupAtomic {
+		n.closed.setTrue()
+		if errRollback := n.closeLocked(); errRollback != nil {
+			err = multierror.Append(err, errRollback)
+		}
+	}
+	return err
+}
+
+func (n *Supervisor) scheduleJobsLocked(ctx context.Context) error {
 	errs := new(multierror.Error)
 	// Start worker jobs, these are Blocking I/O and each working should create a new goroutine.
 	//
@@ -53,6 +83,8 @@ func (n *Supervisor) ScheduleJobs(ctx context.Context) error {
 			w.SetID(i)
 			if err := w.StartJob(workerCtx); err != nil {
 				errs = multierror.Append(errs, err)
+				_ = w.Close() // release any resources the worker acquired before failing
+				n.workers.Put(w)
 			} else {
 				n.runningWorkers.Add(w)
 			}
@@ -78,6 +110,13 @@ func (n *Supervisor) ensureValidParams() error {
 
 // Close ends the current Supervisor consuming session
 func (n *Supervisor) Close() error {
+	n.closed.setTrue()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.closeLocked()
+}
+
+func (n *Supervisor) closeLocked() error {
 	errs := new(multierror.Error)
 	runningLength := n.runningWorkers.Length() // allocate in a different memory address to avoid queue length mutation
 	for i := 0; i < runningLength; i++ {
@@ -90,6 +129,216 @@ func (n *Supervisor) Close() error {
 	return errs.ErrorOrNil()
 }
 
+// Pause stops every running worker from fetching new data while keeping its session (e.g. Kafka consumer group
+// membership) alive
+func (n *Supervisor) Pause() error {
+	if err := n.toggleWorkers(PausableWorker.Pause); err != nil {
+		return err
+	}
+	n.paused.setTrue()
+	return nil
+}
+
+// Resume starts fetching data again on every running worker previously paused
+func (n *Supervisor) Resume() error {
+	if err := n.toggleWorkers(PausableWorker.Resume); err != nil {
+		return err
+	}
+	n.paused.setFalse()
+	return nil
+}
+
+// IsPaused indicates if the Supervisor workers are paused
+func (n *Supervisor) IsPaused() bool {
+	return n.paused.isSet()
+}
+
+// IsClosed indicates if the Supervisor consuming session ended, workers restarted by the Supervisor are closed
+// while it is still open
+func (n *Supervisor) IsClosed() bool {
+	return n.closed.isSet()
+}
+
+// Status returns a snapshot of the current Supervisor configuration and the state of its running workers
+func (n *Supervisor) Status() SupervisorStatus {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	st := SupervisorStatus{
+		Topics:         n.Consumer.GetTopics(),
+		Group:          n.GetGroup(),
+		Cluster:        n.GetCluster(),
+		PoolSize:       n.setDefaultPoolSize(),
+		MaxRetries:     n.setDefaultMaxRetries(),
+		RetryBackoff:   n.setDefaultRetryBackoff(),
+		HandlerTimeout: n.setDefaultHandlerTimeout(),
+		Paused:         n.IsPaused(),
+		Restarts:       len(n.restarts),
+		Panics:         atomic.LoadInt64(&n.panics),
+		Timeouts:       atomic.LoadInt64(&n.timeouts),
+		Degraded:       n.startErr != nil,
+		Workers:        make([]WorkerStatus, 0, n.runningWorkers.Length()),
+	}
+	for i := 0; i < n.runningWorkers.Length(); i++ {
+		ws := WorkerStatus{State: WorkerRunning}
+		if r, ok := n.runningWorkers.Get(i).(StatusReporter); ok {
+			ws = r.Status()
+		}
+		ws.Id = i
+		st.Workers = append(st.Workers, ws)
+	}
+	if n.startErr != nil {
+		st.Error = n.startErr.Error()
+	}
+	return st
+}
+
+func (n *Supervisor) toggleWorkers(fn func(PausableWorker) error) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	errs := new(multierror.Error)
+	for i := 0; i < n.runningWorkers.Length(); i++ {
+		w, ok := n.runningWorkers.Get(i).(PausableWorker)
+		if !ok {
+			return fmt.Errorf("topic(s) %s: %w", n.Consumer.TopicString(), ErrPauseNotSupported)
+		}
+		if err := fn(w); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// NotifyExit must be called by a worker when its consuming job exits without the worker being closed (e.g. an
+// unrecoverable provider error). The Supervisor restarts workers based on its RestartPolicy
+func (n *Supervisor) NotifyExit(w Worker, err error) {
+	if n.closed.isSet() {
+		return
+	}
+	go n.restart(w, err)
+}
+
+// restart closes and starts again the workers following the RestartPolicy, outside the Supervisor lock as
+// provider operations might block (e.g. a slow broker)
+func (n *Supervisor) restart(w Worker, cause error) {
+	ctx, targets, errs := n.takeRestartTargets(w, cause)
+	for _, err := range errs {
+		n.reportError(err)
+	}
+	for _, t := range targets {
+		if err := t.Close(); err != nil {
+			n.reportError(err)
+		}
+		if err := n.putRestarted(t, t.StartJob(ctx)); err != nil {
+			n.reportError(err)
+		}
+	}
+}
+
+// takeRestartTargets removes the workers to restart from the running workers, so they are not paused nor closed
+// concurrently.
+//
+// Returns the errors to report once the Supervisor lock is released, as the ErrorHandler might query the Broker
+// status (which locks every Supervisor)
+func (n *Supervisor) takeRestartTargets(w Worker, cause error) (context.Context, []Worker, []error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed.isSet() || !n.isRunningLocked(w) {
+		return nil, nil, nil
+	}
+	errs := []error{fmt.Errorf("topic(s) %s: %w", n.Consumer.TopicString(), cause)}
+	policy := n.setDefaultRestartPolicy()
+	if policy.Strategy == NeverRestart {
+		return nil, nil, errs
+	} else if !n.allowRestartLocked(policy) {
+		if policy.Escalate {
+			go n.Broker.Shutdown(context.Background())
+		}
+		return nil, nil, append(errs, fmt.Errorf("topic(s) %s: %w", n.Consumer.TopicString(),
+			ErrMaxRestartsExceeded))
+	}
+
+	targets := []Worker{w}
+	if policy.Strategy == OneForAll {
+		targets = make([]Worker, 0, n.runningWorkers.Length())
+		for i := 0; i < n.runningWorkers.Length(); i++ {
+			targets = append(targets, n.runningWorkers.Get(i).(Worker))
+		}
+	}
+	for _, t := range targets {
+		n.removeRunningLocked(t)
+	}
+	return n.ctx, targets, errs
+}
+
+// putRestarted adds back a restarted worker into the running workers, applying the Supervisor state changed
+// while it was restarting. Returns the error to report once the Supervisor lock is released
+func (n *Supervisor) putRestarted(w Worker, errStart error) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed.isSet() {
+		err := w.Close()
+		n.workers.Put(w)
+		return err
+	}
+	n.runningWorkers.Add(w)
+	if errStart != nil {
+		n.NotifyExit(w, errStart) // counts as another crash, bounded by the policy
+		return nil
+	}
+	if p, ok := w.(PausableWorker); ok && n.paused.isSet() {
+		return p.Pause()
+	}
+	return nil
+}
+
+func (n *Supervisor) removeRunningLocked(w Worker) {
+	runningLength := n.runningWorkers.Length()
+	for i := 0; i < runningLength; i++ {
+		if r := n.runningWorkers.Remove().(Worker); r != w {
+			n.runningWorkers.Add(r)
+		}
+	}
+}
+
+func (n *Supervisor) isRunningLocked(w Worker) bool {
+	for i := 0; i < n.runningWorkers.Length(); i++ {
+		if n.runningWorkers.Get(i) == w {
+			return true
+		}
+	}
+	return false
+}
+
+// allowRestartLocked records a restart if the policy allows it within its window
+func (n *Supervisor) allowRestartLocked(p RestartPolicy) bool {
+	now := time.Now()
+	recent := n.restarts[:0]
+	for _, t := range n.restarts {
+		if now.Sub(t) < p.Window {
+			recent = append(recent, t)
+		}
+	}
+	n.restarts = recent
+	if len(n.restarts) >= p.MaxRestarts {
+		return false
+	}
+	n.restarts = append(n.restarts, now)
+	return true
+}
+
+func (n *Supervisor) reportError(err error) {
+	if n.Broker.ErrorHandler != nil {
+		n.Broker.ErrorHandler(n.ctx, err)
+	}
+}
+
+func (n *Supervisor) setDefaultRestartPolicy() RestartPolicy {
+	if p := n.Consumer.restartPolicy; p != nil {
+		return p.withDefaults()
+	}
+	return n.Broker.setDefaultRestartPolicy() // use global
+}
+
 func (n *Supervisor) setDefaultPoolSize() int {
 	if n.Consumer.poolSize > 0 {
 		return n.Consumer.poolSize
@@ -111,6 +360,13 @@ func (n *Supervisor) setDefaultRetryBackoff() time.Duration {
 	return n.Broker.setDefaultRetryBackoff() // use global
 }
 
+func (n *Supervisor) setDefaultHandlerTimeout() time.Duration {
+	if n.Consumer.handlerTimeout > 0 {
+		return n.Consumer.handlerTimeout
+	}
+	return n.Broker.HandlerTimeout // use global
+}
+
 func (n *Supervisor) setDefaultProviderConfig() interface{} {
 	if cfg := n.Consumer.providerConfig; cfg != nil {
 		return cfg
@@ -167,6 +423,13 @@ func (n *Supervisor) GetEventWriter() EventWriter {
 	return n.setDefaultEventWriter()
 }
 
+// NewEventWriter allocates an EventWriter publishing through the given Publisher using the Supervisor configuration.
+//
+// Useful for providers scoping the writes of a handler (e.g. an Apache Kafka transactional producer)
+func (n *Supervisor) NewEventWriter(p Publisher) EventWriter {
+	return newEventWriter(n, p)
+}
+
 // GetCluster retrieves the default cluster slice
 func (n *Supervisor) GetCluster() []string {
 	return n.setDefaultCluster()
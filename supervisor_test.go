@@ -0,0 +1,176 @@
+package quark
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type crashingWorker struct {
+	parent *Supervisor
+	starts *int32
+	closes *int32
+	// failAfter makes StartJob fail once the given number of jobs were started, ignored if zero
+	failAfter int32
+	// unblock makes StartJob block until it is closed, ignored if nil
+	unblock chan struct{}
+}
+
+func (w *crashingWorker) SetID(_ int) {}
+
+func (w *crashingWorker) Parent() *Supervisor {
+	return w.parent
+}
+
+func (w *crashingWorker) StartJob(_ context.Context) error {
+	if w.unblock != nil {
+		<-w.unblock
+	}
+	if w.failAfter > 0 && atomic.LoadInt32(w.starts) >= w.failAfter {
+		return errors.New("broker not available")
+	}
+	atomic.AddInt32(w.starts, 1)
+	return nil
+}
+
+func (w *crashingWorker) Close() error {
+	atomic.AddInt32(w.closes, 1)
+	return nil
+}
+
+func newCrashingSupervisor(p RestartPolicy, poolSize int) (*Supervisor, *int32, *int32) {
+	starts, closes := new(int32), new(int32)
+	b := NewBroker(WithCluster("localhost"), WithPoolSize(poolSize), WithRestartPolicy(p))
+	b.WorkerFactory = func(parent *Supervisor) Worker {
+		return &crashingWorker{parent: parent, starts: starts, closes: closes}
+	}
+	c := NewConsumer("chat.0").HandleFunc(func(w EventWriter, e *Event) bool {
+		return true
+	})
+	return newSupervisor(b, c), starts, closes
+}
+
+var supervisorRestartTestingSuite = []struct {
+	policy         RestartPolicy
+	crashes        int
+	expectedStarts int32
+}{
+	{RestartPolicy{Strategy: OneForOne}, 1, 3},
+	{RestartPolicy{Strategy: OneForAll}, 1, 4},
+	{RestartPolicy{Strategy: NeverRestart}, 1, 2},
+	{RestartPolicy{Strategy: OneForOne, MaxRestarts: 2}, 3, 4},
+}
+
+func TestSupervisor_NotifyExit(t *testing.T) {
+	for _, tt := range supervisorRestartTestingSuite {
+		t.Run("Supervisor restart crashed workers", func(t *testing.T) {
+			n, starts, _ := newCrashingSupervisor(tt.policy, 2)
+			assert.Nil(t, n.ScheduleJobs(context.Background()))
+			w := n.runningWorkers.Get(0).(Worker)
+			for i := 0; i < tt.crashes; i++ {
+				n.restart(w, errors.New("connection reset"))
+			}
+			assert.Equal(t, tt.expectedStarts, atomic.LoadInt32(starts))
+		})
+	}
+
+	t.Run("Supervisor escalate to broker shutdown", func(t *testing.T) {
+		n, _, _ := newCrashingSupervisor(RestartPolicy{MaxRestarts: 1, Escalate: true}, 1)
+		errs := make([]error, 0)
+		mu := sync.Mutex{}
+		n.Broker.ErrorHandler = func(_ context.Context, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		}
+		assert.Nil(t, n.ScheduleJobs(context.Background()))
+		w := n.runningWorkers.Get(0).(Worker)
+		n.restart(w, ErrWorkerExited)
+		n.restart(w, ErrWorkerExited)
+		assert.Eventually(t, n.Broker.shuttingDown, time.Second, time.Millisecond*10)
+		mu.Lock()
+		defer mu.Unlock()
+		assert.True(t, errors.Is(errs[len(errs)-1], ErrMaxRestartsExceeded))
+	})
+
+	t.Run("Supervisor report errors without holding its lock", func(t *testing.T) {
+		n, starts, _ := newCrashingSupervisor(RestartPolicy{MaxRestarts: 1}, 1)
+		reported := int32(0)
+		n.Broker.ErrorHandler = func(_ context.Context, err error) {
+			_ = n.Status() // handlers querying the Supervisor must not deadlock
+			atomic.AddInt32(&reported, 1)
+		}
+		assert.Nil(t, n.ScheduleJobs(context.Background()))
+		w := n.runningWorkers.Get(0).(Worker)
+		n.restart(w, ErrWorkerExited)
+		n.restart(w, ErrWorkerExited)
+		assert.Equal(t, int32(2), atomic.LoadInt32(starts))
+		assert.Equal(t, int32(3), atomic.LoadInt32(&reported)) // two crashes and the exceeded restarts
+	})
+
+	t.Run("Supervisor ignore exits after close", func(t *testing.T) {
+		n, starts, _ := newCrashingSupervisor(RestartPolicy{}, 1)
+		assert.Nil(t, n.ScheduleJobs(context.Background()))
+		w := n.runningWorkers.Get(0).(Worker)
+		assert.Nil(t, n.Close())
+		n.restart(w, ErrWorkerExited)
+		assert.Equal(t, int32(1), atomic.LoadInt32(starts))
+	})
+
+	t.Run("Supervisor restart workers without holding its lock", func(t *testing.T) {
+		n, starts, closes := newCrashingSupervisor(RestartPolicy{}, 1)
+		assert.Nil(t, n.ScheduleJobs(context.Background()))
+		w := n.runningWorkers.Get(0).(*crashingWorker)
+		w.unblock = make(chan struct{})
+		restarted := make(chan struct{})
+		go func() {
+			defer close(restarted)
+			n.restart(w, ErrWorkerExited)
+		}()
+		assert.Eventually(t, func() bool {
+			return atomic.LoadInt32(closes) == 1 // restart is blocked starting the worker
+		}, time.Second, time.Millisecond)
+		assert.Empty(t, n.Status().Workers)
+		assert.Nil(t, n.Close())
+
+		close(w.unblock)
+		<-restarted
+		// the worker restarted after the Supervisor was closed is closed again
+		assert.Equal(t, int32(2), atomic.LoadInt32(starts))
+		assert.Equal(t, int32(2), atomic.LoadInt32(closes))
+		assert.Equal(t, 0, n.runningWorkers.Length())
+	})
+}
+
+var supervisorStartupTestingSuite = []struct {
+	mode            StartupMode
+	expectedWorkers int
+	expectedClosed  int32
+}{
+	{StartupAtomic, 0, 3},
+	{StartupBestEffort, 2, 1},
+}
+
+func TestSupervisor_ScheduleJobs(t *testing.T) {
+	for _, tt := range supervisorStartupTestingSuite {
+		t.Run("Supervisor startup with failing workers", func(t *testing.T) {
+			starts, closes := new(int32), new(int32)
+			b := NewBroker(WithCluster("localhost"), WithPoolSize(3), WithStartupMode(tt.mode))
+			b.WorkerFactory = func(parent *Supervisor) Worker {
+				return &crashingWorker{parent: parent, starts: starts, closes: closes, failAfter: 2}
+			}
+			n := newSupervisor(b, NewConsumer("chat.0").HandleFunc(func(w EventWriter, e *Event) bool {
+				return true
+			}))
+			assert.NotNil(t, n.ScheduleJobs(context.Background()))
+			assert.Equal(t, tt.expectedWorkers, n.runningWorkers.Length())
+			assert.Equal(t, tt.expectedClosed, atomic.LoadInt32(closes))
+			assert.True(t, n.Status().Degraded)
+		})
+	}
+}
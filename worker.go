@@ -23,3 +23,12 @@ type Worker interface {
 // WorkerFactory is a crucial Broker and/or Consumer component which generates the concrete workers
 // Quark will use to consume data
 type WorkerFactory func(parent *Supervisor) Worker
+
+// PausableWorker is a Worker able to stop fetching data temporarily without leaving its consumer group (if any)
+type PausableWorker interface {
+	Worker
+	// Pause stops fetching new data, in-flight messages are still handled
+	Pause() error
+	// Resume starts fetching data again from where it stopped
+	Resume() error
+}